@@ -14,6 +14,7 @@ import (
 type Environment struct {
 	pCtx            parser.Context
 	maxMapRecursion int
+	resources       query.ExecResources
 }
 
 // GlobalEnvironment returns the global default environment. Modifying this
@@ -59,6 +60,9 @@ func (e *Environment) NewField(expr string) (*field.Expression, error) {
 	if err != nil {
 		return nil, err
 	}
+	if e.resources != nil {
+		f.SetResources(e.resources)
+	}
 	return f, nil
 }
 
@@ -76,6 +80,9 @@ func (e *Environment) NewMapping(blobl string) (*mapping.Executor, error) {
 	if e.maxMapRecursion > 0 {
 		exec.SetMaxMapRecursion(e.maxMapRecursion)
 	}
+	if e.resources != nil {
+		exec.SetResources(e.resources)
+	}
 	return exec, nil
 }
 
@@ -180,6 +187,14 @@ func (e *Environment) WithMaxMapRecursion(n int) *Environment {
 	return &env
 }
 
+// WithResources returns a copy of the environment where mappings parsed from it
+// are given access to the resources of a component during execution.
+func (e *Environment) WithResources(r query.ExecResources) *Environment {
+	env := *e
+	env.resources = r
+	return &env
+}
+
 // WalkFunctions executes a provided function argument for every function that
 // has been registered to the environment.
 func (e *Environment) WalkFunctions(fn func(name string, spec query.FunctionSpec)) {
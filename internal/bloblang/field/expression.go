@@ -5,6 +5,7 @@ package field
 import (
 	"bytes"
 
+	"github.com/redpanda-data/benthos/v4/internal/bloblang/query"
 	"github.com/redpanda-data/benthos/v4/internal/message"
 )
 
@@ -67,6 +68,16 @@ func (e *Expression) resolve(index int, msg Message, escaped bool) ([]byte, erro
 	return buf.Bytes(), nil
 }
 
+// SetResources configures the component resources made available to functions
+// and methods during the execution of the interpolations of this expression.
+func (e *Expression) SetResources(r query.ExecResources) {
+	for _, res := range e.resolvers {
+		if q, ok := res.(*QueryResolver); ok {
+			q.resources = r
+		}
+	}
+}
+
 // NumDynamicExpressions returns the number of dynamic interpolation functions
 // within the expression.
 func (e *Expression) NumDynamicExpressions() int {
@@ -37,13 +37,14 @@ func (s StaticResolver) ResolveBytes(index int, msg Message, escaped bool) ([]by
 // QueryResolver executes a query and returns a string representation of the
 // result.
 type QueryResolver struct {
-	fn query.Function
+	fn        query.Function
+	resources query.ExecResources
 }
 
 // NewQueryResolver creates a field query resolver that returns the result of a
 // query function.
 func NewQueryResolver(fn query.Function) *QueryResolver {
-	return &QueryResolver{fn}
+	return &QueryResolver{fn: fn}
 }
 
 // ResolveString returns a string.
@@ -55,6 +56,8 @@ func (q QueryResolver) ResolveString(index int, msg Message, escaped bool) (stri
 		Index:    index,
 		MsgBatch: msg,
 		NewMeta:  msg.Get(index),
+
+		Resources: q.resources,
 	}.WithValueFunc(func() *any {
 		if jObj, err := msg.Get(index).AsStructured(); err == nil {
 			return &jObj
@@ -72,6 +75,8 @@ func (q QueryResolver) ResolveBytes(index int, msg Message, escaped bool) ([]byt
 		Index:    index,
 		MsgBatch: msg,
 		NewMeta:  msg.Get(index),
+
+		Resources: q.resources,
 	}.WithValueFunc(func() *any {
 		if jObj, err := msg.Get(index).AsStructured(); err == nil {
 			return &jObj
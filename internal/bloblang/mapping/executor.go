@@ -46,6 +46,7 @@ type Executor struct {
 	input      []rune
 	maps       map[string]query.Function
 	statements []Statement
+	resources  query.ExecResources
 
 	maxMapStacks int
 }
@@ -73,6 +74,12 @@ func (e *Executor) SetMaxMapRecursion(m int) {
 	e.maxMapStacks = m
 }
 
+// SetResources configures the component resources made available to functions
+// and methods during the execution of this mapping.
+func (e *Executor) SetResources(r query.ExecResources) {
+	e.resources = r
+}
+
 // Annotation returns a string annotation that describes the mapping executor.
 func (e *Executor) Annotation() string {
 	return e.annotation
@@ -165,6 +172,8 @@ func (e *Executor) mapPart(appendTo *message.Part, index int, reference Message)
 			MsgBatch: reference,
 			NewMeta:  newPart,
 			NewValue: &newValue,
+
+			Resources: e.resources,
 		}.WithValueFunc(lazyValue),
 			AssignmentContext{
 				Vars:  vars,
@@ -243,6 +252,9 @@ func (e *Executor) Exec(ctx query.FunctionContext) (any, error) {
 
 	var newObj any = value.Nothing(nil)
 	ctx.NewValue = &newObj
+	if ctx.Resources == nil {
+		ctx.Resources = e.resources
+	}
 
 	for _, stmt := range e.statements {
 		if err := stmt.Execute(ctx, AssignmentContext{
@@ -259,6 +271,9 @@ func (e *Executor) Exec(ctx query.FunctionContext) (any, error) {
 
 // ExecOnto a provided assignment context.
 func (e *Executor) ExecOnto(ctx query.FunctionContext, onto AssignmentContext) error {
+	if ctx.Resources == nil {
+		ctx.Resources = e.resources
+	}
 	for _, stmt := range e.statements {
 		if err := stmt.Execute(ctx, onto); err != nil {
 			return formatExecErr(err, e.input, stmt.Input())
@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -589,6 +590,35 @@ from "%v"`, directMapFile),
 	}
 }
 
+func TestMappingTimeout(t *testing.T) {
+	exec, perr := ParseMapping(GlobalContext(), `
+root.a = {"b":1}
+root.x = this.timeout("1ms", v -> range(0, 300000).map_each(i -> root.a.b).length()).catch(-1)
+root.a.b = 2
+`)
+	require.Nil(t, perr)
+
+	// Executed repeatedly in order to expose any data race between the query
+	// and the remainder of the mapping when run with the race detector.
+	for i := 0; i < 5; i++ {
+		resPart, err := exec.MapPart(0, message.QuickBatch([][]byte{[]byte(`{}`)}))
+		require.NoError(t, err)
+
+		v, err := resPart.AsStructured()
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"b": int64(2)}, v.(map[string]any)["a"])
+	}
+
+	exec, perr = ParseMapping(GlobalContext(), `root = this.timeout("20ms", v -> v.retry(10, x -> throw("nope"), "1h")).catch("timed out")`)
+	require.Nil(t, perr)
+
+	start := time.Now()
+	resPart, err := exec.MapPart(0, message.QuickBatch([][]byte{[]byte(`"foo"`)}))
+	require.NoError(t, err)
+	assert.Equal(t, "timed out", string(resPart.AsBytes()))
+	assert.Less(t, time.Since(start), time.Second*5)
+}
+
 func BenchmarkMappingParser(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		_, err := ParseMapping(GlobalContext(), `
@@ -48,6 +48,8 @@ func Register() error {
 					Vars:     map[string]any{},
 					Maps:     exec.Maps(),
 					MsgBatch: message.QuickBatch(nil),
+
+					Resources: ctx.Resources,
 				}.WithValue(v))
 			}, target.QueryTargets), nil
 		},
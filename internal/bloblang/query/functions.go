@@ -3,6 +3,7 @@
 package query
 
 import (
+	"container/list"
 	"context"
 	"errors"
 	"fmt"
@@ -313,6 +314,73 @@ func countFunction(args *ParsedParams) (Function, error) {
 
 //------------------------------------------------------------------------------
 
+var _ = registerFunction(
+	NewFunctionSpec(
+		FunctionCategoryEnvironment, "rate_limit_check",
+		"Accesses a xref:components:rate_limits/about.adoc[`rate_limit` resource] and returns the number of nanoseconds to wait before the resource should be accessed again, where `0` indicates that access was granted. Each call counts as an access of the rate limit, and therefore consumes from its budget when granted. This makes it possible to drop or route messages that exceed a quota without blocking the pipeline. When a key is provided the access consumes from a budget that is specific to that key, which makes it possible to apply a quota per tenant, user, etc, where each key is given the limits of the resource. Keys are only supported by rate limits that track budgets per key, such as the `local` rate limit, and an error is returned when a key is provided to a rate limit that does not support them.\n\nThis function is only available to mappings and interpolations executed by components, and returns an error when the resource does not exist or when executed outside of a component.",
+		NewExampleSpec("",
+			`root = if rate_limit_check("searches") > 0 { deleted() }`,
+		),
+		NewExampleSpec("",
+			`meta throttled = rate_limit_check("per_tenant", this.tenant_id) > 0`,
+		),
+	).
+		Param(ParamString("resource", "The label of a rate limit resource.")).
+		Param(ParamString("key", "An optional key to check the budget of within the rate limit.").Optional()).
+		MarkImpure(),
+	rateLimitCheckFunction,
+)
+
+func rateLimitCheckFunction(args *ParsedParams) (Function, error) {
+	name, err := args.FieldString("resource")
+	if err != nil {
+		return nil, err
+	}
+	key, err := args.FieldOptionalString("key")
+	if err != nil {
+		return nil, err
+	}
+	return ClosureFunction("function rate_limit_check", func(ctx FunctionContext) (any, error) {
+		if ctx.Resources == nil {
+			return nil, errors.New("rate limit resources are not available in this context")
+		}
+		var wait time.Duration
+		var err error
+		if key != nil {
+			wait, err = ctx.Resources.AccessRateLimitKey(ctx.MsgContext(), name, *key)
+		} else {
+			wait, err = ctx.Resources.AccessRateLimit(ctx.MsgContext(), name)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return int64(wait), nil
+	}, nil), nil
+}
+
+//------------------------------------------------------------------------------
+
+var _ = registerFunction(
+	NewFunctionSpec(
+		FunctionCategoryEnvironment, "stream_id",
+		"Returns the identifier of the stream executing the mapping when running in xref:guides:streams_mode/about.adoc[streams mode], or an empty string otherwise. This is useful for tagging data, logs and metrics emitted from mappings that are shared between multiple streams.",
+		NewExampleSpec("",
+			`root = this
+root.source_stream = stream_id()`,
+		),
+	),
+	func(*ParsedParams) (Function, error) {
+		return ClosureFunction("function stream_id", func(ctx FunctionContext) (any, error) {
+			if ctx.Resources == nil {
+				return "", nil
+			}
+			return ctx.Resources.StreamID(), nil
+		}, nil), nil
+	},
+)
+
+//------------------------------------------------------------------------------
+
 var _ = registerFunction(
 	NewFunctionSpec(
 		FunctionCategoryGeneral, "deleted",
@@ -806,6 +874,212 @@ func randomIntFunction(args *ParsedParams) (Function, error) {
 
 //------------------------------------------------------------------------------
 
+var _ = registerFunction(
+	NewFunctionSpec(
+		FunctionCategoryGeneral, "weighted_choice", `
+Selects a value from an array of choices at random, where the probability of each choice being selected is proportional to its weight. Each choice must be an object containing a field `+"`value`"+`, which is the value yielded when the choice is selected, and a field `+"`weight`"+`, which must be a non-negative number. At least one choice must have a weight greater than zero.
+
+An optional integer argument can be provided in order to seed the random number generator, following the same rules as the `+"`random_int`"+` function.`,
+		NewExampleSpec("Route roughly 5% of messages to a canary output by setting a metadata field.",
+			`meta destination = weighted_choice([
+  {"value": "canary", "weight": 5},
+  {"value": "stable", "weight": 95},
+])`,
+		),
+		NewExampleSpec("Choices with a weight of zero are never selected.",
+			`root.colour = weighted_choice([{"value":"red","weight":0},{"value":"blue","weight":1}])`,
+			`{}`,
+			`{"colour":"blue"}`,
+		),
+	).
+		Param(ParamQuery(
+			"choices",
+			"An array of objects each containing a `value` and a numerical `weight`.",
+			true,
+		)).
+		Param(ParamQuery(
+			"seed",
+			"A seed to use, if a query is provided it will only be resolved once during the lifetime of the mapping.",
+			true,
+		).Default(NewLiteralFunction("", 0))),
+	weightedChoiceFunction,
+)
+
+func weightedChoices(v any) (values []any, weights []float64, total float64, err error) {
+	choices, ok := v.([]any)
+	if !ok {
+		return nil, nil, 0, value.NewTypeError(v, value.TArray)
+	}
+	values = make([]any, len(choices))
+	weights = make([]float64, len(choices))
+	for i, c := range choices {
+		obj, ok := c.(map[string]any)
+		if !ok {
+			return nil, nil, 0, fmt.Errorf("choice %v: %w", i, value.NewTypeError(c, value.TObject))
+		}
+		if values[i], ok = obj["value"]; !ok {
+			return nil, nil, 0, fmt.Errorf("choice %v: missing field value", i)
+		}
+		if weights[i], err = value.IGetNumber(obj["weight"]); err != nil {
+			return nil, nil, 0, fmt.Errorf("choice %v: weight: %w", i, err)
+		}
+		if weights[i] < 0 {
+			return nil, nil, 0, fmt.Errorf("choice %v: weight must not be negative, got %v", i, weights[i])
+		}
+		total += weights[i]
+	}
+	if total <= 0 {
+		return nil, nil, 0, errors.New("at least one choice must have a weight greater than zero")
+	}
+	return
+}
+
+func weightedChoiceFunction(args *ParsedParams) (Function, error) {
+	choicesFn, err := args.FieldQuery("choices")
+	if err != nil {
+		return nil, err
+	}
+	seedFn, err := args.FieldQuery("seed")
+	if err != nil {
+		return nil, err
+	}
+
+	var randMut sync.Mutex
+	var r *rand.Rand
+
+	return ClosureFunction("function weighted_choice", func(ctx FunctionContext) (any, error) {
+		choicesV, err := choicesFn.Exec(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve choices: %w", err)
+		}
+		values, weights, total, err := weightedChoices(choicesV)
+		if err != nil {
+			return nil, err
+		}
+
+		randMut.Lock()
+		defer randMut.Unlock()
+
+		if r == nil {
+			seedI, err := seedFn.Exec(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to seed random number generator: %v", err)
+			}
+
+			seed, err := value.IToInt(seedI)
+			if err != nil {
+				return nil, fmt.Errorf("failed to seed random number generator: %v", err)
+			}
+
+			r = rand.New(rand.NewSource(seed))
+		}
+
+		target := r.Float64() * total
+		lastIndex := 0
+		for i, w := range weights {
+			if w == 0 {
+				continue
+			}
+			if target < w {
+				return values[i], nil
+			}
+			target -= w
+			lastIndex = i
+		}
+
+		// Floating point rounding can leave a sliver of the target remaining,
+		// in which case the last eligible choice is selected.
+		return values[lastIndex], nil
+	}, aggregateTargetPaths(choicesFn)), nil
+}
+
+//------------------------------------------------------------------------------
+
+var _ = registerFunction(
+	NewFunctionSpec(
+		FunctionCategoryGeneral, "memoize",
+		"Executes a query and caches its result by a key, such that subsequent calls with the same key return the cached result rather than executing the query again. This is useful for avoiding repeated expensive computations (such as regular expression heavy transformations) on recurring inputs. The key is converted to a string, and results are cached for the lifetime of the mapping, with the least recently used entry evicted once the number of cached entries exceeds `max_entries`. Errors are not cached. The query should be pure, as it is not executed for cached keys.",
+		NewExampleSpec("",
+			`root.slug = memoize(this.title, this.title.lowercase().re_replace_all("[^a-z0-9]+", "-"))`,
+			`{"title":"Hello World"}`,
+			`{"slug":"hello-world"}`,
+			`{"title":"Hello World"}`,
+			`{"slug":"hello-world"}`,
+		),
+	).
+		Param(ParamQuery("key", "A query that resolves the key that results are cached by.", true)).
+		Param(ParamQuery("value", "A query to execute when a result for the key is not cached.", false)).
+		Param(ParamInt64("max_entries", "The maximum number of results to cache.").Default(1000).DisableDynamic()),
+	memoizeFunction,
+)
+
+type memoizeEntry struct {
+	key   string
+	value any
+}
+
+func memoizeFunction(args *ParsedParams) (Function, error) {
+	keyFn, err := args.FieldQuery("key")
+	if err != nil {
+		return nil, err
+	}
+	valueFn, err := args.FieldQuery("value")
+	if err != nil {
+		return nil, err
+	}
+	maxEntries, err := args.FieldInt64("max_entries")
+	if err != nil {
+		return nil, err
+	}
+	if maxEntries < 1 {
+		return nil, fmt.Errorf("max_entries must be greater than zero, got %v", maxEntries)
+	}
+
+	var cacheMut sync.Mutex
+	entries := map[string]*list.Element{}
+	lru := list.New()
+
+	return ClosureFunction("function memoize", func(ctx FunctionContext) (any, error) {
+		keyV, err := keyFn.Exec(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve key: %w", err)
+		}
+		key := value.IToString(keyV)
+
+		cacheMut.Lock()
+		if e, exists := entries[key]; exists {
+			lru.MoveToFront(e)
+			v := e.Value.(*memoizeEntry).value
+			cacheMut.Unlock()
+			return value.IClone(v), nil
+		}
+		cacheMut.Unlock()
+
+		v, err := valueFn.Exec(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		cacheMut.Lock()
+		if e, exists := entries[key]; exists {
+			lru.MoveToFront(e)
+			e.Value.(*memoizeEntry).value = v
+		} else {
+			entries[key] = lru.PushFront(&memoizeEntry{key: key, value: v})
+			if int64(lru.Len()) > maxEntries {
+				oldest := lru.Back()
+				lru.Remove(oldest)
+				delete(entries, oldest.Value.(*memoizeEntry).key)
+			}
+		}
+		cacheMut.Unlock()
+
+		return value.IClone(v), nil
+	}, aggregateTargetPaths(keyFn, valueFn)), nil
+}
+
+//------------------------------------------------------------------------------
+
 var _ = registerFunction(
 	NewFunctionSpec(
 		FunctionCategoryEnvironment, "now",
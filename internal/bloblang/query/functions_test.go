@@ -3,16 +3,19 @@
 package query
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/redpanda-data/benthos/v4/internal/message"
+	"github.com/redpanda-data/benthos/v4/internal/value"
 )
 
 func TestFunctions(t *testing.T) {
@@ -495,6 +498,241 @@ func TestRandomIntWithinRange(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestWeightedChoice(t *testing.T) {
+	choices := []any{
+		map[string]any{"value": "a", "weight": int64(1)},
+		map[string]any{"value": "b", "weight": 3.0},
+		map[string]any{"value": "c", "weight": int64(0)},
+	}
+
+	e, err := InitFunctionHelper("weighted_choice", choices, 10)
+	require.NoError(t, err)
+
+	tallies := map[any]int{}
+	for i := 0; i < 1000; i++ {
+		res, err := e.Exec(FunctionContext{})
+		require.NoError(t, err)
+		tallies[res]++
+	}
+
+	assert.NotContains(t, tallies, "c")
+	assert.Greater(t, tallies["a"], 150)
+	assert.Greater(t, tallies["b"], 650)
+
+	// The same seed should yield the same sequence
+	e2, err := InitFunctionHelper("weighted_choice", choices, 10)
+	require.NoError(t, err)
+
+	secondTallies := map[any]int{}
+	for i := 0; i < 1000; i++ {
+		res, err := e2.Exec(FunctionContext{})
+		require.NoError(t, err)
+		secondTallies[res]++
+	}
+	assert.Equal(t, tallies, secondTallies)
+}
+
+func TestWeightedChoiceErrors(t *testing.T) {
+	tests := map[string]struct {
+		choices any
+		err     string
+	}{
+		"not an array": {
+			choices: "nope",
+			err:     "expected array value",
+		},
+		"negative weight": {
+			choices: []any{map[string]any{"value": "a", "weight": int64(-1)}},
+			err:     "choice 0: weight must not be negative, got -1",
+		},
+		"all zero weights": {
+			choices: []any{
+				map[string]any{"value": "a", "weight": int64(0)},
+				map[string]any{"value": "b", "weight": int64(0)},
+			},
+			err: "at least one choice must have a weight greater than zero",
+		},
+		"missing value": {
+			choices: []any{map[string]any{"weight": int64(1)}},
+			err:     "choice 0: missing field value",
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			e, err := InitFunctionHelper("weighted_choice", test.choices)
+			require.NoError(t, err)
+
+			_, err = e.Exec(FunctionContext{})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.err)
+		})
+	}
+}
+
+func TestMemoize(t *testing.T) {
+	var calls int
+	valueFn := ClosureFunction("expensive", func(ctx FunctionContext) (any, error) {
+		calls++
+		v := ctx.Value()
+		if v == nil || *v == nil {
+			return nil, errors.New("nope")
+		}
+		return map[string]any{"result": value.IToString(*v) + " done"}, nil
+	}, nil)
+
+	e, err := InitFunctionHelper("memoize", NewFieldFunction(""), valueFn, 2)
+	require.NoError(t, err)
+
+	exec := func(v any) (any, error) {
+		return e.Exec(FunctionContext{}.WithValue(v))
+	}
+
+	res, err := exec("a")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"result": "a done"}, res)
+
+	// Mutations of a result must not leak into the cache
+	res.(map[string]any)["result"] = "mutated"
+
+	res, err = exec("a")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"result": "a done"}, res)
+	assert.Equal(t, 1, calls)
+
+	_, err = exec("b")
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+
+	// Reading a moves it to the front, so that c evicts b
+	_, err = exec("a")
+	require.NoError(t, err)
+	_, err = exec("c")
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+
+	_, err = exec("a")
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+
+	_, err = exec("b")
+	require.NoError(t, err)
+	assert.Equal(t, 4, calls)
+
+	// Errors are not cached
+	_, err = exec(nil)
+	require.Error(t, err)
+	_, err = exec(nil)
+	require.Error(t, err)
+	assert.Equal(t, 6, calls)
+
+	_, err = InitFunctionHelper("memoize", NewFieldFunction(""), valueFn, 0)
+	require.Error(t, err)
+}
+
+type fnTestResources struct {
+	rateLimits map[string]time.Duration
+	streamID   string
+	logs       *[]string
+	metrics    *[]string
+}
+
+func (f fnTestResources) AccessRateLimit(ctx context.Context, name string) (time.Duration, error) {
+	d, exists := f.rateLimits[name]
+	if !exists {
+		return 0, fmt.Errorf("unable to locate resource: %v", name)
+	}
+	return d, nil
+}
+
+func (f fnTestResources) AccessRateLimitKey(ctx context.Context, name, key string) (time.Duration, error) {
+	return f.AccessRateLimit(ctx, name+"/"+key)
+}
+
+func (f fnTestResources) StreamID() string {
+	return f.streamID
+}
+
+func (f fnTestResources) Log(level, message string, keyValues ...any) {
+	if f.logs != nil {
+		*f.logs = append(*f.logs, fmt.Sprintf("%v: %v %v", level, message, keyValues))
+	}
+}
+
+func (f fnTestResources) IncrCounter(name string, count float64, labels map[string]string) {
+	if f.metrics != nil {
+		*f.metrics = append(*f.metrics, fmt.Sprintf("counter %v %v %v", name, count, labels))
+	}
+}
+
+func (f fnTestResources) SetGauge(name string, value float64, labels map[string]string) {
+	if f.metrics != nil {
+		*f.metrics = append(*f.metrics, fmt.Sprintf("gauge %v %v %v", name, value, labels))
+	}
+}
+
+func TestRateLimitCheck(t *testing.T) {
+	res := fnTestResources{
+		rateLimits: map[string]time.Duration{
+			"open":   0,
+			"closed": time.Second,
+		},
+	}
+
+	e, err := InitFunctionHelper("rate_limit_check", "open")
+	require.NoError(t, err)
+
+	v, err := e.Exec(FunctionContext{Resources: res})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), v)
+
+	_, err = e.Exec(FunctionContext{})
+	require.EqualError(t, err, "rate limit resources are not available in this context")
+
+	e, err = InitFunctionHelper("rate_limit_check", "closed")
+	require.NoError(t, err)
+
+	v, err = e.Exec(FunctionContext{Resources: res})
+	require.NoError(t, err)
+	assert.Equal(t, int64(time.Second), v)
+
+	e, err = InitFunctionHelper("rate_limit_check", "nope")
+	require.NoError(t, err)
+
+	_, err = e.Exec(FunctionContext{Resources: res})
+	require.EqualError(t, err, "unable to locate resource: nope")
+
+	res.rateLimits["keyed/foo"] = time.Minute
+
+	e, err = InitFunctionHelper("rate_limit_check", "keyed", "foo")
+	require.NoError(t, err)
+
+	v, err = e.Exec(FunctionContext{Resources: res})
+	require.NoError(t, err)
+	assert.Equal(t, int64(time.Minute), v)
+
+	e, err = InitFunctionHelper("rate_limit_check", "keyed", NewFieldFunction("tenant"))
+	require.NoError(t, err)
+
+	v, err = e.Exec(FunctionContext{Resources: res}.WithValue(map[string]any{"tenant": "foo"}))
+	require.NoError(t, err)
+	assert.Equal(t, int64(time.Minute), v)
+}
+
+func TestStreamIDFunction(t *testing.T) {
+	e, err := InitFunctionHelper("stream_id")
+	require.NoError(t, err)
+
+	v, err := e.Exec(FunctionContext{Resources: fnTestResources{streamID: "foo"}})
+	require.NoError(t, err)
+	assert.Equal(t, "foo", v)
+
+	v, err = e.Exec(FunctionContext{})
+	require.NoError(t, err)
+	assert.Equal(t, "", v)
+}
+
 func TestErrorFunctions(t *testing.T) {
 	tests := []struct {
 		name           string
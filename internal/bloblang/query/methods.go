@@ -3,9 +3,14 @@
 package query
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"math"
 	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/Jeffail/gabs/v2"
 
@@ -184,6 +189,281 @@ func catchMethod(fn Function, args *ParsedParams) (Function, error) {
 
 //------------------------------------------------------------------------------
 
+var _ = registerMethod(
+	NewMethodSpec(
+		"result",
+		"Executes a query on the target value and returns an object describing the outcome, with the fields `ok` (a boolean indicating whether the target and query succeeded), `value` (the result of the query, or `null` if it failed) and `error` (the error message if it failed, otherwise `null`). Errors are never propagated, which allows a single mapping to attempt many operations and report the status of each one. Unlike the `|` operator and `catch` method the error message is preserved alongside the outcome.",
+		NewExampleSpec("",
+			`root.id = this.id.result(id -> id.number())
+root.ts = this.ts.result(ts -> ts.ts_parse("2006-01-02"))`,
+			`{"id":"42","ts":"nope"}`,
+			`{"id":{"error":null,"ok":true,"value":42},"ts":{"error":"parsing time \"nope\" as \"2006-01-02\": cannot parse \"nope\" as \"2006\"","ok":false,"value":null}}`,
+		),
+		NewExampleSpec("Errors of the target value itself are also captured.",
+			`root = this.doc.parse_json().result(doc -> doc.id)`,
+			`{"doc":"{\"id\":\"foo\"}"}`,
+			`{"error":null,"ok":true,"value":"foo"}`,
+		),
+	).Param(ParamQuery("query", "A query to execute on the target.", false)),
+	resultMethod,
+)
+
+func resultMethod(target Function, args *ParsedParams) (Function, error) {
+	queryFn, err := args.FieldQuery("query")
+	if err != nil {
+		return nil, err
+	}
+	return ClosureFunction("method result", func(ctx FunctionContext) (any, error) {
+		res, err := target.Exec(ctx)
+		if err == nil {
+			res, err = queryFn.Exec(ctx.WithValue(res))
+		}
+		if err != nil {
+			return map[string]any{
+				"ok":    false,
+				"value": nil,
+				"error": err.Error(),
+			}, nil
+		}
+		return map[string]any{
+			"ok":    true,
+			"value": res,
+			"error": nil,
+		}, nil
+	}, func(ctx TargetsContext) (TargetsContext, []TargetPath) {
+		queryCtx, targets := target.QueryTargets(ctx)
+		queryCtx = queryCtx.WithValues(targets).WithValuesAsContext()
+
+		returnCtx, queryTargets := queryFn.QueryTargets(queryCtx)
+		return returnCtx, append(targets, queryTargets...)
+	}), nil
+}
+
+//------------------------------------------------------------------------------
+
+var _ = registerMethod(
+	NewMethodSpec(
+		"scope",
+		"Executes a query with the target value as its context and returns the result, which reduces the repetition of long paths when several fields of a deeply nested value are referenced. Within the query `this` refers to the target value, and the context can also be captured with a named parameter. This is equivalent to the bracketed map expression syntax `this.foo.(a + b)`. The name `with` is not used as it's already taken by a method that filters object fields.",
+		NewExampleSpec("",
+			`root.total = this.order.details.pricing.scope(this.price * this.quantity + this.shipping)`,
+			`{"order":{"details":{"pricing":{"price":10,"quantity":3,"shipping":5}}}}`,
+			`{"total":35}`,
+		),
+		NewExampleSpec("",
+			`root.name = this.user.profile.scope(p -> "%s %s".format(p.first, p.last))`,
+			`{"user":{"profile":{"first":"Ada","last":"Lovelace"}}}`,
+			`{"name":"Ada Lovelace"}`,
+		),
+	).Param(ParamQuery("query", "A query to execute with the target as its context.", false)),
+	mapMethod,
+)
+
+//------------------------------------------------------------------------------
+
+var _ = registerMethod(
+	NewMethodSpec(
+		"tap",
+		"Executes a query with the target value as its context for its side effects, such as logging or emitting metrics, and then returns the target value unchanged. The result of the query is discarded, but if the query fails then the error is returned, use `catch` within the query in order to ignore errors.",
+		NewExampleSpec("",
+			`root.items = this.items.tap(i -> i.length().log_value("DEBUG", "item count")).map_each(i -> i.uppercase())`,
+			`{"items":["a","b"]}`,
+			`{"items":["A","B"]}`,
+		),
+	).Param(ParamQuery("query", "A query to execute with the target as its context, the result of which is discarded.", false)),
+	tapMethod,
+)
+
+func tapMethod(target Function, args *ParsedParams) (Function, error) {
+	queryFn, err := args.FieldQuery("query")
+	if err != nil {
+		return nil, err
+	}
+	return ClosureFunction("method tap", func(ctx FunctionContext) (any, error) {
+		res, err := target.Exec(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := queryFn.Exec(ctx.WithValue(res)); err != nil {
+			return nil, err
+		}
+		return res, nil
+	}, func(ctx TargetsContext) (TargetsContext, []TargetPath) {
+		queryCtx, targets := target.QueryTargets(ctx)
+		_, queryTargets := queryFn.QueryTargets(queryCtx.WithValues(targets).WithValuesAsContext())
+		return queryCtx, append(targets, queryTargets...)
+	}), nil
+}
+
+//------------------------------------------------------------------------------
+
+var _ = registerMethod(
+	NewMethodSpec(
+		"retry",
+		"Executes a query with the target value as its context up to a number of attempts, returning the result of the first attempt that succeeds, or the error of the last attempt if they all fail. This adds resilience to queries that call functions or methods which can fail transiently. Only the query is retried, an error resolving the target value is returned immediately. Waiting between attempts is abandoned if the message being mapped is cancelled.",
+		NewExampleSpec("",
+			`root.doc = this.path.retry(3, p -> file(p).parse_json(), "100ms")`,
+		),
+	).
+		Param(ParamInt64("attempts", "The maximum number of times to execute the query, which must be at least one.")).
+		Param(ParamQuery("query", "A query to execute with the target as its context.", false)).
+		Param(ParamString("delay", "A duration string describing how long to wait between attempts, such as `100ms` or `1s`.").Default("0s")).
+		MarkImpure(),
+	retryMethod,
+)
+
+func retryMethod(target Function, args *ParsedParams) (Function, error) {
+	attempts, err := args.FieldInt64("attempts")
+	if err != nil {
+		return nil, err
+	}
+	if attempts < 1 {
+		return nil, fmt.Errorf("attempts must be at least one, got %v", attempts)
+	}
+	queryFn, err := args.FieldQuery("query")
+	if err != nil {
+		return nil, err
+	}
+	delayStr, err := args.FieldString("delay")
+	if err != nil {
+		return nil, err
+	}
+	delay, err := time.ParseDuration(delayStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse delay: %w", err)
+	}
+	return ClosureFunction("method retry", func(ctx FunctionContext) (any, error) {
+		v, err := target.Exec(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for attempt := int64(1); ; attempt++ {
+			res, err := queryFn.Exec(ctx.WithValue(v))
+			if err == nil {
+				return res, nil
+			}
+			if attempt >= attempts {
+				return nil, fmt.Errorf("failed after %v attempts: %w", attempts, err)
+			}
+			if delay > 0 {
+				select {
+				case <-time.After(delay):
+				case <-ctx.MsgContext().Done():
+					return nil, fmt.Errorf("attempt %v: %w", attempt, ctx.MsgContext().Err())
+				}
+			}
+		}
+	}, func(ctx TargetsContext) (TargetsContext, []TargetPath) {
+		queryCtx, targets := target.QueryTargets(ctx)
+		_, queryTargets := queryFn.QueryTargets(queryCtx.WithValues(targets).WithValuesAsContext())
+		return queryCtx, append(targets, queryTargets...)
+	}), nil
+}
+
+//------------------------------------------------------------------------------
+
+var _ = registerMethod(
+	NewMethodSpec(
+		"timeout",
+		"Executes a query with the target value as its context under a deadline, returning the result of the query or an error if the deadline is exceeded. The deadline is applied to the context of the message being mapped, and so operations within the query that observe that context, such as waiting between the attempts of <<retry, `retry`>>, are cancelled once it is exceeded. The query is executed synchronously, and therefore operations that do not observe the context are not interrupted, instead their result is discarded and an error returned if they complete after the deadline.",
+		NewExampleSpec("",
+			`root.doc = this.path.timeout("5s", p -> p.retry(10, path -> file(path).parse_json(), "1s"))`,
+		),
+	).
+		Param(ParamString("duration", "A duration string describing the deadline of the query, such as `500ms` or `5s`.")).
+		Param(ParamQuery("query", "A query to execute with the target as its context.", false)).
+		MarkImpure(),
+	timeoutMethod,
+)
+
+func timeoutMethod(target Function, args *ParsedParams) (Function, error) {
+	durationStr, err := args.FieldString("duration")
+	if err != nil {
+		return nil, err
+	}
+	duration, err := time.ParseDuration(durationStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse duration: %w", err)
+	}
+	if duration <= 0 {
+		return nil, fmt.Errorf("duration must be greater than zero, got %v", duration)
+	}
+	queryFn, err := args.FieldQuery("query")
+	if err != nil {
+		return nil, err
+	}
+	return ClosureFunction("method timeout", func(ctx FunctionContext) (any, error) {
+		v, err := target.Exec(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		tCtx, done := context.WithTimeout(ctx.MsgContext(), duration)
+		defer done()
+
+		res, err := queryFn.Exec(ctx.WithMsgContext(tCtx).WithValue(v))
+		if errors.Is(tCtx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("query exceeded timeout of %v", duration)
+		}
+		return res, err
+	}, func(ctx TargetsContext) (TargetsContext, []TargetPath) {
+		queryCtx, targets := target.QueryTargets(ctx)
+		_, queryTargets := queryFn.QueryTargets(queryCtx.WithValues(targets).WithValuesAsContext())
+		return queryCtx, append(targets, queryTargets...)
+	}), nil
+}
+
+//------------------------------------------------------------------------------
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"enum_map",
+		"Looks up the target value within a table of values, returning the matching value if one exists, otherwise the default is returned. The target value is converted to a string before it is looked up, allowing numbers and booleans to be used as keys. If a default is not provided and there is no matching value then an error is returned.",
+		NewExampleSpec("",
+			`root.status = this.code.enum_map({"200":"ok","404":"not found","500":"error"}, "unknown")`,
+			`{"code":404}`,
+			`{"status":"not found"}`,
+			`{"code":418}`,
+			`{"status":"unknown"}`,
+		),
+		NewExampleSpec("",
+			`root.level = this.level.enum_map({"d":"debug","i":"info","w":"warn","e":"error"}).catch("invalid")`,
+			`{"level":"w"}`,
+			`{"level":"warn"}`,
+			`{"level":"x"}`,
+			`{"level":"invalid"}`,
+		),
+	).
+		Param(ParamObject("table", "An object of keys to the values they map to.")).
+		Param(ParamQuery("default", "A value to yield, or query to execute, if the target value is not found within the table.", true).Optional()),
+	func(args *ParsedParams) (simpleMethod, error) {
+		tableV, err := args.Field("table")
+		if err != nil {
+			return nil, err
+		}
+		table, ok := tableV.(map[string]any)
+		if !ok {
+			return nil, value.NewTypeError(tableV, value.TObject)
+		}
+		defaultFn, err := args.FieldOptionalQuery("default")
+		if err != nil {
+			return nil, err
+		}
+		return func(v any, ctx FunctionContext) (any, error) {
+			key := value.IToString(v)
+			if res, exists := table[key]; exists {
+				return res, nil
+			}
+			if defaultFn == nil {
+				return nil, fmt.Errorf("value %q was not found in the table", key)
+			}
+			return defaultFn.Exec(ctx)
+		}, nil
+	},
+)
+
+//------------------------------------------------------------------------------
+
 var _ = registerMethod(
 	NewMethodSpec(
 		"from",
@@ -405,6 +685,189 @@ func notMethodCtor(target Function, _ *ParsedParams) (Function, error) {
 
 //------------------------------------------------------------------------------
 
+var logValueLevels = map[string]struct{}{
+	"TRACE": {},
+	"DEBUG": {},
+	"INFO":  {},
+	"WARN":  {},
+	"ERROR": {},
+}
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"log_value",
+		"Writes a message to the logger of the component executing the mapping along with the target value, which is added to the log as the field `value`, and then returns the target value unchanged. This makes it possible to inspect intermediate values of a mapping whilst debugging without altering its result. When the mapping is not executed by a component the value is returned without being logged.",
+		NewExampleSpec("",
+			`root.name = this.name.log_value("DEBUG", "name before formatting").uppercase()`,
+			`{"name":"foo"}`,
+			`{"name":"FOO"}`,
+		),
+	).
+		Param(ParamString("level", "The level to log at, one of `TRACE`, `DEBUG`, `INFO`, `WARN` or `ERROR`.")).
+		Param(ParamString("message", "A message to log alongside the value.")).
+		MarkImpure(),
+	func(args *ParsedParams) (simpleMethod, error) {
+		level, err := args.FieldString("level")
+		if err != nil {
+			return nil, err
+		}
+		level = strings.ToUpper(level)
+		if _, exists := logValueLevels[level]; !exists {
+			return nil, fmt.Errorf("log level not recognised: %v", level)
+		}
+		message, err := args.FieldString("message")
+		if err != nil {
+			return nil, err
+		}
+		return func(v any, ctx FunctionContext) (any, error) {
+			if ctx.Resources != nil {
+				ctx.Resources.Log(level, message, "value", value.IToString(v))
+			}
+			return v, nil
+		}, nil
+	},
+)
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"log_sampled",
+		"Writes a message to the logger of the component executing the mapping along with the target value, in the same way as `log_value`, but only for a fraction of invocations, and then returns the target value unchanged. This makes it possible to spot-check values flowing through a mapping at high throughput without flooding logs. Sampling is deterministic rather than random, each instance of the method counts its invocations within the process and logs the first invocation followed by one in every `1/rate` after it, so a rate of `0.01` logs every 100th invocation. When the mapping is not executed by a component the value is returned without being logged. The arguments of this method must be literal values, as the invocation count would otherwise be lost whenever they change.",
+		NewExampleSpec("",
+			`root.price = this.price.log_sampled("INFO", "price sample", 0.001).number()`,
+			`{"price":"10.5"}`,
+			`{"price":10.5}`,
+		),
+	).
+		Param(ParamString("level", "The level to log at, one of `TRACE`, `DEBUG`, `INFO`, `WARN` or `ERROR`.").DisableDynamic()).
+		Param(ParamString("message", "A message to log alongside the value.").DisableDynamic()).
+		Param(ParamFloat("rate", "The fraction of invocations to log, greater than zero and up to `1`.").DisableDynamic()).
+		MarkImpure(),
+	func(args *ParsedParams) (simpleMethod, error) {
+		level, err := args.FieldString("level")
+		if err != nil {
+			return nil, err
+		}
+		level = strings.ToUpper(level)
+		if _, exists := logValueLevels[level]; !exists {
+			return nil, fmt.Errorf("log level not recognised: %v", level)
+		}
+		message, err := args.FieldString("message")
+		if err != nil {
+			return nil, err
+		}
+		rate, err := args.FieldFloat("rate")
+		if err != nil {
+			return nil, err
+		}
+		if rate <= 0 || rate > 1 {
+			return nil, fmt.Errorf("rate must be greater than zero and at most 1, got %v", rate)
+		}
+		var invocations atomic.Int64
+		return func(v any, ctx FunctionContext) (any, error) {
+			n := invocations.Add(1) - 1
+			if ctx.Resources != nil && math.Floor(float64(n)*rate) != math.Floor(float64(n-1)*rate) {
+				ctx.Resources.Log(level, message, "value", value.IToString(v))
+			}
+			return v, nil
+		}, nil
+	},
+)
+
+//------------------------------------------------------------------------------
+
+func metricLabels(args *ParsedParams) (map[string]string, error) {
+	labelsV, err := args.Field("labels")
+	if err != nil {
+		return nil, err
+	}
+	labelsObj, ok := labelsV.(map[string]any)
+	if !ok {
+		return nil, value.NewTypeError(labelsV, value.TObject)
+	}
+	labels := make(map[string]string, len(labelsObj))
+	for k, v := range labelsObj {
+		labels[k] = value.IToString(v)
+	}
+	return labels, nil
+}
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"metric_counter",
+		"Increments a counter metric, emitted to the metrics exporter of the component executing the mapping, and returns the target value unchanged. Labels are provided as an object of label names to values, where non-string values are converted to strings. When the mapping is not executed by a component the value is returned without a metric being emitted. The same metric name must always be used with the same set of label names.",
+		NewExampleSpec("",
+			`root = this.metric_counter("events_by_type", 1, {"type": this.type})`,
+			`{"type":"click"}`,
+			`{"type":"click"}`,
+		),
+	).
+		Param(ParamString("name", "The name of the metric.")).
+		Param(ParamFloat("value", "The amount to increment the counter by, which must not be negative.").Default(1)).
+		Param(ParamObject("labels", "An object of label names to values.").Default(map[string]any{})).
+		MarkImpure(),
+	func(args *ParsedParams) (simpleMethod, error) {
+		name, err := args.FieldString("name")
+		if err != nil {
+			return nil, err
+		}
+		count, err := args.FieldFloat("value")
+		if err != nil {
+			return nil, err
+		}
+		if count < 0 {
+			return nil, fmt.Errorf("counter value must not be negative, got %v", count)
+		}
+		labels, err := metricLabels(args)
+		if err != nil {
+			return nil, err
+		}
+		return func(v any, ctx FunctionContext) (any, error) {
+			if ctx.Resources != nil {
+				ctx.Resources.IncrCounter(name, count, labels)
+			}
+			return v, nil
+		}, nil
+	},
+)
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"metric_gauge",
+		"Sets the value of a gauge metric, emitted to the metrics exporter of the component executing the mapping, and returns the target value unchanged. Labels are provided as an object of label names to values, where non-string values are converted to strings. When the mapping is not executed by a component the value is returned without a metric being emitted. The same metric name must always be used with the same set of label names.",
+		NewExampleSpec("",
+			`root = this.metric_gauge("queue_depth", this.depth, {"queue": this.queue})`,
+			`{"depth":12,"queue":"orders"}`,
+			`{"depth":12,"queue":"orders"}`,
+		),
+	).
+		Param(ParamString("name", "The name of the metric.")).
+		Param(ParamFloat("value", "The value to set the gauge to.")).
+		Param(ParamObject("labels", "An object of label names to values.").Default(map[string]any{})).
+		MarkImpure(),
+	func(args *ParsedParams) (simpleMethod, error) {
+		name, err := args.FieldString("name")
+		if err != nil {
+			return nil, err
+		}
+		gaugeValue, err := args.FieldFloat("value")
+		if err != nil {
+			return nil, err
+		}
+		labels, err := metricLabels(args)
+		if err != nil {
+			return nil, err
+		}
+		return func(v any, ctx FunctionContext) (any, error) {
+			if ctx.Resources != nil {
+				ctx.Resources.SetGauge(name, gaugeValue, labels)
+			}
+			return v, nil
+		}, nil
+	},
+)
+
+//------------------------------------------------------------------------------
+
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"not_null", "",
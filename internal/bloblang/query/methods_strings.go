@@ -26,10 +26,12 @@ import (
 	"net/url"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/OneOfOne/xxhash"
+	"github.com/pelletier/go-toml/v2"
 	"github.com/tilinna/z85"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
@@ -197,7 +199,7 @@ var _ = registerSimpleMethod(
 		"decode", "",
 	).InCategory(
 		MethodCategoryEncoding,
-		"Decodes an encoded string target according to a chosen scheme and returns the result as a byte array. When mapping the result to a JSON field the value should be cast to a string using the method `string`, or encoded using the method `encode`, otherwise it will be base64 encoded by default.\n\nAvailable schemes are: `base64`, `base64url` https://rfc-editor.org/rfc/rfc4648.html[(RFC 4648 with padding characters)], `base64rawurl` https://rfc-editor.org/rfc/rfc4648.html[(RFC 4648 without padding characters)], `hex`, `ascii85`.",
+		"Decodes an encoded string target according to a chosen scheme and returns the result as a byte array. When mapping the result to a JSON field the value should be cast to a string using the method `string`, or encoded using the method `encode`, otherwise it will be base64 encoded by default.\n\nAvailable schemes are: `base64`, `base64url` https://rfc-editor.org/rfc/rfc4648.html[(RFC 4648 with padding characters)], `base64rawurl` https://rfc-editor.org/rfc/rfc4648.html[(RFC 4648 without padding characters)], `base64_lenient`, `hex`, `ascii85`.\n\nThe `base64_lenient` scheme is useful for data that has been mangled in transit: all whitespace (such as line breaks) is removed, missing padding characters are tolerated and both the standard and URL-safe alphabets are accepted.",
 		// NOTE: z85 has been removed from the list until we can support
 		// misaligned data automatically. It'll still be supported for backwards
 		// compatibility, but given it behaves differently to `ascii85` I think
@@ -207,6 +209,13 @@ var _ = registerSimpleMethod(
 			`{"value":"68656c6c6f20776f726c64"}`,
 			`{"decoded":"hello world"}`,
 		),
+		NewExampleSpec("",
+			`root.decoded = this.value.decode("base64_lenient").string()`,
+			`{"value":"aGVsbG8g\nd29ybGQ"}`,
+			`{"decoded":"hello world"}`,
+			`{"value":"PDw_Pz8-Pg"}`,
+			`{"decoded":"<<???>>"}`,
+		),
 		NewExampleSpec("",
 			`root = this.encoded.decode("ascii85")`,
 			"{\"encoded\":\"FD,B0+DGm>FDl80Ci\\\"A>F`)8BEckl6F`M&(+Cno&@/\"}",
@@ -236,6 +245,8 @@ var _ = registerSimpleMethod(
 				e := base64.NewDecoder(base64.RawURLEncoding, bytes.NewReader(b))
 				return io.ReadAll(e)
 			}
+		case "base64_lenient":
+			schemeFn = decodeBase64Lenient
 		case "hex":
 			schemeFn = func(b []byte) ([]byte, error) {
 				e := hex.NewDecoder(bytes.NewReader(b))
@@ -276,6 +287,27 @@ var _ = registerSimpleMethod(
 	},
 )
 
+func decodeBase64Lenient(b []byte) ([]byte, error) {
+	normalised := make([]byte, 0, len(b))
+	for _, c := range b {
+		switch c {
+		case ' ', '\t', '\n', '\r', '\v', '\f', '=':
+		case '-':
+			normalised = append(normalised, '+')
+		case '_':
+			normalised = append(normalised, '/')
+		default:
+			normalised = append(normalised, c)
+		}
+	}
+	dec := make([]byte, base64.RawStdEncoding.DecodedLen(len(normalised)))
+	n, err := base64.RawStdEncoding.Decode(dec, normalised)
+	if err != nil {
+		return nil, err
+	}
+	return dec[:n], nil
+}
+
 //------------------------------------------------------------------------------
 
 var _ = registerSimpleMethod(
@@ -548,6 +580,42 @@ var _ = registerSimpleMethod(
 
 //------------------------------------------------------------------------------
 
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"count", "",
+	).InCategory(
+		MethodCategoryStrings,
+		"Returns the number of non-overlapping occurrences of the argument substring in a string target. If the substring is empty then the number of Unicode code points in the target plus one is returned, since an empty substring matches before and after each code point.",
+		NewExampleSpec("",
+			`root.count = this.thing.count("an")`,
+			`{"thing":"banana"}`,
+			`{"count":2}`,
+		),
+		NewExampleSpec("",
+			`root.lines = content().count("\n") + 1`,
+			"first line\nsecond line\nthird line",
+			`{"lines":3}`,
+		),
+	).Param(ParamString("value", "A string to search for.")),
+	func(args *ParsedParams) (simpleMethod, error) {
+		substring, err := args.FieldString("value")
+		if err != nil {
+			return nil, err
+		}
+		return func(v any, ctx FunctionContext) (any, error) {
+			switch t := v.(type) {
+			case string:
+				return int64(strings.Count(t, substring)), nil
+			case []byte:
+				return int64(bytes.Count(t, []byte(substring))), nil
+			}
+			return nil, value.NewTypeError(v, value.TString)
+		}, nil
+	},
+)
+
+//------------------------------------------------------------------------------
+
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"unescape_html", "",
@@ -1116,6 +1184,153 @@ func parseCSVMethod(args *ParsedParams) (simpleMethod, error) {
 	}, nil
 }
 
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"format_csv", "",
+	).InCategory(
+		MethodCategoryParsing,
+		"Serializes an array of objects or an array of arrays into a CSV byte array following the format described in RFC 4180. When the rows are objects the columns are the sorted union of their keys unless `columns` is specified, and fields missing from an object are left empty. Null values are written as empty fields and structured values are written as JSON.",
+		NewExampleSpec("Serializes an array of objects with a header row",
+			`root = this.orders.format_csv().string()`,
+			`{"orders":[{"foo":"foo 1","bar":"bar 1"},{"foo":"foo 2","bar":"bar 2"}]}`,
+			`bar,foo
+bar 1,foo 1
+bar 2,foo 2
+`,
+		),
+		NewExampleSpec("Serializes an array of objects with an explicit column order and without a header row",
+			`root = this.orders.format_csv(delimiter: ";", header: false, columns: ["foo","bar"]).string()`,
+			`{"orders":[{"foo":"foo 1","bar":"bar 1"},{"foo":"foo 2"}]}`,
+			`foo 1;bar 1
+foo 2;
+`,
+		),
+		NewExampleSpec("Serializes an array of arrays, where the columns are emitted as a header row",
+			`root = this.orders.format_csv(columns: ["foo","bar"]).string()`,
+			`{"orders":[["foo 1","bar 1"],["foo 2","bar 2"]]}`,
+			`foo,bar
+foo 1,bar 1
+foo 2,bar 2
+`,
+		),
+	).
+		Param(ParamString("delimiter", "The delimiter to use for separating values in each record. It must be a single character.").Default(",")).
+		Param(ParamBool("header", "Whether to emit a header row of column names. When the rows are arrays a header row is only emitted when `columns` is specified.").Default(true)).
+		Param(ParamArray("columns", "An optional list of column names determining which fields of object rows are written and in which order.").Optional()),
+	formatCSVMethod,
+)
+
+func formatCSVMethod(args *ParsedParams) (simpleMethod, error) {
+	delimStr, err := args.FieldString("delimiter")
+	if err != nil {
+		return nil, err
+	}
+	delimRunes := []rune(delimStr)
+	if len(delimRunes) != 1 {
+		return nil, errors.New("delimiter value must be exactly one character")
+	}
+	delimiter := delimRunes[0]
+
+	header, err := args.FieldBool("header")
+	if err != nil {
+		return nil, err
+	}
+
+	var columns []string
+	optColumns, err := args.FieldOptionalArray("columns")
+	if err != nil {
+		return nil, err
+	}
+	if optColumns != nil {
+		for i, c := range *optColumns {
+			cStr, ok := c.(string)
+			if !ok {
+				return nil, fmt.Errorf("columns index %v: %w", i, value.NewTypeError(c, value.TString))
+			}
+			columns = append(columns, cStr)
+		}
+	}
+
+	csvField := func(v any) string {
+		if v == nil {
+			return ""
+		}
+		return value.IToString(v)
+	}
+
+	return func(v any, ctx FunctionContext) (any, error) {
+		rows, ok := v.([]any)
+		if !ok {
+			return nil, value.NewTypeError(v, value.TArray)
+		}
+
+		var objRows, arrRows bool
+		for i, row := range rows {
+			switch row.(type) {
+			case map[string]any:
+				objRows = true
+			case []any:
+				arrRows = true
+			default:
+				return nil, fmt.Errorf("index %v: %w", i, value.NewTypeError(row, value.TObject, value.TArray))
+			}
+			if objRows && arrRows {
+				return nil, fmt.Errorf("index %v: rows must be either all objects or all arrays", i)
+			}
+		}
+
+		rowColumns := columns
+		if objRows && rowColumns == nil {
+			keySet := map[string]struct{}{}
+			for _, row := range rows {
+				for k := range row.(map[string]any) {
+					keySet[k] = struct{}{}
+				}
+			}
+			rowColumns = make([]string, 0, len(keySet))
+			for k := range keySet {
+				rowColumns = append(rowColumns, k)
+			}
+			sort.Strings(rowColumns)
+		}
+
+		var buf bytes.Buffer
+		w := csv.NewWriter(&buf)
+		w.Comma = delimiter
+
+		if header && len(rowColumns) > 0 {
+			if err := w.Write(rowColumns); err != nil {
+				return nil, err
+			}
+		}
+
+		for _, row := range rows {
+			var record []string
+			switch t := row.(type) {
+			case map[string]any:
+				record = make([]string, len(rowColumns))
+				for i, c := range rowColumns {
+					record[i] = csvField(t[c])
+				}
+			case []any:
+				record = make([]string, len(t))
+				for i, e := range t {
+					record[i] = csvField(e)
+				}
+			}
+			if err := w.Write(record); err != nil {
+				return nil, err
+			}
+		}
+
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}, nil
+}
+
 //------------------------------------------------------------------------------
 
 var _ = registerSimpleMethod(
@@ -1222,6 +1437,190 @@ var _ = registerSimpleMethod(
 	},
 )
 
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"parse_ini", "",
+	).InCategory(
+		MethodCategoryParsing,
+		"Attempts to parse a string as an INI document and returns an object where each section is a nested object of its key value pairs. Keys that precede the first section are added to the root of the object. Lines beginning with `;` or `#` are ignored as comments, keys and values are trimmed of whitespace, and values wrapped in matching single or double quotes are unquoted. All values are parsed as strings, and when a key is repeated within a section the last value is used.",
+		NewExampleSpec("",
+			`root.doc = this.doc.parse_ini()`,
+			`{"doc":"name = agent\n\n[server]\nhost = 127.0.0.1\nport = 8080\n\n; comment\n[paths]\nlogs = \"/var/log/agent\""}`,
+			`{"doc":{"name":"agent","paths":{"logs":"/var/log/agent"},"server":{"host":"127.0.0.1","port":"8080"}}}`,
+		),
+	),
+	func(*ParsedParams) (simpleMethod, error) {
+		return func(v any, ctx FunctionContext) (any, error) {
+			var iniStr string
+			switch t := v.(type) {
+			case string:
+				iniStr = t
+			case []byte:
+				iniStr = string(t)
+			default:
+				return nil, value.NewTypeError(v, value.TString)
+			}
+			return parseINI(iniStr)
+		}, nil
+	},
+)
+
+func parseINI(s string) (map[string]any, error) {
+	root := map[string]any{}
+	current := root
+
+	for i, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == ';' || line[0] == '#' {
+			continue
+		}
+
+		if line[0] == '[' {
+			if line[len(line)-1] != ']' {
+				return nil, fmt.Errorf("line %v: section header is missing a closing bracket", i+1)
+			}
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			if name == "" {
+				return nil, fmt.Errorf("line %v: section name is empty", i+1)
+			}
+			section, exists := root[name].(map[string]any)
+			if !exists {
+				if _, isKey := root[name]; isKey {
+					return nil, fmt.Errorf("line %v: section %v conflicts with a key of the same name", i+1, name)
+				}
+				section = map[string]any{}
+				root[name] = section
+			}
+			current = section
+			continue
+		}
+
+		k, val, found := strings.Cut(line, "=")
+		if !found {
+			return nil, fmt.Errorf("line %v: expected a key value pair or section header", i+1)
+		}
+		if k = strings.TrimSpace(k); k == "" {
+			return nil, fmt.Errorf("line %v: key is empty", i+1)
+		}
+		if _, isSection := current[k].(map[string]any); isSection {
+			return nil, fmt.Errorf("line %v: key %v conflicts with a section of the same name", i+1, k)
+		}
+
+		val = strings.TrimSpace(val)
+		if len(val) >= 2 && (val[0] == '"' || val[0] == '\'') && val[len(val)-1] == val[0] {
+			val = val[1 : len(val)-1]
+		}
+		current[k] = val
+	}
+	return root, nil
+}
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"parse_toml", "",
+	).InCategory(
+		MethodCategoryParsing,
+		"Attempts to parse a string as a TOML document and returns the result. Offset date-times are parsed as timestamps, whereas local dates, times and date-times are parsed as strings.",
+		NewExampleSpec("",
+			`root.doc = this.doc.parse_toml()`,
+			`{"doc":"foo = \"bar\"\n\n[baz]\nbuz = 10"}`,
+			`{"doc":{"baz":{"buz":10},"foo":"bar"}}`,
+		),
+	),
+	func(*ParsedParams) (simpleMethod, error) {
+		return func(v any, ctx FunctionContext) (any, error) {
+			var tomlBytes []byte
+			switch t := v.(type) {
+			case string:
+				tomlBytes = []byte(t)
+			case []byte:
+				tomlBytes = t
+			default:
+				return nil, value.NewTypeError(v, value.TString)
+			}
+			var sObj map[string]any
+			if err := toml.Unmarshal(tomlBytes, &sObj); err != nil {
+				return nil, fmt.Errorf("failed to parse value as TOML: %w", err)
+			}
+			return normaliseTOMLValue(sObj), nil
+		}, nil
+	},
+)
+
+// normaliseTOMLValue converts the local date and time types produced by the
+// TOML parser, which have no bloblang equivalent, into strings.
+func normaliseTOMLValue(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, e := range t {
+			t[k] = normaliseTOMLValue(e)
+		}
+	case []any:
+		for i, e := range t {
+			t[i] = normaliseTOMLValue(e)
+		}
+	case toml.LocalDate:
+		return t.String()
+	case toml.LocalTime:
+		return t.String()
+	case toml.LocalDateTime:
+		return t.String()
+	}
+	return v
+}
+
+// tomlCompatibleValue returns a copy of a value where numbers and byte arrays
+// are converted into types that the TOML serializer encodes as expected.
+func tomlCompatibleValue(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(t))
+		for k, e := range t {
+			m[k] = tomlCompatibleValue(e)
+		}
+		return m
+	case []any:
+		a := make([]any, len(t))
+		for i, e := range t {
+			a[i] = tomlCompatibleValue(e)
+		}
+		return a
+	case json.Number:
+		return value.ISanitize(t)
+	case []byte:
+		return string(t)
+	}
+	return v
+}
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"format_toml", "",
+	).InCategory(
+		MethodCategoryParsing,
+		"Serializes a target object into a TOML byte array.",
+		NewExampleSpec("",
+			`root = this.doc.format_toml()`,
+			`{"doc":{"foo":"bar"}}`,
+			`foo = 'bar'
+`,
+		),
+		NewExampleSpec("Use the `.string()` method in order to coerce the result into a string.",
+			`root.doc = this.doc.format_toml().string()`,
+			`{"doc":{"foo":"bar","baz":{"buz":10}}}`,
+			`{"doc":"foo = 'bar'\n\n[baz]\nbuz = 10\n"}`,
+		),
+	),
+	func(*ParsedParams) (simpleMethod, error) {
+		return func(v any, ctx FunctionContext) (any, error) {
+			if _, ok := v.(map[string]any); !ok {
+				return nil, value.NewTypeError(v, value.TObject)
+			}
+			return toml.Marshal(tomlCompatibleValue(v))
+		}, nil
+	},
+)
+
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"format_json", "",
@@ -1892,6 +2291,78 @@ func reReplaceAllImpl(args *ParsedParams) (simpleMethod, error) {
 
 //------------------------------------------------------------------------------
 
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"re_replace_all_many", "",
+	).InCategory(
+		MethodCategoryRegexp,
+		"For each pair of values in an argument array, replaces all occurrences of the regular expression of the first item of the pair with the second. Each pattern is compiled once and the replacements are applied in the order they are specified, so the result of one replacement can be matched by the patterns that follow it. Inside each replacement value $ signs are interpreted as submatch expansions, e.g. `$1` represents the text of the first submatch. This is a more compact and efficient way of chaining a series of `re_replace_all` methods.",
+		NewExampleSpec("",
+			`root.new_value = this.value.re_replace_all_many([
+  "(?i)password=\\S+", "password=****",
+  "([0-9]{4})-([0-9]{2})-([0-9]{2})", "$3/$2/$1",
+  "\\s+", " ",
+])`,
+			`{"value":"user=ash   PASSWORD=hunter2 created=2025-01-31"}`,
+			`{"new_value":"user=ash password=**** created=31/01/2025"}`,
+		),
+	).Param(ParamArray("values", "An array of values, each even value is a regular expression that will be replaced with the following odd value.")),
+	func(args *ParsedParams) (simpleMethod, error) {
+		items, err := args.FieldArray("values")
+		if err != nil {
+			return nil, err
+		}
+		if len(items)%2 != 0 {
+			return nil, fmt.Errorf("invalid arg, replacements should be in pairs and must therefore be even: %v", items)
+		}
+
+		type reReplacePair struct {
+			re        *regexp.Regexp
+			with      string
+			withBytes []byte
+		}
+
+		replacePairs := make([]reReplacePair, 0, len(items)/2)
+		for i := 0; i < len(items); i += 2 {
+			reStr, err := value.IGetString(items[i])
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern at index %v: %w", i, err)
+			}
+			re, err := regexp.Compile(reStr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern at index %v: %w", i, err)
+			}
+			with, err := value.IGetString(items[i+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid replacement value at index %v: %w", i+1, err)
+			}
+			replacePairs = append(replacePairs, reReplacePair{
+				re:        re,
+				with:      with,
+				withBytes: []byte(with),
+			})
+		}
+
+		return func(v any, ctx FunctionContext) (any, error) {
+			switch t := v.(type) {
+			case string:
+				for _, pair := range replacePairs {
+					t = pair.re.ReplaceAllString(t, pair.with)
+				}
+				return t, nil
+			case []byte:
+				for _, pair := range replacePairs {
+					t = pair.re.ReplaceAll(t, pair.withBytes)
+				}
+				return string(t), nil
+			}
+			return nil, value.NewTypeError(v, value.TString)
+		}, nil
+	},
+)
+
+//------------------------------------------------------------------------------
+
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"split", "",
@@ -1934,6 +2405,140 @@ var _ = registerSimpleMethod(
 
 //------------------------------------------------------------------------------
 
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"split_n", "",
+	).InCategory(
+		MethodCategoryStrings,
+		"Split a string value into an array of at most `n` strings by splitting it on a string separator, where the last element contains the unsplit remainder of the string. An `n` of `-1` (or any negative number) splits on all occurrences in the same way as <<split, `split`>>, and an `n` of zero results in an empty array.",
+		NewExampleSpec("",
+			`root.pair = this.value.split_n("=", 2)`,
+			`{"value":"key=value=with=equals"}`,
+			`{"pair":["key","value=with=equals"]}`,
+		),
+	).
+		Param(ParamString("delimiter", "The delimiter to split with.")).
+		Param(ParamInt64("n", "The maximum number of substrings to return.")),
+	func(args *ParsedParams) (simpleMethod, error) {
+		delim, err := args.FieldString("delimiter")
+		if err != nil {
+			return nil, err
+		}
+		n, err := args.FieldInt64("n")
+		if err != nil {
+			return nil, err
+		}
+		delimB := []byte(delim)
+		return func(v any, ctx FunctionContext) (any, error) {
+			switch t := v.(type) {
+			case string:
+				bits := strings.SplitN(t, delim, int(n))
+				vals := make([]any, 0, len(bits))
+				for _, b := range bits {
+					vals = append(vals, b)
+				}
+				return vals, nil
+			case []byte:
+				bits := bytes.SplitN(t, delimB, int(n))
+				vals := make([]any, 0, len(bits))
+				for _, b := range bits {
+					vals = append(vals, b)
+				}
+				return vals, nil
+			}
+			return nil, value.NewTypeError(v, value.TString)
+		}, nil
+	},
+)
+
+//------------------------------------------------------------------------------
+
+// rsplitN splits a string on a separator from the right into at most n
+// substrings, where the first substring contains the unsplit remainder.
+func rsplitN(s, sep string, n int) []string {
+	if n == 0 {
+		return []string{}
+	}
+	if n < 0 {
+		return strings.Split(s, sep)
+	}
+	if sep == "" {
+		// Splitting on an empty separator splits on each UTF-8 sequence, and so
+		// the leading sequences are joined back into the remainder.
+		parts := strings.Split(s, sep)
+		if len(parts) <= n {
+			return parts
+		}
+		head := len(parts) - n + 1
+		return append([]string{strings.Join(parts[:head], sep)}, parts[head:]...)
+	}
+
+	parts := make([]string, n)
+	i := n - 1
+	for ; i > 0; i-- {
+		idx := strings.LastIndex(s, sep)
+		if idx < 0 {
+			break
+		}
+		parts[i] = s[idx+len(sep):]
+		s = s[:idx]
+	}
+	parts[i] = s
+	return parts[i:]
+}
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"rsplit", "",
+	).InCategory(
+		MethodCategoryStrings,
+		"Split a string value into an array of at most `n` strings by splitting it on a string separator starting from the end of the string, where the first element contains the unsplit remainder of the string. An `n` of `-1` (or any negative number) splits on all occurrences in the same way as <<split, `split`>>, and an `n` of zero results in an empty array.",
+		NewExampleSpec("",
+			`root.parts = this.filename.rsplit(".", 2)`,
+			`{"filename":"archive.tar.gz"}`,
+			`{"parts":["archive.tar","gz"]}`,
+		),
+		NewExampleSpec("",
+			`root.parts = this.path.rsplit("/", 3)`,
+			`{"path":"a/b/c/d/e"}`,
+			`{"parts":["a/b/c","d","e"]}`,
+		),
+	).
+		Param(ParamString("delimiter", "The delimiter to split with.")).
+		Param(ParamInt64("n", "The maximum number of substrings to return.")),
+	func(args *ParsedParams) (simpleMethod, error) {
+		delim, err := args.FieldString("delimiter")
+		if err != nil {
+			return nil, err
+		}
+		n, err := args.FieldInt64("n")
+		if err != nil {
+			return nil, err
+		}
+		return func(v any, ctx FunctionContext) (any, error) {
+			switch t := v.(type) {
+			case string:
+				bits := rsplitN(t, delim, int(n))
+				vals := make([]any, 0, len(bits))
+				for _, b := range bits {
+					vals = append(vals, b)
+				}
+				return vals, nil
+			case []byte:
+				bits := rsplitN(string(t), delim, int(n))
+				vals := make([]any, 0, len(bits))
+				for _, b := range bits {
+					vals = append(vals, []byte(b))
+				}
+				return vals, nil
+			}
+			return nil, value.NewTypeError(v, value.TString)
+		}, nil
+	},
+)
+
+//------------------------------------------------------------------------------
+
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"string", "",
@@ -2059,3 +2664,100 @@ root.description = this.description.trim_suffix("_foobar")`,
 		}, nil
 	},
 )
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"wrap", "",
+	).InCategory(
+		MethodCategoryStrings,
+		"Wraps a string with a prefix and a suffix, where the suffix defaults to the prefix when omitted.",
+		NewExampleSpec("",
+			`root.quoted = this.name.wrap("\"")
+root.bracketed = this.name.wrap("[", "]")`,
+			`{"name":"blobton"}`,
+			`{"bracketed":"[blobton]","quoted":"\"blobton\""}`,
+		),
+	).
+		Param(ParamString("prefix", "The prefix to add to the start of the string.")).
+		Param(ParamString("suffix", "An optional suffix to add to the end of the string, defaults to the prefix.").Optional()),
+	func(args *ParsedParams) (simpleMethod, error) {
+		prefix, suffix, err := wrapArgs(args)
+		if err != nil {
+			return nil, err
+		}
+		return func(v any, ctx FunctionContext) (any, error) {
+			switch t := v.(type) {
+			case string:
+				return prefix + t + suffix, nil
+			case []byte:
+				wrapped := make([]byte, 0, len(prefix)+len(t)+len(suffix))
+				wrapped = append(wrapped, prefix...)
+				wrapped = append(wrapped, t...)
+				return append(wrapped, suffix...), nil
+			}
+			return nil, value.NewTypeError(v, value.TString)
+		}, nil
+	},
+)
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"unwrap", "",
+	).InCategory(
+		MethodCategoryStrings,
+		"Removes a prefix and a suffix from a string, where the suffix defaults to the prefix when omitted. The prefix and suffix are only removed when the string has both of them, and they do not overlap, otherwise the string is returned unchanged. This makes it possible to safely strip known wrappers such as quotes or brackets.",
+		NewExampleSpec("",
+			`root.name = this.name.unwrap("[", "]")`,
+			`{"name":"[blobton]"}`,
+			`{"name":"blobton"}`,
+			`{"name":"[blobton"}`,
+			`{"name":"[blobton"}`,
+		),
+		NewExampleSpec("",
+			`root.value = this.value.unwrap("\"")`,
+			`{"value":"\"quoted\""}`,
+			`{"value":"quoted"}`,
+			`{"value":"\""}`,
+			`{"value":"\""}`,
+		),
+	).
+		Param(ParamString("prefix", "The prefix to remove from the start of the string.")).
+		Param(ParamString("suffix", "An optional suffix to remove from the end of the string, defaults to the prefix.").Optional()),
+	func(args *ParsedParams) (simpleMethod, error) {
+		prefix, suffix, err := wrapArgs(args)
+		if err != nil {
+			return nil, err
+		}
+		bytesPrefix, bytesSuffix := []byte(prefix), []byte(suffix)
+		return func(v any, ctx FunctionContext) (any, error) {
+			switch t := v.(type) {
+			case string:
+				if len(t) >= len(prefix)+len(suffix) && strings.HasPrefix(t, prefix) && strings.HasSuffix(t, suffix) {
+					return t[len(prefix) : len(t)-len(suffix)], nil
+				}
+				return t, nil
+			case []byte:
+				if len(t) >= len(prefix)+len(suffix) && bytes.HasPrefix(t, bytesPrefix) && bytes.HasSuffix(t, bytesSuffix) {
+					return t[len(prefix) : len(t)-len(suffix)], nil
+				}
+				return t, nil
+			}
+			return nil, value.NewTypeError(v, value.TString)
+		}, nil
+	},
+)
+
+func wrapArgs(args *ParsedParams) (prefix, suffix string, err error) {
+	if prefix, err = args.FieldString("prefix"); err != nil {
+		return
+	}
+	var suffixOpt *string
+	if suffixOpt, err = args.FieldOptionalString("suffix"); err != nil {
+		return
+	}
+	suffix = prefix
+	if suffixOpt != nil {
+		suffix = *suffixOpt
+	}
+	return
+}
@@ -111,6 +111,96 @@ var _ = registerSimpleMethod(
 	},
 )
 
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"every",
+		"Checks each element of an array against a query and returns true if the query holds for every element. Iteration stops at the first element that fails the query. An error occurs if the target is not an array, or if an element results in the provided query returning a non-boolean result. Unlike <<all, `all`>>, this method returns true if the target array is empty.",
+	).InCategory(
+		MethodCategoryObjectAndArray,
+		"",
+		NewExampleSpec("",
+			`root.all_valid = this.items.every(item -> item.price > 0)`,
+			`{"items":[{"id":"1","price":5},{"id":"2","price":0}]}`,
+			`{"all_valid":false}`,
+			`{"items":[{"id":"1","price":5},{"id":"2","price":3}]}`,
+			`{"all_valid":true}`,
+			`{"items":[]}`,
+			`{"all_valid":true}`,
+		),
+	).Param(ParamQuery("test", "A test query to apply to each element.", false)),
+	func(args *ParsedParams) (simpleMethod, error) {
+		queryFn, err := args.FieldQuery("test")
+		if err != nil {
+			return nil, err
+		}
+		return func(res any, ctx FunctionContext) (any, error) {
+			arr, ok := res.([]any)
+			if !ok {
+				return nil, value.NewTypeError(res, value.TArray)
+			}
+			for i, v := range arr {
+				res, err := queryFn.Exec(ctx.WithValue(v))
+				if err != nil {
+					return nil, fmt.Errorf("element %v: %w", i, err)
+				}
+				b, ok := res.(bool)
+				if !ok {
+					return nil, fmt.Errorf("element %v: %w", i, value.NewTypeError(res, value.TBool))
+				}
+				if !b {
+					return false, nil
+				}
+			}
+			return true, nil
+		}, nil
+	},
+)
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"some",
+		"Checks the elements of an array against a query and returns true if the query holds for at least one element. Iteration stops at the first element that passes the query. An error occurs if the target is not an array, or if an element results in the provided query returning a non-boolean result. Returns false if the target array is empty. This method is equivalent to <<any, `any`>> and is provided as the counterpart to <<every, `every`>>.",
+	).InCategory(
+		MethodCategoryObjectAndArray,
+		"",
+		NewExampleSpec("",
+			`root.has_free_item = this.items.some(item -> item.price == 0)`,
+			`{"items":[{"id":"1","price":5},{"id":"2","price":0}]}`,
+			`{"has_free_item":true}`,
+			`{"items":[{"id":"1","price":5},{"id":"2","price":3}]}`,
+			`{"has_free_item":false}`,
+			`{"items":[]}`,
+			`{"has_free_item":false}`,
+		),
+	).Param(ParamQuery("test", "A test query to apply to each element.", false)),
+	func(args *ParsedParams) (simpleMethod, error) {
+		queryFn, err := args.FieldQuery("test")
+		if err != nil {
+			return nil, err
+		}
+		return func(res any, ctx FunctionContext) (any, error) {
+			arr, ok := res.([]any)
+			if !ok {
+				return nil, value.NewTypeError(res, value.TArray)
+			}
+			for i, v := range arr {
+				res, err := queryFn.Exec(ctx.WithValue(v))
+				if err != nil {
+					return nil, fmt.Errorf("element %v: %w", i, err)
+				}
+				b, ok := res.(bool)
+				if !ok {
+					return nil, fmt.Errorf("element %v: %w", i, value.NewTypeError(res, value.TBool))
+				}
+				if b {
+					return true, nil
+				}
+			}
+			return false, nil
+		}, nil
+	},
+)
+
 //------------------------------------------------------------------------------
 
 var _ = registerSimpleMethod(
@@ -243,6 +333,85 @@ var _ = registerSimpleMethod(
 
 //------------------------------------------------------------------------------
 
+func arrayContains(arr []any, v any) bool {
+	for _, ele := range arr {
+		if value.ICompare(v, ele) {
+			return true
+		}
+	}
+	return false
+}
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"contains_any", "",
+	).InCategory(
+		MethodCategoryObjectAndArray,
+		"Checks whether an array contains an element matching any of the elements of an argument array and returns a boolean result, which is `false` when the argument array is empty. Elements are matched in the same way as <<contains, `contains`>>.",
+		NewExampleSpec("",
+			`root.is_admin = this.roles.contains_any(["admin","owner"])`,
+			`{"roles":["reader","owner"]}`,
+			`{"is_admin":true}`,
+			`{"roles":["reader","writer"]}`,
+			`{"is_admin":false}`,
+		),
+	).Param(ParamArray("values", "An array of values to test against elements of the target.")),
+	func(args *ParsedParams) (simpleMethod, error) {
+		values, err := args.FieldArray("values")
+		if err != nil {
+			return nil, err
+		}
+		return func(v any, ctx FunctionContext) (any, error) {
+			arr, ok := v.([]any)
+			if !ok {
+				return nil, value.NewTypeError(v, value.TArray)
+			}
+			for _, c := range values {
+				if arrayContains(arr, c) {
+					return true, nil
+				}
+			}
+			return false, nil
+		}, nil
+	},
+)
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"contains_all", "",
+	).InCategory(
+		MethodCategoryObjectAndArray,
+		"Checks whether an array contains an element matching each of the elements of an argument array and returns a boolean result, which is `true` when the argument array is empty. Elements are matched in the same way as <<contains, `contains`>>.",
+		NewExampleSpec("",
+			`root.can_publish = this.permissions.contains_all(["write","publish"])`,
+			`{"permissions":["read","write","publish"]}`,
+			`{"can_publish":true}`,
+			`{"permissions":["read","write"]}`,
+			`{"can_publish":false}`,
+		),
+	).Param(ParamArray("values", "An array of values to test against elements of the target.")),
+	func(args *ParsedParams) (simpleMethod, error) {
+		values, err := args.FieldArray("values")
+		if err != nil {
+			return nil, err
+		}
+		return func(v any, ctx FunctionContext) (any, error) {
+			arr, ok := v.([]any)
+			if !ok {
+				return nil, value.NewTypeError(v, value.TArray)
+			}
+			for _, c := range values {
+				if !arrayContains(arr, c) {
+					return false, nil
+				}
+			}
+			return true, nil
+		}, nil
+	},
+)
+
+//------------------------------------------------------------------------------
+
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"enumerated",
@@ -254,8 +423,17 @@ var _ = registerSimpleMethod(
 			`{"foo":["bar","baz"]}`,
 			`{"foo":[{"index":0,"value":"bar"},{"index":1,"value":"baz"}]}`,
 		),
-	),
-	func(*ParsedParams) (simpleMethod, error) {
+		NewExampleSpec("When `tuples` is `true` each element is instead an array containing the index followed by the value.",
+			`root.foo = this.foo.enumerated(tuples: true).map_each(pair -> "%v: %v".format(pair.index(0), pair.index(1)))`,
+			`{"foo":["bar","baz"]}`,
+			`{"foo":["0: bar","1: baz"]}`,
+		),
+	).Param(ParamBool("tuples", "Whether to represent each element as an array of the index and value rather than an object.").Default(false)),
+	func(args *ParsedParams) (simpleMethod, error) {
+		tuples, err := args.FieldBool("tuples")
+		if err != nil {
+			return nil, err
+		}
 		return func(v any, ctx FunctionContext) (any, error) {
 			arr, ok := v.([]any)
 			if !ok {
@@ -263,6 +441,10 @@ var _ = registerSimpleMethod(
 			}
 			enumerated := make([]any, 0, len(arr))
 			for i, ele := range arr {
+				if tuples {
+					enumerated = append(enumerated, []any{int64(i), ele})
+					continue
+				}
 				enumerated = append(enumerated, map[string]any{
 					"index": int64(i),
 					"value": ele,
@@ -275,6 +457,169 @@ var _ = registerSimpleMethod(
 
 //------------------------------------------------------------------------------
 
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"rotate", "",
+	).InCategory(
+		MethodCategoryObjectAndArray,
+		"Returns a new array with the elements of an array circularly shifted by a number of positions, such that the element at index `n` becomes the first element. A negative number shifts the elements in the opposite direction, and numbers larger than the length of the array wrap around.",
+		NewExampleSpec("",
+			`root.a = this.workers.rotate(1)
+root.b = this.workers.rotate(-1)
+root.c = this.workers.rotate(5)`,
+			`{"workers":["w1","w2","w3"]}`,
+			`{"a":["w2","w3","w1"],"b":["w3","w1","w2"],"c":["w3","w1","w2"]}`,
+		),
+		NewExampleSpec("Assign a different starting worker to each message in a round-robin fashion.",
+			`root.order = this.workers.rotate(batch_index())`,
+			`{"workers":["w1","w2","w3"]}`,
+			`{"order":["w1","w2","w3"]}`,
+		),
+	).Param(ParamInt64("n", "The number of positions to shift elements by.")),
+	func(args *ParsedParams) (simpleMethod, error) {
+		n, err := args.FieldInt64("n")
+		if err != nil {
+			return nil, err
+		}
+		return func(v any, ctx FunctionContext) (any, error) {
+			arr, ok := v.([]any)
+			if !ok {
+				return nil, value.NewTypeError(v, value.TArray)
+			}
+			rotated := make([]any, 0, len(arr))
+			if len(arr) == 0 {
+				return rotated, nil
+			}
+			offset := int(n % int64(len(arr)))
+			if offset < 0 {
+				offset += len(arr)
+			}
+			rotated = append(rotated, arr[offset:]...)
+			return append(rotated, arr[:offset]...), nil
+		}, nil
+	},
+)
+
+//------------------------------------------------------------------------------
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"stride", "",
+	).InCategory(
+		MethodCategoryObjectAndArray,
+		"Returns a new array containing every `n`th element of an array, starting from the element at index `offset`, which is useful for downsampling dense series. An empty array is returned if the offset is beyond the end of the array.",
+		NewExampleSpec("",
+			`root.samples = this.readings.stride(3)`,
+			`{"readings":[10,11,12,13,14,15,16]}`,
+			`{"samples":[10,13,16]}`,
+		),
+		NewExampleSpec("",
+			`root.odd = this.values.stride(2, 1)`,
+			`{"values":["a","b","c","d","e"]}`,
+			`{"odd":["b","d"]}`,
+		),
+	).
+		Param(ParamInt64("n", "The distance between selected elements, which must be greater than zero.")).
+		Param(ParamInt64("offset", "The index of the first element to select, which must not be negative.").Default(0)),
+	func(args *ParsedParams) (simpleMethod, error) {
+		n, err := args.FieldInt64("n")
+		if err != nil {
+			return nil, err
+		}
+		if n <= 0 {
+			return nil, fmt.Errorf("n must be greater than zero, got %v", n)
+		}
+		offset, err := args.FieldInt64("offset")
+		if err != nil {
+			return nil, err
+		}
+		if offset < 0 {
+			return nil, fmt.Errorf("offset must not be negative, got %v", offset)
+		}
+		return func(v any, ctx FunctionContext) (any, error) {
+			arr, ok := v.([]any)
+			if !ok {
+				return nil, value.NewTypeError(v, value.TArray)
+			}
+			res := []any{}
+			for i := offset; i < int64(len(arr)); i += n {
+				res = append(res, arr[i])
+			}
+			return res, nil
+		}, nil
+	},
+)
+
+//------------------------------------------------------------------------------
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"fill", "",
+	).InCategory(
+		MethodCategoryObjectAndArray,
+		"Returns a new array with the `null` elements of an array replaced, which is a common step when cleaning time series data. The `direction` determines the replacement of each `null` element, where `forward` uses the closest preceding non-null element, `backward` uses the closest following non-null element and `value` uses the `value` argument. When filling `forward` or `backward` the `value` argument is used for `null` elements that have no preceding or following non-null element respectively, which otherwise remain `null`.",
+		NewExampleSpec("",
+			`root.forward = this.readings.fill("forward")
+root.backward = this.readings.fill("backward", 0)
+root.constant = this.readings.fill("value", -1)`,
+			`{"readings":[null,1,null,null,4,null]}`,
+			`{"backward":[1,1,4,4,4,0],"constant":[-1,1,-1,-1,4,-1],"forward":[null,1,1,1,4,4]}`,
+		),
+	).
+		Param(ParamString("direction", "The direction to fill from, one of `forward`, `backward` or `value`.")).
+		Param(ParamAny("value", "A value to replace `null` elements with when the direction is `value`, or when there is no non-null element to fill from.").Optional()),
+	func(args *ParsedParams) (simpleMethod, error) {
+		direction, err := args.FieldString("direction")
+		if err != nil {
+			return nil, err
+		}
+		switch direction {
+		case "forward", "backward", "value":
+		default:
+			return nil, fmt.Errorf("unrecognised direction: %v", direction)
+		}
+		fillValue, err := args.Field("value")
+		if err != nil {
+			return nil, err
+		}
+		return func(v any, ctx FunctionContext) (any, error) {
+			arr, ok := v.([]any)
+			if !ok {
+				return nil, value.NewTypeError(v, value.TArray)
+			}
+			filled := make([]any, len(arr))
+			switch direction {
+			case "forward":
+				last := fillValue
+				for i, ele := range arr {
+					if ele != nil {
+						last = ele
+					}
+					filled[i] = last
+				}
+			case "backward":
+				next := fillValue
+				for i := len(arr) - 1; i >= 0; i-- {
+					if arr[i] != nil {
+						next = arr[i]
+					}
+					filled[i] = next
+				}
+			default:
+				for i, ele := range arr {
+					if ele == nil {
+						ele = fillValue
+					}
+					filled[i] = ele
+				}
+			}
+			return filled, nil
+		}, nil
+	},
+)
+
+//------------------------------------------------------------------------------
+
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"exists",
@@ -303,6 +648,87 @@ var _ = registerSimpleMethod(
 
 //------------------------------------------------------------------------------
 
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"type_at", "",
+	).InCategory(
+		MethodCategoryObjectAndArray,
+		"Returns the type of a value, identified via a xref:configuration:field_paths.adoc[dot path], as a string. The type is one of the values returned by <<type, `type`>>, or `missing` if the path does not exist. An empty path refers to the value itself. Unlike <<get, `get`>> followed by <<type, `type`>> this makes it possible to distinguish a field that is missing from a field that is set to `null`, and as the path is a parameter it can be computed at runtime.",
+		NewExampleSpec("",
+			`root.types = this.paths.map_each(p -> this.doc.type_at(p))`,
+			`{"doc":{"a":{"b":"foo","c":null},"d":[1,2]},"paths":["a","a.b","a.c","a.e","d","d.0","d.5"]}`,
+			`{"types":["object","string","null","missing","array","number","missing"]}`,
+		),
+	).Param(ParamString("path", "A xref:configuration:field_paths.adoc[dot path] to a field.")),
+	func(args *ParsedParams) (simpleMethod, error) {
+		pathStr, err := args.FieldString("path")
+		if err != nil {
+			return nil, err
+		}
+		var path []string
+		if pathStr != "" {
+			path = gabs.DotPathToSlice(pathStr)
+		}
+		return func(v any, ctx FunctionContext) (any, error) {
+			c := gabs.Wrap(v)
+			if !c.Exists(path...) {
+				return "missing", nil
+			}
+			return string(value.ITypeOf(c.S(path...).Data())), nil
+		}, nil
+	},
+)
+
+//------------------------------------------------------------------------------
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"first_existing", "",
+	).InCategory(
+		MethodCategoryObjectAndArray,
+		"Takes an array of xref:configuration:field_paths.adoc[dot paths] and returns the value of the first path that exists within the target and is not `null`, or `null` if there isn't one. This is useful for documents where a value may live under one of several fields, and unlike chaining queries with the `|` operator the paths can be computed at runtime.",
+		NewExampleSpec("",
+			`root.user_id = this.first_existing(["user_id", "userId", "uid"])`,
+			`{"userId":"u1","uid":"u2"}`,
+			`{"user_id":"u1"}`,
+			`{"user_id":null,"uid":"u2"}`,
+			`{"user_id":"u2"}`,
+			`{"name":"foo"}`,
+			`{"user_id":null}`,
+		),
+		NewExampleSpec("",
+			`root.region = this.doc.first_existing(this.region_paths)`,
+			`{"doc":{"meta":{"region":"eu"}},"region_paths":["region","meta.region"]}`,
+			`{"region":"eu"}`,
+		),
+	).Param(ParamArray("paths", "An array of xref:configuration:field_paths.adoc[dot paths] to check in order.")),
+	func(args *ParsedParams) (simpleMethod, error) {
+		pathsArr, err := args.FieldArray("paths")
+		if err != nil {
+			return nil, err
+		}
+		paths := make([][]string, len(pathsArr))
+		for i, p := range pathsArr {
+			pathStr, err := value.IGetString(p)
+			if err != nil {
+				return nil, fmt.Errorf("paths index %v: %w", i, err)
+			}
+			paths[i] = gabs.DotPathToSlice(pathStr)
+		}
+		return func(v any, ctx FunctionContext) (any, error) {
+			c := gabs.Wrap(v)
+			for _, p := range paths {
+				if res := c.S(p...).Data(); res != nil {
+					return res, nil
+				}
+			}
+			return nil, nil
+		}, nil
+	},
+)
+
+//------------------------------------------------------------------------------
+
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"explode", "",
@@ -604,6 +1030,52 @@ var _ = registerSimpleMethod(
 
 //------------------------------------------------------------------------------
 
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"find_first",
+		"Returns the first element of an array where the provided query resolves to a boolean `true`, or `null` if there are no matches. Elements after the first match are not tested. In order to obtain the index of the first match use <<find_by, `find_by`>>.",
+	).InCategory(
+		MethodCategoryObjectAndArray, "",
+		NewExampleSpec("",
+			`root.first_adult = this.patrons.find_first(patron -> patron.age >= 18)`,
+			`{"patrons":[{"id":"1","age":12},{"id":"2","age":23},{"id":"3","age":45}]}`,
+			`{"first_adult":{"age":23,"id":"2"}}`,
+			`{"patrons":[{"id":"1","age":12}]}`,
+			`{"first_adult":null}`,
+		),
+	).Param(ParamQuery("query", "A query to execute for each element.", false)),
+	func(args *ParsedParams) (simpleMethod, error) {
+		queryFn, err := args.FieldQuery("query")
+		if err != nil {
+			return nil, err
+		}
+
+		return func(v any, ctx FunctionContext) (any, error) {
+			array, ok := v.([]any)
+			if !ok {
+				return nil, value.NewTypeError(v, value.TArray)
+			}
+
+			for i, elem := range array {
+				iIsMatch, err := queryFn.Exec(ctx.WithValue(elem))
+				if err != nil {
+					return nil, fmt.Errorf("query returned an error for index %v: %w", i, err)
+				}
+				isMatch, ok := iIsMatch.(bool)
+				if !ok {
+					return nil, fmt.Errorf("query returned a non-boolean value for index %v: %w", i, value.NewTypeError(iIsMatch, value.TBool))
+				}
+				if isMatch {
+					return elem, nil
+				}
+			}
+			return nil, nil
+		}, nil
+	},
+)
+
+//------------------------------------------------------------------------------
+
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"flatten",
@@ -641,7 +1113,7 @@ var _ = registerSimpleMethod(
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"fold",
-		"Takes two arguments: an initial value, and a mapping query. For each element of an array the mapping context is an object with two fields `tally` and `value`, where `tally` contains the current accumulated value and `value` is the value of the current element. The mapping must return the result of adding the value to the tally.\n\nThe first argument is the value that `tally` will have on the first call.",
+		"Takes two arguments: an initial value, and a mapping query. For each element of an array the mapping context is an object with the fields `tally`, `value` and `index`, where `tally` contains the current accumulated value, `value` is the value of the current element and `index` is the index of the current element. The accumulated value is also available under the field `acc`, which is an alias of `tally`. The mapping must return the result of adding the value to the tally.\n\nThe first argument is the value that `tally` will have on the first call.",
 	).InCategory(
 		MethodCategoryObjectAndArray, "",
 		NewExampleSpec(``,
@@ -659,9 +1131,14 @@ var _ = registerSimpleMethod(
 			`{"fruits":[{"apple":5},{"banana":3},{"orange":8}]}`,
 			`{"smoothie":{"apple":5,"banana":3,"orange":8}}`,
 		),
+		NewExampleSpec(`The index of each element can be used to weight values by their position:`,
+			`root.weighted = this.values.fold(0, item -> item.acc + (item.value * item.index))`,
+			`{"values":[5,3,2]}`,
+			`{"weighted":7}`,
+		),
 	).
 		Param(ParamAny("initial", "The initial value to start the fold with. For example, an empty object `{}`, a zero count `0`, or an empty string `\"\"`.")).
-		Param(ParamQuery("query", "A query to apply for each element. The query is provided an object with two fields; `tally` containing the current tally, and `value` containing the value of the current element. The query should result in a new tally to be passed to the next element query.", false)),
+		Param(ParamQuery("query", "A query to apply for each element. The query is provided an object with the fields; `tally` (or its alias `acc`) containing the current tally, `value` containing the value of the current element, and `index` containing the index of the current element. The query should result in a new tally to be passed to the next element query.", false)),
 	func(args *ParsedParams) (simpleMethod, error) {
 		foldTallyStart, err := args.Field("initial")
 		if err != nil {
@@ -678,10 +1155,12 @@ var _ = registerSimpleMethod(
 			}
 
 			tally := value.IClone(foldTallyStart)
-			for _, v := range resArray {
+			for i, v := range resArray {
 				newV, mapErr := foldFn.Exec(ctx.WithValue(map[string]any{
 					"tally": tally,
+					"acc":   tally,
 					"value": v,
+					"index": int64(i),
 				}))
 				if mapErr != nil {
 					return nil, mapErr
@@ -695,6 +1174,66 @@ var _ = registerSimpleMethod(
 
 //------------------------------------------------------------------------------
 
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"scan",
+		"Takes two arguments: an initial value, and a mapping query, and threads an accumulated value through each element of an array in the same way as <<fold, `fold`>>. However, instead of returning only the final accumulated value an array of every intermediate accumulated value is returned, where each element is the result of the query for the element of the array at the same index.\n\nFor each element of an array the mapping context is an object with the fields `tally`, `value` and `index`, where `tally` contains the current accumulated value, `value` is the value of the current element and `index` is the index of the current element. The accumulated value is also available under the field `acc`, which is an alias of `tally`.",
+	).InCategory(
+		MethodCategoryObjectAndArray, "",
+		NewExampleSpec(`Calculate a running total:`,
+			`root.totals = this.values.scan(0, item -> item.acc + item.value)`,
+			`{"values":[3,8,11]}`,
+			`{"totals":[3,11,22]}`,
+		),
+		NewExampleSpec(`Track the state of a sequence of events:`,
+			`root.states = this.events.scan("closed", item -> match item.value {
+  "open" => "open"
+  "close" => "closed"
+  _ => item.acc
+})`,
+			`{"events":["open","ping","close","ping"]}`,
+			`{"states":["open","open","closed","closed"]}`,
+		),
+	).
+		Param(ParamAny("initial", "The initial value to start the scan with. For example, an empty object `{}`, a zero count `0`, or an empty string `\"\"`.")).
+		Param(ParamQuery("query", "A query to apply for each element. The query is provided an object with the fields; `tally` (or its alias `acc`) containing the current tally, `value` containing the value of the current element, and `index` containing the index of the current element. The query should result in a new tally to be passed to the next element query.", false)),
+	func(args *ParsedParams) (simpleMethod, error) {
+		scanTallyStart, err := args.Field("initial")
+		if err != nil {
+			return nil, err
+		}
+		scanFn, err := args.FieldQuery("query")
+		if err != nil {
+			return nil, err
+		}
+		return func(res any, ctx FunctionContext) (any, error) {
+			resArray, ok := res.([]any)
+			if !ok {
+				return nil, value.NewTypeError(res, value.TArray)
+			}
+
+			tally := value.IClone(scanTallyStart)
+			results := make([]any, 0, len(resArray))
+			for i, v := range resArray {
+				newV, mapErr := scanFn.Exec(ctx.WithValue(map[string]any{
+					"tally": tally,
+					"acc":   tally,
+					"value": v,
+					"index": int64(i),
+				}))
+				if mapErr != nil {
+					return nil, mapErr
+				}
+				tally = newV
+				results = append(results, tally)
+			}
+			return results, nil
+		}, nil
+	},
+)
+
+//------------------------------------------------------------------------------
+
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"index",
@@ -1149,6 +1688,73 @@ func assigner(destination, source any) any {
 
 //------------------------------------------------------------------------------
 
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"with_defaults", "",
+	).InCategory(
+		MethodCategoryObjectAndArray,
+		"Recursively sets fields from an object of defaults where they are missing from the target object, the opposite of <<assign, `assign`>> in that values of the target object are never overwritten. Nested objects present in both are merged in the same way. Fields of the target set to `null` are considered present unless the parameter `fill_null` is `true`.",
+		NewExampleSpec("",
+			`root = this.config.with_defaults({"host":"localhost","port":8080,"tls":{"enabled":false,"verify":true}})`,
+			`{"config":{"port":9000,"tls":{"enabled":true}}}`,
+			`{"host":"localhost","port":9000,"tls":{"enabled":true,"verify":true}}`,
+		),
+		NewExampleSpec("",
+			`root.a = this.with_defaults({"name":"anon","age":0})
+root.b = this.with_defaults(defaults: {"name":"anon","age":0}, fill_null: true)`,
+			`{"name":null}`,
+			`{"a":{"age":0,"name":null},"b":{"age":0,"name":"anon"}}`,
+		),
+	).
+		Param(ParamObject("defaults", "An object of default values.")).
+		Param(ParamBool("fill_null", "Whether fields of the target set to `null` should be replaced with their default.").Default(false)),
+	func(args *ParsedParams) (simpleMethod, error) {
+		defaultsV, err := args.Field("defaults")
+		if err != nil {
+			return nil, err
+		}
+		defaults, ok := defaultsV.(map[string]any)
+		if !ok {
+			return nil, value.NewTypeError(defaultsV, value.TObject)
+		}
+		fillNull, err := args.FieldBool("fill_null")
+		if err != nil {
+			return nil, err
+		}
+		return func(v any, ctx FunctionContext) (any, error) {
+			obj, ok := v.(map[string]any)
+			if !ok {
+				return nil, value.NewTypeError(v, value.TObject)
+			}
+			return withDefaults(obj, defaults, fillNull), nil
+		}, nil
+	},
+)
+
+// withDefaults returns a copy of an object with the fields of defaults added
+// wherever they are missing, without modifying either argument.
+func withDefaults(obj, defaults map[string]any, fillNull bool) map[string]any {
+	res := make(map[string]any, len(obj))
+	for k, v := range obj {
+		res[k] = v
+	}
+	for k, dv := range defaults {
+		v, exists := res[k]
+		if !exists || (fillNull && v == nil) {
+			res[k] = value.IClone(dv)
+			continue
+		}
+		vObj, isObj := v.(map[string]any)
+		dvObj, isDefaultObj := dv.(map[string]any)
+		if isObj && isDefaultObj {
+			res[k] = withDefaults(vObj, dvObj, fillNull)
+		}
+	}
+	return res
+}
+
+//------------------------------------------------------------------------------
+
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"not_empty", "",
@@ -1392,6 +1998,108 @@ func sortByMethod(target Function, args *ParsedParams) (Function, error) {
 
 //------------------------------------------------------------------------------
 
+// compareSortable compares two values following the ordering of the sort
+// methods, where numbers are compared numerically and strings lexically, and
+// returns a negative number when left is less than right, zero when they're
+// equal and a positive number otherwise.
+func compareSortable(left, right any) (int, error) {
+	switch left.(type) {
+	case float64, int, int64, uint64, json.Number:
+		lhs, err := value.IGetNumber(left)
+		if err != nil {
+			return 0, err
+		}
+		rhs, err := value.IGetNumber(right)
+		if err != nil {
+			return 0, err
+		}
+		switch {
+		case lhs < rhs:
+			return -1, nil
+		case lhs > rhs:
+			return 1, nil
+		}
+		return 0, nil
+	case string, []byte:
+		lhs, err := value.IGetString(left)
+		if err != nil {
+			return 0, err
+		}
+		rhs, err := value.IGetString(right)
+		if err != nil {
+			return 0, err
+		}
+		return strings.Compare(lhs, rhs), nil
+	}
+	return 0, value.NewTypeError(left, value.TNumber, value.TString)
+}
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"is_sorted", "",
+	).InCategory(
+		MethodCategoryObjectAndArray,
+		"Checks whether the elements of an array are sorted in non-decreasing order, or non-increasing order when `descending` is `true`, and returns a boolean result. Elements are compared in the same way as <<sort, `sort`>>, where numbers are compared numerically and strings lexically, and an error is returned when adjacent elements are of mismatched or unsupported types. Checking stops at the first pair of elements that are out of order. Empty arrays and arrays with a single element are sorted.",
+		NewExampleSpec("",
+			`root.ordered = this.values.is_sorted()`,
+			`{"values":[1,2,2,5]}`,
+			`{"ordered":true}`,
+			`{"values":[1,3,2]}`,
+			`{"ordered":false}`,
+		),
+		NewExampleSpec("An optional query can be provided in order to compare elements by a value derived from each element.",
+			`root.ordered = this.events.is_sorted(e -> e.ts)`,
+			`{"events":[{"ts":"2024-01-01T00:00:00Z"},{"ts":"2024-01-02T00:00:00Z"}]}`,
+			`{"ordered":true}`,
+		),
+		NewExampleSpec("",
+			`root.ordered = this.values.is_sorted(descending: true)`,
+			`{"values":[3,2,1]}`,
+			`{"ordered":true}`,
+		),
+	).
+		Param(ParamQuery("query", "An optional query to apply to each element that yields the value used for comparisons.", false).Optional()).
+		Param(ParamBool("descending", "Whether to check that elements are in non-increasing order.").Default(false)),
+	func(args *ParsedParams) (simpleMethod, error) {
+		keyFn, err := args.FieldOptionalQuery("query")
+		if err != nil {
+			return nil, err
+		}
+		descending, err := args.FieldBool("descending")
+		if err != nil {
+			return nil, err
+		}
+		return func(v any, ctx FunctionContext) (any, error) {
+			arr, ok := v.([]any)
+			if !ok {
+				return nil, value.NewTypeError(v, value.TArray)
+			}
+			var prev any
+			for i, key := range arr {
+				if keyFn != nil {
+					var err error
+					if key, err = keyFn.Exec(ctx.WithValue(key)); err != nil {
+						return nil, fmt.Errorf("element %v: %w", i, err)
+					}
+				}
+				if i > 0 {
+					c, err := compareSortable(prev, key)
+					if err != nil {
+						return nil, fmt.Errorf("element %v: %w", i, err)
+					}
+					if (!descending && c > 0) || (descending && c < 0) {
+						return false, nil
+					}
+				}
+				prev = key
+			}
+			return true, nil
+		}, nil
+	},
+)
+
+//------------------------------------------------------------------------------
+
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"slice", "",
@@ -1645,6 +2353,158 @@ func uniqueMethod(args *ParsedParams) (simpleMethod, error) {
 
 //------------------------------------------------------------------------------
 
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"dedupe_consecutive", "",
+	).InCategory(
+		MethodCategoryObjectAndArray,
+		"Removes elements from an array that are equal to the element kept before them, collapsing each run of adjacent duplicates into its first element. Unlike <<unique, `unique`>> only adjacent duplicates are removed, which makes it possible to detect changes of state over ordered data. Elements of any type can be compared, and numerical comparisons are made irrespective of the representation type (float versus integer).",
+		NewExampleSpec("",
+			`root.changes = this.readings.dedupe_consecutive()`,
+			`{"readings":["ok","ok","warn","warn","ok","ok"]}`,
+			`{"changes":["ok","warn","ok"]}`,
+		),
+		NewExampleSpec("A query can be provided in order to yield the value of each element that is compared, the first element of each run is kept:",
+			`root.changes = this.events.dedupe_consecutive(event -> event.state)`,
+			`{"events":[{"state":"up","ts":1},{"state":"up","ts":2},{"state":"down","ts":3},{"state":"up","ts":4}]}`,
+			`{"changes":[{"state":"up","ts":1},{"state":"down","ts":3},{"state":"up","ts":4}]}`,
+		),
+	).
+		Param(ParamQuery(
+			"emit",
+			"An optional query that can be used in order to yield a value for each element to compare.",
+			false,
+		).Optional()),
+	func(args *ParsedParams) (simpleMethod, error) {
+		emitFn, err := args.FieldOptionalQuery("emit")
+		if err != nil {
+			return nil, err
+		}
+		return func(v any, ctx FunctionContext) (any, error) {
+			slice, ok := v.([]any)
+			if !ok {
+				return nil, value.NewTypeError(v, value.TArray)
+			}
+
+			var lastKey any
+			dedupedSlice := make([]any, 0, len(slice))
+			for i, v := range slice {
+				key := v
+				if emitFn != nil {
+					var err error
+					if key, err = emitFn.Exec(ctx.WithValue(v)); err != nil {
+						return nil, fmt.Errorf("index %v: %w", i, err)
+					}
+				}
+				if i > 0 && value.ICompare(lastKey, key) {
+					continue
+				}
+				lastKey = key
+				dedupedSlice = append(dedupedSlice, v)
+			}
+			return dedupedSlice, nil
+		}, nil
+	},
+)
+
+//------------------------------------------------------------------------------
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"rle_encode", "",
+	).InCategory(
+		MethodCategoryObjectAndArray,
+		"Run-length encodes an array by collapsing each run of adjacent equal elements into an object containing the fields `value`, the value of the run, and `count`, the number of elements in the run. Elements are compared in the same way as <<dedupe_consecutive, `dedupe_consecutive`>>, where elements of any type can be compared and numerical comparisons are made irrespective of the representation type (float versus integer). The first element of each run is used as its value. The original array can be restored with <<rle_decode, `rle_decode`>>.",
+		NewExampleSpec("",
+			`root.statuses = this.statuses.rle_encode()`,
+			`{"statuses":["ok","ok","ok","fail","ok","ok"]}`,
+			`{"statuses":[{"count":3,"value":"ok"},{"count":1,"value":"fail"},{"count":2,"value":"ok"}]}`,
+		),
+	),
+	func(*ParsedParams) (simpleMethod, error) {
+		return func(v any, ctx FunctionContext) (any, error) {
+			slice, ok := v.([]any)
+			if !ok {
+				return nil, value.NewTypeError(v, value.TArray)
+			}
+
+			runs := []any{}
+			var current map[string]any
+			for _, v := range slice {
+				if current != nil && value.ICompare(current["value"], v) {
+					current["count"] = current["count"].(int64) + 1
+					continue
+				}
+				current = map[string]any{
+					"value": v,
+					"count": int64(1),
+				}
+				runs = append(runs, current)
+			}
+			return runs, nil
+		}, nil
+	},
+)
+
+var _ = registerSimpleMethod(
+	NewMethodSpec(
+		"rle_decode", "",
+	).InCategory(
+		MethodCategoryObjectAndArray,
+		"Expands an array of run-length encoded objects, as produced by <<rle_encode, `rle_encode`>>, back into a flat array. Each object must contain the fields `value`, the value of the run, and `count`, a non-negative number of times the value is repeated. An error is returned if the total number of elements would exceed `max_size`.",
+		NewExampleSpec("",
+			`root.statuses = this.statuses.rle_decode()`,
+			`{"statuses":[{"count":3,"value":"ok"},{"count":1,"value":"fail"},{"count":2,"value":"ok"}]}`,
+			`{"statuses":["ok","ok","ok","fail","ok","ok"]}`,
+		),
+		NewExampleSpec("",
+			`root.error = this.statuses.rle_decode(max_size: 3).catch(err -> err)`,
+			`{"statuses":[{"count":3,"value":"ok"},{"count":1000000,"value":"fail"}]}`,
+			`{"error":"field `+"`this.statuses`"+`: index 1: decoded array would exceed max_size of 3 elements"}`,
+		),
+	).
+		Param(ParamInt64("max_size", "The maximum number of elements allowed in the result.").Default(100000)),
+	func(args *ParsedParams) (simpleMethod, error) {
+		maxSize, err := args.FieldInt64("max_size")
+		if err != nil {
+			return nil, err
+		}
+		if maxSize < 0 {
+			return nil, fmt.Errorf("max_size must not be negative, got %v", maxSize)
+		}
+		return func(v any, ctx FunctionContext) (any, error) {
+			slice, ok := v.([]any)
+			if !ok {
+				return nil, value.NewTypeError(v, value.TArray)
+			}
+
+			expanded := []any{}
+			for i, ele := range slice {
+				run, ok := ele.(map[string]any)
+				if !ok {
+					return nil, fmt.Errorf("index %v: %w", i, value.NewTypeError(ele, value.TObject))
+				}
+				count, err := value.IGetInt(run["count"])
+				if err != nil {
+					return nil, fmt.Errorf("index %v: field count: %w", i, err)
+				}
+				if count < 0 {
+					return nil, fmt.Errorf("index %v: field count: must not be negative, got %v", i, count)
+				}
+				if count > maxSize-int64(len(expanded)) {
+					return nil, fmt.Errorf("index %v: decoded array would exceed max_size of %v elements", i, maxSize)
+				}
+				for j := int64(0); j < count; j++ {
+					expanded = append(expanded, value.IClone(run["value"]))
+				}
+			}
+			return expanded, nil
+		}, nil
+	},
+)
+
+//------------------------------------------------------------------------------
+
 var _ = registerSimpleMethod(
 	NewMethodSpec(
 		"values", "",
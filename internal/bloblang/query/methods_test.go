@@ -3,9 +3,13 @@
 package query
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/Jeffail/gabs/v2"
 	"github.com/stretchr/testify/assert"
@@ -142,6 +146,114 @@ func TestMethods(t *testing.T) {
     foo: bar
 `),
 		},
+		"check format_toml": {
+			input: methods(
+				jsonFn(`{"doc":{"foo":"bar","nums":[1,2.5]}}`),
+				method("format_toml"),
+			),
+			output: []byte(`[doc]
+foo = 'bar'
+nums = [1.0, 2.5]
+`),
+		},
+		"check format_toml not object": {
+			input: methods(
+				jsonFn(`["foo"]`),
+				method("format_toml"),
+			),
+			err: "expected object value, got array from array literal",
+		},
+		"check parse_toml": {
+			input: methods(
+				literalFn("a = 1979-05-27\nb = 07:32:00\nc = 1979-05-27T07:32:00\nd = 1979-05-27T07:32:00Z\n\n[[e]]\nf = 1.5\n"),
+				method("parse_toml"),
+			),
+			output: map[string]any{
+				"a": "1979-05-27",
+				"b": "07:32:00",
+				"c": "1979-05-27T07:32:00",
+				"d": time.Date(1979, 5, 27, 7, 32, 0, 0, time.UTC),
+				"e": []any{
+					map[string]any{"f": 1.5},
+				},
+			},
+		},
+		"check parse_toml error": {
+			input: methods(
+				literalFn("a = "),
+				method("parse_toml"),
+			),
+			err: "string literal: failed to parse value as TOML: toml: expected value, not eof",
+		},
+		"check format_csv values": {
+			input: methods(
+				jsonFn(`[{"a":"foo, \"bar\"","b":null,"c":{"d":1}},{"a":true,"e":5}]`),
+				method("format_csv"),
+			),
+			output: []byte(`a,b,c,e
+"foo, ""bar""",,"{""d"":1}",
+true,,,5
+`),
+		},
+		"check format_csv arrays no columns": {
+			input: methods(
+				jsonFn(`[["a","b"],["c"]]`),
+				method("format_csv"),
+			),
+			output: []byte("a,b\nc\n"),
+		},
+		"check format_csv mixed rows": {
+			input: methods(
+				jsonFn(`[{"a":"b"},["c"]]`),
+				method("format_csv"),
+			),
+			err: "array literal: index 1: rows must be either all objects or all arrays",
+		},
+		"check format_csv bad row": {
+			input: methods(
+				jsonFn(`["a"]`),
+				method("format_csv"),
+			),
+			err: "array literal: index 0: expected object or array value, got string (\"a\")",
+		},
+		"check parse_ini": {
+			input: methods(
+				literalFn("# top\r\na = 1\r\n[ s1 ]\r\nb = 'x = y'\r\nb = \"z\"\r\nc =\r\n[s2]\r\nd=e\r\n[s1]\r\nf = g"),
+				method("parse_ini"),
+			),
+			output: map[string]any{
+				"a": "1",
+				"s1": map[string]any{
+					"b": "z",
+					"c": "",
+					"f": "g",
+				},
+				"s2": map[string]any{
+					"d": "e",
+				},
+			},
+		},
+		"check parse_ini bad line": {
+			input: methods(
+				literalFn("[s1]\nfoo"),
+				method("parse_ini"),
+			),
+			err: "string literal: line 2: expected a key value pair or section header",
+		},
+		"check parse_ini unclosed section": {
+			input: methods(
+				literalFn("[s1"),
+				method("parse_ini"),
+			),
+			err: "string literal: line 1: section header is missing a closing bracket",
+		},
+		"check parse_ini section key conflict": {
+			input: methods(
+				literalFn("s1 = foo\n[s1]"),
+				method("parse_ini"),
+			),
+			err: "string literal: line 2: section s1 conflicts with a key of the same name",
+		},
 		"check parse csv 1": {
 			input: methods(
 				literalFn("foo,bar,baz\n1,2,3\n4,5,6"),
@@ -299,6 +411,87 @@ func TestMethods(t *testing.T) {
 			),
 			err: "expected array value, got string from string literal (\"foo\")",
 		},
+		"check dedupe_consecutive": {
+			input: methods(
+				jsonFn(`[3.0,3,5,3,{"a":1},{"a":1.0},[1],[1],null,null]`),
+				method("dedupe_consecutive"),
+			),
+			output: []any{
+				float64(3), float64(5), float64(3),
+				map[string]any{"a": float64(1)},
+				[]any{float64(1)},
+				nil,
+			},
+		},
+		"check dedupe_consecutive empty": {
+			input: methods(
+				jsonFn(`[]`),
+				method("dedupe_consecutive"),
+			),
+			output: []any{},
+		},
+		"check dedupe_consecutive custom err": {
+			input: methods(
+				jsonFn(`[{"v":"a"},{"v":"b"}]`),
+				method("dedupe_consecutive", methods(
+					NewFieldFunction("nope"),
+					method("number"),
+				)),
+			),
+			err: "array literal: index 0: expected number value, got null from field `this.nope`",
+		},
+		"check rle_encode": {
+			input: methods(
+				jsonFn(`[1,1.0,2,"2","2",null]`),
+				method("rle_encode"),
+			),
+			output: []any{
+				map[string]any{"value": float64(1), "count": int64(2)},
+				map[string]any{"value": float64(2), "count": int64(1)},
+				map[string]any{"value": "2", "count": int64(2)},
+				map[string]any{"value": nil, "count": int64(1)},
+			},
+		},
+		"check rle_decode": {
+			input: methods(
+				jsonFn(`[1,1,2,"a",{"b":1},{"b":1}]`),
+				method("rle_encode"),
+				method("rle_decode"),
+			),
+			output: []any{
+				float64(1), float64(1), float64(2), "a",
+				map[string]any{"b": float64(1)},
+				map[string]any{"b": float64(1)},
+			},
+		},
+		"check rle_decode bad count": {
+			input: methods(
+				jsonFn(`[{"value":"a","count":1},{"value":"b","count":-1}]`),
+				method("rle_decode"),
+			),
+			err: "array literal: index 1: field count: must not be negative, got -1",
+		},
+		"check rle_decode exceeds max size": {
+			input: methods(
+				jsonFn(`[{"value":"a","count":2},{"value":"b","count":1000000}]`),
+				method("rle_decode"),
+			),
+			err: "array literal: index 1: decoded array would exceed max_size of 100000 elements",
+		},
+		"check rle_decode custom max size": {
+			input: methods(
+				jsonFn(`[{"value":"a","count":2},{"value":"b","count":1}]`),
+				method("rle_decode", int64(3)),
+			),
+			output: []any{"a", "a", "b"},
+		},
+		"check rle_decode not object": {
+			input: methods(
+				jsonFn(`["a"]`),
+				method("rle_decode"),
+			),
+			err: "array literal: index 0: expected object value, got string (\"a\")",
+		},
 		"check unique": {
 			input: methods(
 				jsonFn(`[3.0,5,3,4,5.1,5]`),
@@ -928,6 +1121,29 @@ func TestMethods(t *testing.T) {
 			),
 			output: `<<???>>`,
 		},
+		"check base64_lenient decode padded": {
+			input: methods(
+				literalFn("aGVsbG8gd29ybGQ="),
+				method("decode", "base64_lenient"),
+				method("string"),
+			),
+			output: `hello world`,
+		},
+		"check base64_lenient decode wrapped url alphabet": {
+			input: methods(
+				literalFn("PDw_\r\n Pz8-\r\n Pg="),
+				method("decode", "base64_lenient"),
+				method("string"),
+			),
+			output: `<<???>>`,
+		},
+		"check base64_lenient decode invalid": {
+			input: methods(
+				literalFn("aGVsbG8*"),
+				method("decode", "base64_lenient"),
+			),
+			err: "string literal: illegal base64 data at input byte 7",
+		},
 		"check z85 encode": {
 			input: methods(
 				literalFn("hello world!"),
@@ -1318,6 +1534,16 @@ func TestMethods(t *testing.T) {
 				"foo", []any{"foo"},
 			},
 		},
+		"check enumerated tuples": {
+			input: methods(
+				jsonFn(`["foo","bar"]`),
+				method("enumerated", true),
+			),
+			output: []any{
+				[]any{int64(0), "foo"},
+				[]any{int64(1), "bar"},
+			},
+		},
 		"check enumerated": {
 			input: methods(
 				jsonFn(`["foo","bar","baz"]`),
@@ -1601,6 +1827,19 @@ func TestMethods(t *testing.T) {
 			},
 			output: "foobar",
 		},
+		"check fold acc and index": {
+			input: methods(
+				jsonFn(`["foo","bar","baz"]`),
+				method("fold", "", methods(
+					literalFn("%v%v:%v,"),
+					method("format", NewFieldFunction("acc"), NewFieldFunction("index"), NewFieldFunction("value")),
+				)),
+			),
+			messages: []easyMsg{
+				{content: `{}`},
+			},
+			output: "0:foo,1:bar,2:baz,",
+		},
 		"check fold exec err 2": {
 			input: methods(
 				jsonFn(`["foo","bar"]`),
@@ -1614,6 +1853,133 @@ func TestMethods(t *testing.T) {
 			},
 			err: "expected number value, got null from field `this.does.not.exist`",
 		},
+		"check scan": {
+			input: methods(
+				jsonFn(`[3,5,2]`),
+				method("scan", 0.0, arithmetic(
+					NewFieldFunction("tally"),
+					NewFieldFunction("value"),
+					ArithmeticAdd,
+				)),
+			),
+			messages: []easyMsg{
+				{content: `{}`},
+			},
+			output: []any{float64(3), float64(8), float64(10)},
+		},
+		"check scan empty": {
+			input: methods(
+				jsonFn(`[]`),
+				method("scan", 0.0, NewFieldFunction("value")),
+			),
+			messages: []easyMsg{
+				{content: `{}`},
+			},
+			output: []any{},
+		},
+		"check scan exec err": {
+			input: methods(
+				jsonFn(`["foo","bar"]`),
+				method("scan", 0.0, methods(
+					NewFieldFunction("does.not.exist"),
+					method("number"),
+				)),
+			),
+			messages: []easyMsg{
+				{content: `{}`},
+			},
+			err: "expected number value, got null from field `this.does.not.exist`",
+		},
+		"check find_first": {
+			input: methods(
+				jsonFn(`[1,5,8,10]`),
+				method("find_first", arithmetic(
+					NewFieldFunction(""),
+					NewLiteralFunction("", int64(4)),
+					ArithmeticGt,
+				)),
+			),
+			messages: []easyMsg{
+				{content: `{}`},
+			},
+			output: float64(5),
+		},
+		"check find_first short circuits": {
+			input: methods(
+				jsonFn(`[5,"nope"]`),
+				method("find_first", arithmetic(
+					NewFieldFunction(""),
+					NewLiteralFunction("", int64(4)),
+					ArithmeticGt,
+				)),
+			),
+			messages: []easyMsg{
+				{content: `{}`},
+			},
+			output: float64(5),
+		},
+		"check find_first no match": {
+			input: methods(
+				jsonFn(`[1,2]`),
+				method("find_first", arithmetic(
+					NewFieldFunction(""),
+					NewLiteralFunction("", int64(4)),
+					ArithmeticGt,
+				)),
+			),
+			messages: []easyMsg{
+				{content: `{}`},
+			},
+			output: nil,
+		},
+		"check find_first non bool": {
+			input: methods(
+				jsonFn(`[1,2]`),
+				method("find_first", NewFieldFunction("")),
+			),
+			messages: []easyMsg{
+				{content: `{}`},
+			},
+			err: "array literal: query returned a non-boolean value for index 0: expected bool value, got number (1)",
+		},
+		"check every short circuits": {
+			input: methods(
+				jsonFn(`[5,1,"nope"]`),
+				method("every", arithmetic(
+					NewFieldFunction(""),
+					NewLiteralFunction("", int64(4)),
+					ArithmeticGt,
+				)),
+			),
+			messages: []easyMsg{
+				{content: `{}`},
+			},
+			output: false,
+		},
+		"check every non bool": {
+			input: methods(
+				jsonFn(`[1,2]`),
+				method("every", NewFieldFunction("")),
+			),
+			messages: []easyMsg{
+				{content: `{}`},
+			},
+			err: "array literal: element 0: expected bool value, got number (1)",
+		},
+		"check some short circuits": {
+			input: methods(
+				jsonFn(`[1,5,"nope"]`),
+				method("some", arithmetic(
+					NewFieldFunction(""),
+					NewLiteralFunction("", int64(4)),
+					ArithmeticGt,
+				)),
+			),
+			messages: []easyMsg{
+				{content: `{}`},
+			},
+			output: true,
+		},
 		"check keys literal": {
 			input: methods(
 				jsonFn(`{"foo":1,"bar":2}`),
@@ -2023,6 +2389,407 @@ func TestMethods(t *testing.T) {
 			})),
 			output: []byte("ITAhello!ITA BOLDworld!BOLD"),
 		},
+		"check re_replace_all_many string": {
+			input: methods(literalFn("foo ADD 70 and ADD 30"), method("re_replace_all_many", []any{
+				"ADD ([0-9]+)", "+($1)",
+				`\+\(([0-9])`, "+[$1",
+			})),
+			output: "foo +[70) and +[30)",
+		},
+		"check re_replace_all_many bytes": {
+			input: methods(literalFn([]byte("a1b22c333")), method("re_replace_all_many", []any{
+				"[0-9]+", "#",
+				"#", "${0}${0}",
+			})),
+			output: "a##b##c##",
+		},
+		"check count": {
+			input:  methods(literalFn("cheese"), method("count", "e")),
+			output: int64(3),
+		},
+		"check count bytes non-overlapping": {
+			input:  methods(literalFn([]byte("aaaa")), method("count", "aa")),
+			output: int64(2),
+		},
+		"check count empty": {
+			input:  methods(literalFn("café"), method("count", "")),
+			output: int64(5),
+		},
+		"check split_n": {
+			input:  methods(literalFn("a,b,c,d"), method("split_n", ",", int64(3))),
+			output: []any{"a", "b", "c,d"},
+		},
+		"check split_n bytes": {
+			input:  methods(literalFn([]byte("a,b,c")), method("split_n", ",", int64(2))),
+			output: []any{[]byte("a"), []byte("b,c")},
+		},
+		"check split_n all": {
+			input:  methods(literalFn("a,b,c"), method("split_n", ",", int64(-1))),
+			output: []any{"a", "b", "c"},
+		},
+		"check split_n zero": {
+			input:  methods(literalFn("a,b,c"), method("split_n", ",", int64(0))),
+			output: []any{},
+		},
+		"check rsplit": {
+			input:  methods(literalFn("a.b.c"), method("rsplit", ".", int64(2))),
+			output: []any{"a.b", "c"},
+		},
+		"check rsplit bytes": {
+			input:  methods(literalFn([]byte("a::b::c")), method("rsplit", "::", int64(2))),
+			output: []any{[]byte("a::b"), []byte("c")},
+		},
+		"check rsplit fewer delimiters": {
+			input:  methods(literalFn("a.b"), method("rsplit", ".", int64(5))),
+			output: []any{"a", "b"},
+		},
+		"check rsplit no delimiter": {
+			input:  methods(literalFn("abc"), method("rsplit", ".", int64(2))),
+			output: []any{"abc"},
+		},
+		"check rsplit leading delimiter": {
+			input:  methods(literalFn(".a.b"), method("rsplit", ".", int64(3))),
+			output: []any{"", "a", "b"},
+		},
+		"check rsplit all": {
+			input:  methods(literalFn("a.b.c"), method("rsplit", ".", int64(-1))),
+			output: []any{"a", "b", "c"},
+		},
+		"check rsplit empty delimiter": {
+			input:  methods(literalFn("abcé"), method("rsplit", "", int64(2))),
+			output: []any{"abc", "é"},
+		},
+		"check wrap bytes": {
+			input:  methods(literalFn([]byte("foo")), method("wrap", "<", ">")),
+			output: []byte("<foo>"),
+		},
+		"check unwrap bytes": {
+			input:  methods(literalFn([]byte("<foo>")), method("unwrap", "<", ">")),
+			output: []byte("foo"),
+		},
+		"check unwrap one side": {
+			input:  methods(literalFn("foo>"), method("unwrap", "<", ">")),
+			output: "foo>",
+		},
+		"check unwrap overlapping": {
+			input:  methods(literalFn("''"), method("unwrap", "''")),
+			output: "''",
+		},
+		"check type_at": {
+			input: methods(
+				literalFn(map[string]any{"a": map[string]any{"b": int64(5), "c": nil}}),
+				method("type_at", "a.b"),
+			),
+			output: "number",
+		},
+		"check contains_any": {
+			input: methods(
+				literalFn([]any{"a", int64(2), 3.5}),
+				method("contains_any", []any{"b", 2.0}),
+			),
+			output: true,
+		},
+		"check contains_any no match": {
+			input: methods(
+				literalFn([]any{"a", int64(2)}),
+				method("contains_any", []any{"b", "2"}),
+			),
+			output: false,
+		},
+		"check contains_any empty": {
+			input: methods(
+				literalFn([]any{"a"}),
+				method("contains_any", []any{}),
+			),
+			output: false,
+		},
+		"check contains_all": {
+			input: methods(
+				literalFn([]any{"a", int64(2), 3.5}),
+				method("contains_all", []any{"a", 3.5, int64(2)}),
+			),
+			output: true,
+		},
+		"check contains_all missing": {
+			input: methods(
+				literalFn([]any{"a", int64(2)}),
+				method("contains_all", []any{"a", "b"}),
+			),
+			output: false,
+		},
+		"check contains_all empty": {
+			input: methods(
+				literalFn([]any{}),
+				method("contains_all", []any{}),
+			),
+			output: true,
+		},
+		"check contains_all not array": {
+			input: methods(
+				literalFn("foo"),
+				method("contains_all", []any{"f"}),
+			),
+			err: `expected array value, got string from string literal ("foo")`,
+		},
+		"check rotate": {
+			input: methods(
+				literalFn([]any{"a", "b", "c", "d"}),
+				method("rotate", int64(3)),
+			),
+			output: []any{"d", "a", "b", "c"},
+		},
+		"check rotate negative wrap": {
+			input: methods(
+				literalFn([]any{"a", "b", "c", "d"}),
+				method("rotate", int64(-9)),
+			),
+			output: []any{"d", "a", "b", "c"},
+		},
+		"check rotate zero": {
+			input: methods(
+				literalFn([]any{"a", "b"}),
+				method("rotate", int64(4)),
+			),
+			output: []any{"a", "b"},
+		},
+		"check rotate empty": {
+			input: methods(
+				literalFn([]any{}),
+				method("rotate", int64(2)),
+			),
+			output: []any{},
+		},
+		"check is_sorted": {
+			input: methods(
+				literalFn([]any{int64(1), 2.5, int64(3), int64(3)}),
+				method("is_sorted"),
+			),
+			output: true,
+		},
+		"check is_sorted unsorted": {
+			input: methods(
+				literalFn([]any{"a", "c", "b"}),
+				method("is_sorted"),
+			),
+			output: false,
+		},
+		"check is_sorted descending": {
+			input: methods(
+				literalFn([]any{"c", "b", "b", "a"}),
+				method("is_sorted", NewFieldFunction(""), true),
+			),
+			output: true,
+		},
+		"check is_sorted empty": {
+			input: methods(
+				literalFn([]any{}),
+				method("is_sorted"),
+			),
+			output: true,
+		},
+		"check is_sorted query": {
+			input: methods(
+				literalFn([]any{
+					map[string]any{"v": int64(1)},
+					map[string]any{"v": int64(2)},
+				}),
+				method("is_sorted", NewFieldFunction("v")),
+			),
+			output: true,
+		},
+		"check is_sorted mixed types": {
+			input: methods(
+				literalFn([]any{int64(1), "2"}),
+				method("is_sorted"),
+			),
+			err: "array literal: element 1: expected number value, got string (\"2\")",
+		},
+		"check stride": {
+			input: methods(
+				literalFn([]any{int64(0), int64(1), int64(2), int64(3), int64(4)}),
+				method("stride", int64(2)),
+			),
+			output: []any{int64(0), int64(2), int64(4)},
+		},
+		"check stride offset": {
+			input: methods(
+				literalFn([]any{int64(0), int64(1), int64(2), int64(3), int64(4)}),
+				method("stride", int64(3), int64(1)),
+			),
+			output: []any{int64(1), int64(4)},
+		},
+		"check stride offset beyond end": {
+			input: methods(
+				literalFn([]any{int64(0), int64(1)}),
+				method("stride", int64(1), int64(5)),
+			),
+			output: []any{},
+		},
+		"check fill forward": {
+			input: methods(
+				literalFn([]any{nil, "a", nil, "b", nil}),
+				method("fill", "forward"),
+			),
+			output: []any{nil, "a", "a", "b", "b"},
+		},
+		"check fill backward": {
+			input: methods(
+				literalFn([]any{nil, "a", nil, "b", nil}),
+				method("fill", "backward", "z"),
+			),
+			output: []any{"a", "a", "b", "b", "z"},
+		},
+		"check fill value": {
+			input: methods(
+				literalFn([]any{nil, "a", nil}),
+				method("fill", "value", int64(0)),
+			),
+			output: []any{int64(0), "a", int64(0)},
+		},
+		"check fill all null": {
+			input: methods(
+				literalFn([]any{nil, nil}),
+				method("fill", "forward", "x"),
+			),
+			output: []any{"x", "x"},
+		},
+		"check first_existing": {
+			input: methods(
+				literalFn(map[string]any{"a": map[string]any{"b": nil, "c": int64(5)}, "d": "foo"}),
+				method("first_existing", []any{"a.b", "a.e", "a.c", "d"}),
+			),
+			output: int64(5),
+		},
+		"check first_existing none": {
+			input: methods(
+				literalFn(map[string]any{"a": nil}),
+				method("first_existing", []any{"a", "b.c"}),
+			),
+			output: nil,
+		},
+		"check type_at null": {
+			input: methods(
+				literalFn(map[string]any{"a": map[string]any{"b": int64(5), "c": nil}}),
+				method("type_at", "a.c"),
+			),
+			output: "null",
+		},
+		"check type_at missing": {
+			input: methods(
+				literalFn(map[string]any{"a": map[string]any{"b": int64(5), "c": nil}}),
+				method("type_at", "a.b.c"),
+			),
+			output: "missing",
+		},
+		"check type_at root": {
+			input:  methods(literalFn("foo"), method("type_at", "")),
+			output: "string",
+		},
+		"check with_defaults": {
+			input: methods(
+				literalFn(map[string]any{
+					"a": "from target",
+					"b": nil,
+					"c": map[string]any{"d": int64(1)},
+					"e": "not an object",
+				}),
+				method("with_defaults", map[string]any{
+					"a": "from defaults",
+					"b": "from defaults",
+					"c": map[string]any{"d": int64(2), "f": int64(3)},
+					"e": map[string]any{"g": int64(4)},
+					"h": []any{"from defaults"},
+				}),
+			),
+			output: map[string]any{
+				"a": "from target",
+				"b": nil,
+				"c": map[string]any{"d": int64(1), "f": int64(3)},
+				"e": "not an object",
+				"h": []any{"from defaults"},
+			},
+		},
+		"check with_defaults fill null": {
+			input: methods(
+				literalFn(map[string]any{"a": nil, "b": map[string]any{"c": nil}}),
+				method("with_defaults", map[string]any{
+					"a": "from defaults",
+					"b": map[string]any{"c": "from defaults"},
+				}, true),
+			),
+			output: map[string]any{
+				"a": "from defaults",
+				"b": map[string]any{"c": "from defaults"},
+			},
+		},
+		"check with_defaults not object": {
+			input: methods(
+				literalFn("foo"),
+				method("with_defaults", map[string]any{"a": "b"}),
+			),
+			err: `expected object value, got string from string literal ("foo")`,
+		},
+		"check result ok": {
+			input: methods(
+				literalFn("5"),
+				method("result", methods(NewFieldFunction(""), method("number"))),
+			),
+			output: map[string]any{"ok": true, "value": float64(5), "error": nil},
+		},
+		"check result query error": {
+			input: methods(
+				literalFn("nope"),
+				method("result", methods(NewFieldFunction(""), method("number"))),
+			),
+			output: map[string]any{"ok": false, "value": nil, "error": "field `this`: strconv.ParseFloat: parsing \"nope\": invalid syntax"},
+		},
+		"check result target error": {
+			input: methods(
+				literalFn("nope"),
+				method("number"),
+				method("result", methods(NewFieldFunction(""), method("uppercase"))),
+			),
+			output: map[string]any{"ok": false, "value": nil, "error": `string literal: strconv.ParseFloat: parsing "nope": invalid syntax`},
+		},
+		"check scope": {
+			input: methods(
+				literalFn(map[string]any{"a": map[string]any{"b": int64(2), "c": int64(3)}}),
+				method("scope", arithmetic(NewFieldFunction("a.b"), NewFieldFunction("a.c"), ArithmeticMul)),
+			),
+			output: int64(6),
+		},
+		"check enum_map": {
+			input: methods(
+				literalFn(int64(2)),
+				method("enum_map", map[string]any{"1": "one", "2": "two"}, "unknown"),
+			),
+			output: "two",
+		},
+		"check enum_map default": {
+			input: methods(
+				literalFn(true),
+				method("enum_map", map[string]any{"1": "one", "2": "two"}, "unknown"),
+			),
+			output: "unknown",
+		},
+		"check enum_map default query": {
+			input: methods(
+				literalFn("3"),
+				method("enum_map", map[string]any{"1": "one", "2": "two"}, methods(literalFn("fallback"), method("uppercase"))),
+			),
+			output: "FALLBACK",
+		},
+		"check enum_map no default": {
+			input: methods(
+				literalFn("3"),
+				method("enum_map", map[string]any{"1": "one", "2": "two"}),
+			),
+			err: `string literal: value "3" was not found in the table`,
+		},
+		"check unwrap empty": {
+			input:  methods(literalFn("''''"), method("unwrap", "''")),
+			output: "",
+		},
 		"check index of": {
 			input: methods(
 				function(`content`),
@@ -2097,6 +2864,234 @@ func TestMethods(t *testing.T) {
 	}
 }
 
+func TestTapMethod(t *testing.T) {
+	var logs []string
+	res := fnTestResources{logs: &logs}
+
+	logFn, err := InitMethodHelper("log_value", NewFieldFunction("a"), "info", "tapped")
+	require.NoError(t, err)
+
+	e, err := InitMethodHelper("tap", NewLiteralFunction("", map[string]any{"a": "foo"}), logFn)
+	require.NoError(t, err)
+
+	v, err := e.Exec(FunctionContext{Resources: res})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"a": "foo"}, v)
+	assert.Equal(t, []string{`INFO: tapped [value foo]`}, logs)
+
+	failFn, err := InitMethodHelper("number", NewFieldFunction("a"))
+	require.NoError(t, err)
+
+	e, err = InitMethodHelper("tap", NewLiteralFunction("", map[string]any{"a": "foo"}), failFn)
+	require.NoError(t, err)
+
+	_, err = e.Exec(FunctionContext{})
+	require.Error(t, err)
+}
+
+func TestRetryMethod(t *testing.T) {
+	calls := 0
+	flaky := ClosureFunction("flaky", func(ctx FunctionContext) (any, error) {
+		calls++
+		if calls < 3 {
+			return nil, fmt.Errorf("failure %v", calls)
+		}
+		v := ctx.Value()
+		return *v, nil
+	}, nil)
+
+	e, err := InitMethodHelper("retry", NewLiteralFunction("", "foo"), int64(3), flaky)
+	require.NoError(t, err)
+
+	v, err := e.Exec(FunctionContext{})
+	require.NoError(t, err)
+	assert.Equal(t, "foo", v)
+	assert.Equal(t, 3, calls)
+
+	calls = 0
+	e, err = InitMethodHelper("retry", NewLiteralFunction("", "foo"), int64(2), flaky, "1ms")
+	require.NoError(t, err)
+
+	_, err = e.Exec(FunctionContext{})
+	require.EqualError(t, err, "failed after 2 attempts: failure 2")
+	assert.Equal(t, 2, calls)
+
+	calls = 0
+	msgCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	part := message.WithContext(msgCtx, message.NewPart(nil))
+
+	e, err = InitMethodHelper("retry", NewLiteralFunction("", "foo"), int64(5), flaky, "1h")
+	require.NoError(t, err)
+
+	_, err = e.Exec(FunctionContext{MsgBatch: message.Batch{part}})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, calls)
+
+	_, err = InitMethodHelper("retry", NewLiteralFunction("", "foo"), int64(0), flaky)
+	require.EqualError(t, err, "attempts must be at least one, got 0")
+
+	_, err = InitMethodHelper("retry", NewLiteralFunction("", "foo"), int64(1), flaky, "nope")
+	require.Error(t, err)
+}
+
+func TestTimeoutMethod(t *testing.T) {
+	e, err := InitMethodHelper("timeout", NewLiteralFunction("", "foo"), "1h", NewFieldFunction(""))
+	require.NoError(t, err)
+
+	v, err := e.Exec(FunctionContext{})
+	require.NoError(t, err)
+	assert.Equal(t, "foo", v)
+
+	blocking := ClosureFunction("blocking", func(ctx FunctionContext) (any, error) {
+		<-ctx.MsgContext().Done()
+		return nil, ctx.MsgContext().Err()
+	}, nil)
+
+	e, err = InitMethodHelper("timeout", NewLiteralFunction("", "foo"), "10ms", blocking)
+	require.NoError(t, err)
+
+	_, err = e.Exec(FunctionContext{})
+	require.EqualError(t, err, "query exceeded timeout of 10ms")
+
+	slow := ClosureFunction("slow", func(ctx FunctionContext) (any, error) {
+		time.Sleep(20 * time.Millisecond)
+		return "bar", nil
+	}, nil)
+
+	e, err = InitMethodHelper("timeout", NewLiteralFunction("", "foo"), "1ms", slow)
+	require.NoError(t, err)
+
+	_, err = e.Exec(FunctionContext{})
+	require.EqualError(t, err, "query exceeded timeout of 1ms")
+
+	e, err = InitMethodHelper("timeout", NewLiteralFunction("", "foo"), "1h", ClosureFunction("failing", func(ctx FunctionContext) (any, error) {
+		return nil, errors.New("nope")
+	}, nil))
+	require.NoError(t, err)
+
+	_, err = e.Exec(FunctionContext{})
+	require.EqualError(t, err, "nope")
+
+	_, err = InitMethodHelper("timeout", NewLiteralFunction("", "foo"), "0s", blocking)
+	require.Error(t, err)
+}
+
+func TestLogValueMethod(t *testing.T) {
+	var logs []string
+	res := fnTestResources{logs: &logs}
+
+	e, err := InitMethodHelper("log_value", NewLiteralFunction("", map[string]any{"foo": "bar"}), "debug", "hello world")
+	require.NoError(t, err)
+
+	v, err := e.Exec(FunctionContext{Resources: res})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"foo": "bar"}, v)
+	assert.Equal(t, []string{`DEBUG: hello world [value {"foo":"bar"}]`}, logs)
+
+	v, err = e.Exec(FunctionContext{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"foo": "bar"}, v)
+	assert.Len(t, logs, 1)
+
+	_, err = InitMethodHelper("log_value", NewLiteralFunction("", "foo"), "nope", "hello world")
+	require.EqualError(t, err, "log level not recognised: NOPE")
+}
+
+func TestFirstExistingBadPath(t *testing.T) {
+	_, err := InitMethodHelper("first_existing", NewLiteralFunction("", map[string]any{}), []any{"a", int64(5)})
+	require.EqualError(t, err, "paths index 1: expected string value, got number (5)")
+}
+
+func TestStrideBadArgs(t *testing.T) {
+	_, err := InitMethodHelper("stride", NewLiteralFunction("", []any{}), int64(0))
+	require.EqualError(t, err, "n must be greater than zero, got 0")
+
+	_, err = InitMethodHelper("stride", NewLiteralFunction("", []any{}), int64(1), int64(-1))
+	require.EqualError(t, err, "offset must not be negative, got -1")
+}
+
+func TestFormatCSVBadArgs(t *testing.T) {
+	_, err := InitMethodHelper("format_csv", NewLiteralFunction("", []any{}), "ab")
+	require.EqualError(t, err, "delimiter value must be exactly one character")
+
+	_, err = InitMethodHelper("format_csv", NewLiteralFunction("", []any{}), ",", true, []any{"a", 10})
+	require.EqualError(t, err, "columns index 1: expected string value, got number (10)")
+}
+
+func TestFillBadDirection(t *testing.T) {
+	_, err := InitMethodHelper("fill", NewLiteralFunction("", []any{}), "sideways")
+	require.EqualError(t, err, "unrecognised direction: sideways")
+}
+
+func TestLogSampledMethod(t *testing.T) {
+	var logs []string
+	res := fnTestResources{logs: &logs}
+
+	e, err := InitMethodHelper("log_sampled", NewLiteralFunction("", "foo"), "info", "sample", 0.25)
+	require.NoError(t, err)
+
+	for i := 0; i < 9; i++ {
+		v, err := e.Exec(FunctionContext{Resources: res})
+		require.NoError(t, err)
+		assert.Equal(t, "foo", v)
+	}
+	assert.Equal(t, []string{
+		`INFO: sample [value foo]`,
+		`INFO: sample [value foo]`,
+		`INFO: sample [value foo]`,
+	}, logs)
+
+	logs = nil
+	e, err = InitMethodHelper("log_sampled", NewLiteralFunction("", "foo"), "info", "sample", 1.0)
+	require.NoError(t, err)
+	for i := 0; i < 5; i++ {
+		_, err := e.Exec(FunctionContext{Resources: res})
+		require.NoError(t, err)
+	}
+	assert.Len(t, logs, 5)
+
+	for _, rate := range []float64{0, -0.5, 1.5} {
+		_, err = InitMethodHelper("log_sampled", NewLiteralFunction("", "foo"), "info", "sample", rate)
+		require.Error(t, err, rate)
+	}
+
+	_, err = InitMethodHelper("log_sampled", NewLiteralFunction("", "foo"), "info", NewFieldFunction("msg"), 0.5)
+	require.EqualError(t, err, "param message must not be dynamic")
+}
+
+func TestMetricMethods(t *testing.T) {
+	var emitted []string
+	res := fnTestResources{metrics: &emitted}
+
+	e, err := InitMethodHelper("metric_counter", NewLiteralFunction("", "foo"), "events", 2.0, map[string]any{"type": "click", "n": int64(5)})
+	require.NoError(t, err)
+
+	v, err := e.Exec(FunctionContext{Resources: res})
+	require.NoError(t, err)
+	assert.Equal(t, "foo", v)
+
+	e, err = InitMethodHelper("metric_gauge", NewLiteralFunction("", "bar"), "depth", 10.5, map[string]any{})
+	require.NoError(t, err)
+
+	v, err = e.Exec(FunctionContext{Resources: res})
+	require.NoError(t, err)
+	assert.Equal(t, "bar", v)
+
+	v, err = e.Exec(FunctionContext{})
+	require.NoError(t, err)
+	assert.Equal(t, "bar", v)
+
+	assert.Equal(t, []string{
+		"counter events 2 map[n:5 type:click]",
+		"gauge depth 10.5 map[]",
+	}, emitted)
+
+	_, err = InitMethodHelper("metric_counter", NewLiteralFunction("", "foo"), "events", -1.0)
+	require.EqualError(t, err, "counter value must not be negative, got -1")
+}
+
 func TestMethodTargets(t *testing.T) {
 	function := func(name string, args ...any) Function {
 		t.Helper()
@@ -6,7 +6,9 @@
 package query
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/redpanda-data/benthos/v4/internal/message"
 	"github.com/redpanda-data/benthos/v4/internal/value"
@@ -43,6 +45,34 @@ type MetaMsg interface {
 	MetaIterStr(f func(k, v string) error) error
 }
 
+// ExecResources provides functions and methods with access to the resources of
+// the component executing a mapping.
+type ExecResources interface {
+	// AccessRateLimit checks a rate limit resource identified by its label and
+	// returns the duration to wait before the resource should be accessed,
+	// which is zero when access is granted.
+	AccessRateLimit(ctx context.Context, name string) (time.Duration, error)
+
+	// AccessRateLimitKey checks the budget of a key within a rate limit
+	// resource identified by its label, in the same way as AccessRateLimit.
+	AccessRateLimitKey(ctx context.Context, name, key string) (time.Duration, error)
+
+	// StreamID returns the identifier of the stream executing the mapping,
+	// which is empty when the component is not running within streams mode.
+	StreamID() string
+
+	// Log writes a message to the logger of the component at a given level,
+	// which is one of TRACE, DEBUG, INFO, WARN or ERROR, along with optional
+	// key/value pairs.
+	Log(level, message string, keyValues ...any)
+
+	// IncrCounter increments a counter metric with a set of labels.
+	IncrCounter(name string, count float64, labels map[string]string)
+
+	// SetGauge sets the value of a gauge metric with a set of labels.
+	SetGauge(name string, value float64, labels map[string]string)
+}
+
 // FunctionContext provides access to a range of query targets for functions to
 // reference.
 type FunctionContext struct {
@@ -55,11 +85,19 @@ type FunctionContext struct {
 	NewMeta  MetaMsg
 	NewValue *any
 
+	// Resources of the component executing the mapping, which may be nil when
+	// the mapping is not executed by a component.
+	Resources ExecResources
+
 	valueFn    func() *any
 	value      *any
 	nextValue  *any
 	namedValue *namedContextValue
 
+	// Overrides the context of the message being mapped, used in order to
+	// bound the execution of queries.
+	msgCtx context.Context
+
 	// Used to track how many maps we've entered.
 	stackCount int
 }
@@ -100,6 +138,27 @@ func (ctx FunctionContext) WithNamedValue(name string, value any) FunctionContex
 	return ctx
 }
 
+// WithMsgContext returns a function context where MsgContext returns the
+// provided context.Context rather than the context of the message being mapped.
+func (ctx FunctionContext) WithMsgContext(c context.Context) FunctionContext {
+	ctx.msgCtx = c
+	return ctx
+}
+
+// MsgContext returns the context.Context of the message currently being
+// mapped, or a background context if there isn't one.
+func (ctx FunctionContext) MsgContext() context.Context {
+	if ctx.msgCtx != nil {
+		return ctx.msgCtx
+	}
+	if ctx.MsgBatch != nil && ctx.Index < ctx.MsgBatch.Len() {
+		if c := ctx.MsgBatch.Get(ctx.Index).GetContext(); c != nil {
+			return c
+		}
+	}
+	return context.Background()
+}
+
 // Value returns a lazily evaluated context value. A context value is not always
 // available and can therefore be nil.
 func (ctx FunctionContext) Value() *any {
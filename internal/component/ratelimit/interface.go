@@ -4,6 +4,7 @@ package ratelimit
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
@@ -20,3 +21,25 @@ type V1 interface {
 	// is cancelled.
 	Close(ctx context.Context) error
 }
+
+// Keyed is an optional interface implemented by rate limits that are able to
+// track an independent budget for each of an open-ended set of keys.
+type Keyed interface {
+	// AccessKey accesses the rate limited resource in the same way as Access,
+	// but consumes from the budget of a specific key.
+	AccessKey(ctx context.Context, key string) (time.Duration, error)
+}
+
+// ErrKeysNotSupported is returned when a key is provided to a rate limit that
+// does not implement Keyed.
+var ErrKeysNotSupported = errors.New("rate limit does not support keys")
+
+// AccessKey accesses a rate limit for a given key, returning
+// ErrKeysNotSupported if the rate limit does not implement Keyed.
+func AccessKey(ctx context.Context, r V1, key string) (time.Duration, error) {
+	k, ok := r.(Keyed)
+	if !ok {
+		return 0, ErrKeysNotSupported
+	}
+	return k.AccessKey(ctx, key)
+}
@@ -40,6 +40,17 @@ func (r *metricsRateLimit) Access(ctx context.Context) (time.Duration, error) {
 	return tout, err
 }
 
+func (r *metricsRateLimit) AccessKey(ctx context.Context, key string) (time.Duration, error) {
+	r.mChecked.Incr(1)
+	tout, err := AccessKey(ctx, r.r, key)
+	if err != nil {
+		r.mErr.Incr(1)
+	} else if tout > 0 {
+		r.mLimited.Incr(1)
+	}
+	return tout, err
+}
+
 func (r *metricsRateLimit) Close(ctx context.Context) error {
 	return r.r.Close(ctx)
 }
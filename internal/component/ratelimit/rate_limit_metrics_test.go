@@ -33,3 +33,28 @@ func TestRateLimitAirGapShutdown(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, rl.closed)
 }
+
+type keyedRateLimit struct {
+	closableRateLimit
+	keys []string
+}
+
+func (k *keyedRateLimit) AccessKey(ctx context.Context, key string) (time.Duration, error) {
+	k.keys = append(k.keys, key)
+	return time.Second, nil
+}
+
+func TestRateLimitAirGapAccessKey(t *testing.T) {
+	rl := &keyedRateLimit{}
+	agrl := MetricsForRateLimit(rl, metrics.Noop())
+
+	tout, err := AccessKey(context.Background(), agrl, "foo")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Second, tout)
+	assert.Equal(t, []string{"foo"}, rl.keys)
+
+	agrl = MetricsForRateLimit(&closableRateLimit{}, metrics.Noop())
+
+	_, err = AccessKey(context.Background(), agrl, "foo")
+	assert.ErrorIs(t, err, ErrKeysNotSupported)
+}
@@ -0,0 +1,119 @@
+// Copyright 2025 Redpanda Data, Inc.
+
+package io
+
+import (
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/oschwald/maxminddb-golang"
+
+	"github.com/redpanda-data/benthos/v4/internal/bloblang/query"
+	"github.com/redpanda-data/benthos/v4/public/bloblang"
+)
+
+// geoipReaders holds MaxMind database readers keyed by their path so that each
+// database is opened at most once for the lifetime of the process, regardless
+// of how many mappings reference it.
+var geoipReaders = struct {
+	sync.Mutex
+	m map[string]*maxminddb.Reader
+}{m: map[string]*maxminddb.Reader{}}
+
+func geoipReader(path string) (*maxminddb.Reader, error) {
+	geoipReaders.Lock()
+	defer geoipReaders.Unlock()
+
+	if r, exists := geoipReaders.m[path]; exists {
+		return r, nil
+	}
+	r, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open geoip database '%v': %w", path, err)
+	}
+	geoipReaders.m[path] = r
+	return r, nil
+}
+
+// geoipRecord is a superset of the fields found within the GeoLite2 City,
+// Country and ASN databases, fields that are absent from a given database are
+// left empty.
+type geoipRecord struct {
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	Location struct {
+		Latitude  *float64 `maxminddb:"latitude"`
+		Longitude *float64 `maxminddb:"longitude"`
+	} `maxminddb:"location"`
+	ASN *uint64 `maxminddb:"autonomous_system_number"`
+}
+
+func (r *geoipRecord) toMap() map[string]any {
+	res := map[string]any{
+		"country":   nil,
+		"city":      nil,
+		"latitude":  nil,
+		"longitude": nil,
+		"asn":       nil,
+	}
+	if r.Country.ISOCode != "" {
+		res["country"] = r.Country.ISOCode
+	}
+	if name, exists := r.City.Names["en"]; exists {
+		res["city"] = name
+	}
+	if r.Location.Latitude != nil {
+		res["latitude"] = *r.Location.Latitude
+	}
+	if r.Location.Longitude != nil {
+		res["longitude"] = *r.Location.Longitude
+	}
+	if r.ASN != nil {
+		res["asn"] = int64(*r.ASN)
+	}
+	return res
+}
+
+func init() {
+	if err := bloblang.RegisterMethodV2("geoip",
+		bloblang.NewPluginSpec().
+			Impure().
+			Category(query.MethodCategoryGeoIP).
+			Description("Looks up an IP address within a https://www.maxmind.com/[MaxMind^] database (such as GeoLite2 City or ASN) and returns an object containing the fields `country` (an ISO 3166-1 code), `city` (the English name), `latitude`, `longitude` and `asn`. Fields that are not present in the database for the given address are set to `null`. Each database is opened only once and is shared across all mappings that reference the same path. An error is returned if the database cannot be opened or the target is not a valid IP address.").
+			Param(bloblang.NewStringParam("database_path").
+				Description("The path of a MaxMind `.mmdb` database file.")).
+			ExampleNotTested("", `root.geo = this.client_ip.geoip("/var/lib/GeoIP/GeoLite2-City.mmdb")`,
+				[2]string{
+					`{"client_ip":"81.2.69.142"}`,
+					`{"geo":{"asn":null,"city":"London","country":"GB","latitude":51.5142,"longitude":-0.0931}}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			dbPath, err := args.GetString("database_path")
+			if err != nil {
+				return nil, err
+			}
+			reader, err := geoipReader(dbPath)
+			if err != nil {
+				return nil, err
+			}
+			return bloblang.StringMethod(func(s string) (any, error) {
+				ip := net.ParseIP(s)
+				if ip == nil {
+					return nil, fmt.Errorf("invalid IP address: %q", s)
+				}
+				var record geoipRecord
+				if err := reader.Lookup(ip, &record); err != nil {
+					return nil, fmt.Errorf("failed to lookup IP address: %w", err)
+				}
+				return record.toMap(), nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+}
@@ -115,3 +115,86 @@ func TestFileRelFunctionCaching(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "hello world 456", value.IToString(res))
 }
+
+func TestGeoIPMissingDatabase(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "does_not_exist.mmdb")
+
+	_, err := query.InitMethodHelper("geoip", query.NewLiteralFunction("", "81.2.69.142"), dbPath)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to open geoip database")
+}
+
+func TestGeoIPLookup(t *testing.T) {
+	// A small database containing a city record, a country only record and an
+	// ASN record, in the same structure as the GeoLite2 databases.
+	dbPath := filepath.Join("testdata", "geoip.mmdb")
+
+	tests := map[string]struct {
+		input  string
+		output map[string]any
+	}{
+		"city": {
+			input: "81.2.69.142",
+			output: map[string]any{
+				"country":   "GB",
+				"city":      "London",
+				"latitude":  51.5142,
+				"longitude": -0.0931,
+				"asn":       nil,
+			},
+		},
+		"country": {
+			input: "2.125.160.218",
+			output: map[string]any{
+				"country":   "GB",
+				"city":      nil,
+				"latitude":  nil,
+				"longitude": nil,
+				"asn":       nil,
+			},
+		},
+		"asn": {
+			input: "1.128.0.1",
+			output: map[string]any{
+				"country":   nil,
+				"city":      nil,
+				"latitude":  nil,
+				"longitude": nil,
+				"asn":       int64(1221),
+			},
+		},
+		"not found": {
+			input: "10.0.0.1",
+			output: map[string]any{
+				"country":   nil,
+				"city":      nil,
+				"latitude":  nil,
+				"longitude": nil,
+				"asn":       nil,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			e, err := query.InitMethodHelper("geoip", query.NewLiteralFunction("", test.input), dbPath)
+			require.NoError(t, err)
+
+			res, err := e.Exec(query.FunctionContext{})
+			require.NoError(t, err)
+			assert.Equal(t, test.output, res)
+		})
+	}
+}
+
+func TestGeoIPMalformedAddress(t *testing.T) {
+	dbPath := filepath.Join("testdata", "geoip.mmdb")
+
+	e, err := query.InitMethodHelper("geoip", query.NewLiteralFunction("", "not.an.ip.address"), dbPath)
+	require.NoError(t, err)
+
+	_, err = e.Exec(query.FunctionContext{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid IP address: "not.an.ip.address"`)
+}
@@ -4,6 +4,7 @@ package io
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"io"
 	"net"
@@ -15,8 +16,10 @@ import (
 )
 
 const (
-	isFieldNetwork = "network"
-	isFieldAddress = "address"
+	isFieldNetwork             = "network"
+	isFieldAddress             = "address"
+	isFieldEmitLifecycleEvents = "emit_lifecycle_events"
+	isFieldTLS                 = "tls"
 )
 
 func socketInputSpec() *service.ConfigSpec {
@@ -24,12 +27,32 @@ func socketInputSpec() *service.ConfigSpec {
 		Stable().
 		Summary(`Connects to a tcp or unix socket and consumes a continuous stream of messages.`).
 		Categories("Network").
+		Description(`
+== Metadata
+
+When `+"`emit_lifecycle_events`"+` is enabled an empty message is emitted each time a connection is established or lost, with the following metadata fields:
+
+- socket_event
+- socket_remote_address
+
+The `+"`socket_event`"+` field is either `+"`connected` or `disconnected`"+`, and data messages do not have this field set.
+
+The `+"`connected`"+` event of a connection is always emitted before any data read from that connection, and the `+"`disconnected`"+` event is emitted after all data read from that connection. The `+"`disconnected`"+` event is not emitted when the input is shutting down.
+
+You can access these metadata fields using xref:configuration:interpolation.adoc#bloblang-queries[function interpolation].`).
 		Fields(
 			service.NewStringEnumField(isFieldNetwork, "unix", "tcp").
 				Description("A network type to assume (unix|tcp)."),
 			service.NewStringField(isFieldAddress).
 				Description("The address to connect to.").
 				Examples("/tmp/benthos.sock", "127.0.0.1:6000"),
+			service.NewBoolField(isFieldEmitLifecycleEvents).
+				Description("Whether to emit messages when a connection is established or lost, allowing downstream components to detect reconnects.").
+				Advanced().
+				Default(false).
+				Version("4.44.0"),
+			service.NewTLSToggledField(isFieldTLS).
+				Version("4.44.0"),
 			service.NewAutoRetryNacksToggleField(),
 		).
 		Fields(codec.DeprecatedCodecFields("lines")...)
@@ -52,12 +75,16 @@ func init() {
 type socketReader struct {
 	log *service.Logger
 
-	address   string
-	network   string
-	codecCtor codec.DeprecatedFallbackCodec
+	address         string
+	network         string
+	codecCtor       codec.DeprecatedFallbackCodec
+	lifecycleEvents bool
+	tlsConf         *tls.Config
 
-	codecMut sync.Mutex
-	codec    codec.DeprecatedFallbackStream
+	codecMut   sync.Mutex
+	codec      codec.DeprecatedFallbackStream
+	remoteAddr string
+	events     service.MessageBatch
 }
 
 func newSocketReaderFromParsed(pConf *service.ParsedConfig, mgr *service.Resources) (rdr *socketReader, err error) {
@@ -73,9 +100,31 @@ func newSocketReaderFromParsed(pConf *service.ParsedConfig, mgr *service.Resourc
 	if rdr.codecCtor, err = codec.DeprecatedCodecFromParsed(pConf); err != nil {
 		return
 	}
+	if rdr.lifecycleEvents, err = pConf.FieldBool(isFieldEmitLifecycleEvents); err != nil {
+		return
+	}
+	var tlsEnabled bool
+	if rdr.tlsConf, tlsEnabled, err = pConf.FieldTLSToggled(isFieldTLS); err != nil {
+		return
+	}
+	if !tlsEnabled {
+		rdr.tlsConf = nil
+	}
 	return
 }
 
+// addEvent queues a lifecycle event message to be read before any subsequent
+// data, and must be called whilst holding codecMut.
+func (s *socketReader) addEvent(event string) {
+	if !s.lifecycleEvents {
+		return
+	}
+	msg := service.NewMessage(nil)
+	msg.MetaSetMut("socket_event", event)
+	msg.MetaSetMut("socket_remote_address", s.remoteAddr)
+	s.events = append(s.events, msg)
+}
+
 func (s *socketReader) Connect(ctx context.Context) error {
 	s.codecMut.Lock()
 	defer s.codecMut.Unlock()
@@ -84,7 +133,14 @@ func (s *socketReader) Connect(ctx context.Context) error {
 		return nil
 	}
 
-	conn, err := net.Dial(s.network, s.address)
+	var conn net.Conn
+	var err error
+	if s.tlsConf != nil {
+		dialer := tls.Dialer{Config: s.tlsConf}
+		conn, err = dialer.DialContext(ctx, s.network, s.address)
+	} else {
+		conn, err = net.Dial(s.network, s.address)
+	}
 	if err != nil {
 		return err
 	}
@@ -95,14 +151,28 @@ func (s *socketReader) Connect(ctx context.Context) error {
 		conn.Close()
 		return err
 	}
+
+	s.remoteAddr = ""
+	if addr := conn.RemoteAddr(); addr != nil {
+		s.remoteAddr = addr.String()
+	}
+	s.addEvent("connected")
 	return nil
 }
 
 func (s *socketReader) ReadBatch(ctx context.Context) (service.MessageBatch, service.AckFunc, error) {
 	s.codecMut.Lock()
 	codec := s.codec
+	events := s.events
+	s.events = nil
 	s.codecMut.Unlock()
 
+	if len(events) > 0 {
+		return events, func(rctx context.Context, res error) error {
+			return nil
+		}, nil
+	}
+
 	if codec == nil {
 		return nil, nil, service.ErrNotConnected
 	}
@@ -118,6 +188,7 @@ func (s *socketReader) ReadBatch(ctx context.Context) (service.MessageBatch, ser
 			if s.codec != nil && s.codec == codec {
 				s.codec.Close(ctx)
 				s.codec = nil
+				s.addEvent("disconnected")
 			}
 			s.codecMut.Unlock()
 		}
@@ -3,6 +3,7 @@
 package io
 
 import (
+	"bufio"
 	"context"
 	"crypto/ecdsa"
 	"crypto/elliptic"
@@ -17,6 +18,7 @@ import (
 	"net"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Jeffail/shutdown"
@@ -34,13 +36,33 @@ const (
 	issFieldTLSCertFile   = "cert_file"
 	issFieldTLSKeyFile    = "key_file"
 	issFieldTLSSelfSigned = "self_signed"
+
+	issFieldProxyProtocol       = "proxy_protocol"
+	issFieldProxyProtocolStrict = "proxy_protocol_strict"
+	issFieldMaxConnections      = "max_connections"
+	issFieldReadTimeout         = "read_timeout"
+	issFieldMaxMessageSize      = "max_message_size"
 )
 
+// The maximum time a new connection is given to send a PROXY protocol header.
+const proxyProtocolHeaderTimeout = 10 * time.Second
+
 func socketServerInputSpec() *service.ConfigSpec {
 	return service.NewConfigSpec().
 		Stable().
 		Summary(`Creates a server that receives a stream of messages over a TCP, UDP or Unix socket.`).
 		Categories("Network").
+		Description(`
+== Metadata
+
+When `+"`proxy_protocol`"+` is enabled each message has the following metadata fields:
+
+- socket_client_ip
+- socket_client_port
+
+These fields contain the source address given by the PROXY protocol header of the connection, or the remote address of the connection itself when the header does not describe a source (such as health checks from the proxy).
+
+You can access these metadata fields using xref:configuration:interpolation.adoc#bloblang-queries[function interpolation].`).
 		Fields(
 			service.NewStringEnumField(issFieldNetwork, "unix", "tcp", "udp", "tls").
 				Description("A network type to accept."),
@@ -64,6 +86,32 @@ func socketServerInputSpec() *service.ConfigSpec {
 			).
 				Description("TLS specific configuration, valid when the `network` is set to `tls`.").
 				Optional(),
+			service.NewBoolField(issFieldProxyProtocol).
+				Description("Whether to parse a https://www.haproxy.org/download/2.9/doc/proxy-protocol.txt[PROXY protocol^] v1 or v2 header at the beginning of each connection, which is stripped before the data is consumed. This allows the real source of connections arriving via a load balancer to be obtained. Only valid when the `network` is `tcp`, `tls` or `unix`.").
+				Advanced().
+				Default(false).
+				Version("4.44.0"),
+			service.NewBoolField(issFieldProxyProtocolStrict).
+				Description("When `proxy_protocol` is enabled, whether connections that do not begin with a PROXY protocol header should be rejected. When disabled such connections are consumed as normal and their metadata describes the remote address of the connection.").
+				Advanced().
+				Default(true).
+				Version("4.44.0"),
+			service.NewIntField(issFieldMaxConnections).
+				Description("The maximum number of concurrent connections to accept, connections made beyond this limit are closed immediately. Set to zero to disable the limit. Only valid when the `network` is `tcp`, `tls` or `unix`.").
+				Advanced().
+				Default(0).
+				Version("4.44.0"),
+			service.NewDurationField(issFieldReadTimeout).
+				Description("The maximum period of time to wait for data from a connection before closing it. Set to zero to disable the timeout. Only valid when the `network` is `tcp`, `tls` or `unix`.").
+				Advanced().
+				Default("0s").
+				Example("30s").
+				Version("4.44.0"),
+			service.NewIntField(issFieldMaxMessageSize).
+				Description("The maximum size in bytes of a single message read from a connection. When a message exceeds this size an error is logged and the connection is closed, other connections are unaffected. Set to zero to disable the limit. Only valid when the `network` is `tcp`, `tls` or `unix`. The limit is enforced whilst reading from the connection, and therefore a message that exceeds it is rejected without being buffered in full.").
+				Advanced().
+				Default(0).
+				Version("4.44.0"),
 			service.NewAutoRetryNacksToggleField(),
 		).
 		Fields(codec.DeprecatedCodecFields("lines")...)
@@ -103,6 +151,14 @@ type socketServerInput struct {
 	tlsSelfSigned bool
 	codecCtor     codec.DeprecatedFallbackCodec
 
+	proxyProtocol       bool
+	proxyProtocolStrict bool
+	tlsConfig           *tls.Config
+
+	maxConnections int
+	readTimeout    time.Duration
+	maxMessageSize int
+
 	messages chan service.MessageBatch
 	shutSig  *shutdown.Signaller
 }
@@ -131,9 +187,115 @@ func newSocketServerInputFromParsed(conf *service.ParsedConfig, mgr *service.Res
 	if t.codecCtor, err = codec.DeprecatedCodecFromParsed(conf); err != nil {
 		return
 	}
+
+	if t.proxyProtocol, err = conf.FieldBool(issFieldProxyProtocol); err != nil {
+		return
+	}
+	if t.proxyProtocolStrict, err = conf.FieldBool(issFieldProxyProtocolStrict); err != nil {
+		return
+	}
+	if t.proxyProtocol && t.network == "udp" {
+		return nil, errors.New("proxy_protocol cannot be enabled when the network is udp")
+	}
+
+	if t.maxConnections, err = conf.FieldInt(issFieldMaxConnections); err != nil {
+		return
+	}
+	if t.maxConnections < 0 {
+		return nil, fmt.Errorf("%v must not be negative, got %v", issFieldMaxConnections, t.maxConnections)
+	}
+	if t.readTimeout, err = conf.FieldDuration(issFieldReadTimeout); err != nil {
+		return
+	}
+	if t.readTimeout < 0 {
+		return nil, fmt.Errorf("%v must not be negative, got %v", issFieldReadTimeout, t.readTimeout)
+	}
+	if t.maxMessageSize, err = conf.FieldInt(issFieldMaxMessageSize); err != nil {
+		return
+	}
+	if t.maxMessageSize < 0 {
+		return nil, fmt.Errorf("%v must not be negative, got %v", issFieldMaxMessageSize, t.maxMessageSize)
+	}
 	return &t, nil
 }
 
+// readTimeoutConn closes connections that have been idle for longer than the
+// timeout by refreshing the read deadline before each read.
+type readTimeoutConn struct {
+	net.Conn
+	timeout time.Duration
+}
+
+func (r *readTimeoutConn) Read(b []byte) (int, error) {
+	if err := r.Conn.SetReadDeadline(time.Now().Add(r.timeout)); err != nil {
+		return 0, err
+	}
+	return r.Conn.Read(b)
+}
+
+// The number of bytes beyond max_message_size that may be read between
+// messages, which accommodates delimiters and data buffered ahead by a codec.
+const maxMessageSizeReadSlack = 4096
+
+var errMessageTooLarge = errors.New("message exceeds the maximum size")
+
+// maxMessageSizeConn fails reads once more than limit bytes have been read since
+// the last reset, which prevents a codec from buffering an unbounded amount of
+// data whilst searching for the end of a message.
+type maxMessageSizeConn struct {
+	net.Conn
+	limit int
+	read  int
+}
+
+func (m *maxMessageSizeConn) Read(b []byte) (int, error) {
+	remaining := m.limit - m.read
+	if remaining <= 0 {
+		return 0, errMessageTooLarge
+	}
+	if len(b) > remaining {
+		b = b[:remaining]
+	}
+	n, err := m.Conn.Read(b)
+	m.read += n
+	return n, err
+}
+
+func (m *maxMessageSizeConn) reset() {
+	m.read = 0
+}
+
+type proxyProtocolConn struct {
+	net.Conn
+	rdr *bufio.Reader
+}
+
+func (p *proxyProtocolConn) Read(b []byte) (int, error) {
+	return p.rdr.Read(b)
+}
+
+// readProxyProtocol consumes the PROXY protocol header from a new connection
+// and returns a connection of the remaining data along with the real source
+// address of the connection.
+func (t *socketServerInput) readProxyProtocol(c net.Conn) (net.Conn, net.Addr, error) {
+	_ = c.SetReadDeadline(time.Now().Add(proxyProtocolHeaderTimeout))
+	defer func() {
+		_ = c.SetReadDeadline(time.Time{})
+	}()
+
+	rdr := bufio.NewReader(c)
+	addr, err := readProxyProtocolHeader(rdr)
+	if err != nil && (!errors.Is(err, errNoProxyProtocolHeader) || t.proxyProtocolStrict) {
+		return nil, nil, err
+	}
+
+	var source net.Addr = c.RemoteAddr()
+	if addr != nil {
+		source = addr
+	}
+	return &proxyProtocolConn{Conn: c, rdr: rdr}, source, nil
+}
+
 func (t *socketServerInput) Connect(ctx context.Context) error {
 	var ln net.Listener
 	var cn net.PacketConn
@@ -150,7 +312,14 @@ func (t *socketServerInput) Connect(ctx context.Context) error {
 		config := &tls.Config{
 			Certificates: []tls.Certificate{cert},
 		}
-		ln, err = tls.Listen("tcp", t.address, config)
+		if t.proxyProtocol {
+			// TLS is established per connection once the PROXY protocol
+			// header has been consumed.
+			t.tlsConfig = config
+			ln, err = net.Listen("tcp", t.address)
+		} else {
+			ln, err = tls.Listen("tcp", t.address, config)
+		}
 	case "udp":
 		cn, err = net.ListenPacket(t.network, t.address)
 	default:
@@ -220,6 +389,8 @@ func (t *socketServerInput) loop(listener net.Listener) {
 	closeCtx, done := t.shutSig.SoftStopCtx(context.Background())
 	defer done()
 
+	var connCount atomic.Int64
+
 acceptLoop:
 	for {
 		conn, err := listener.Accept()
@@ -235,6 +406,13 @@ acceptLoop:
 			}
 		}
 
+		if t.maxConnections > 0 && connCount.Load() >= int64(t.maxConnections) {
+			t.log.Warnf("Rejecting connection from %v as the maximum of %v connections has been reached", conn.RemoteAddr(), t.maxConnections)
+			_ = conn.Close()
+			continue acceptLoop
+		}
+		connCount.Add(1)
+
 		go func() {
 			<-t.shutSig.SoftStopChan()
 			_ = conn.Close()
@@ -244,9 +422,42 @@ acceptLoop:
 		go func(c net.Conn) {
 			defer func() {
 				_ = c.Close()
+				connCount.Add(-1)
 				wg.Done()
 			}()
 
+			var clientIP, clientPort string
+			if t.proxyProtocol {
+				pConn, source, err := t.readProxyProtocol(c)
+				if err != nil {
+					t.log.Errorf("Rejecting connection from %v: %v", c.RemoteAddr(), err)
+					return
+				}
+				if source != nil {
+					var splitErr error
+					if clientIP, clientPort, splitErr = net.SplitHostPort(source.String()); splitErr != nil {
+						clientIP = source.String()
+					}
+				}
+
+				// The PROXY protocol header precedes the TLS handshake, and
+				// therefore TLS is layered on top of the remaining data.
+				c = pConn
+				if t.tlsConfig != nil {
+					c = tls.Server(c, t.tlsConfig)
+				}
+			}
+
+			if t.readTimeout > 0 {
+				c = &readTimeoutConn{Conn: c, timeout: t.readTimeout}
+			}
+
+			var sizeConn *maxMessageSizeConn
+			if t.maxMessageSize > 0 {
+				sizeConn = &maxMessageSizeConn{Conn: c, limit: t.maxMessageSize + maxMessageSizeReadSlack}
+				c = sizeConn
+			}
+
 			codec, err := t.codecCtor.Create(c, func(ctx context.Context, err error) error {
 				return nil
 			}, service.NewScannerSourceDetails())
@@ -258,16 +469,39 @@ acceptLoop:
 			for {
 				parts, ackFn, err := codec.NextBatch(closeCtx)
 				if err != nil {
-					if !errors.Is(err, io.EOF) {
+					if errors.Is(err, errMessageTooLarge) {
+						t.log.Errorf("Closing connection from %v as a message exceeds the maximum size of %v bytes", c.RemoteAddr(), t.maxMessageSize)
+					} else if !errors.Is(err, io.EOF) {
 						t.log.Errorf("Connection dropped due to: %v\n", err)
 					}
 					return
 				}
 
+				if sizeConn != nil {
+					// Data buffered ahead by the codec may allow a message to
+					// slightly exceed the limit before it is caught by the
+					// reader, and therefore the size is also checked here.
+					for _, p := range parts {
+						if b, _ := p.AsBytes(); len(b) > t.maxMessageSize {
+							t.log.Errorf("Closing connection from %v as a message of %v bytes exceeds the maximum size of %v bytes", c.RemoteAddr(), len(b), t.maxMessageSize)
+							_ = ackFn(closeCtx, errMessageTooLarge)
+							return
+						}
+					}
+					sizeConn.reset()
+				}
+
 				// We simply bounce rejected messages in a loop downstream so
 				// there's no benefit to aggregating acks.
 				_ = ackFn(closeCtx, nil)
 
+				if t.proxyProtocol {
+					for _, p := range parts {
+						p.MetaSetMut("socket_client_ip", clientIP)
+						p.MetaSetMut("socket_client_port", clientPort)
+					}
+				}
+
 				select {
 				case t.messages <- parts:
 				case <-t.shutSig.SoftStopChan():
@@ -3,6 +3,7 @@
 package io_test
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"errors"
@@ -1272,3 +1273,366 @@ socket_server:
 	wg.Wait()
 	conn.Close()
 }
+
+func TestSocketServerProxyProtocol(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*20)
+	defer done()
+
+	rdr, addr := socketServerInputFromConf(t, `
+socket_server:
+  network: tcp
+  address: 127.0.0.1:0
+  proxy_protocol: true
+`)
+
+	defer func() {
+		rdr.TriggerStopConsuming()
+		assert.NoError(t, rdr.WaitForClose(tCtx))
+	}()
+
+	readNextMsg := func() (message.Batch, error) {
+		var tran message.Transaction
+		select {
+		case tran = <-rdr.TransactionChan():
+			require.NoError(t, tran.Ack(tCtx, nil))
+		case <-time.After(time.Second * 5):
+			return nil, errors.New("timed out")
+		}
+		return tran.Payload, nil
+	}
+
+	// Connections without a header are rejected by default.
+	noHeaderConn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	_, err = noHeaderConn.Write([]byte("nope\n"))
+	require.NoError(t, err)
+	_ = noHeaderConn.SetReadDeadline(time.Now().Add(time.Second * 5))
+	_, err = noHeaderConn.Read(make([]byte, 1))
+	require.Error(t, err)
+	noHeaderConn.Close()
+
+	for _, test := range []struct {
+		name   string
+		header []byte
+		ip     string
+		port   string
+	}{
+		{
+			name:   "v1",
+			header: []byte("PROXY TCP4 192.168.0.1 192.168.0.11 56324 443\r\n"),
+			ip:     "192.168.0.1",
+			port:   "56324",
+		},
+		{
+			name: "v2",
+			header: append([]byte("\r\n\r\n\x00\r\nQUIT\n\x21\x11\x00\x0c"),
+				10, 0, 0, 5, 10, 0, 0, 6, 0x1f, 0x90, 0x01, 0xbb),
+			ip:   "10.0.0.5",
+			port: "8080",
+		},
+	} {
+		conn, err := net.Dial("tcp", addr)
+		require.NoError(t, err, test.name)
+
+		_ = conn.SetWriteDeadline(time.Now().Add(time.Second * 5))
+		_, err = conn.Write(append(test.header, []byte("foo\n")...))
+		require.NoError(t, err, test.name)
+
+		msg, err := readNextMsg()
+		require.NoError(t, err, test.name)
+		require.Len(t, msg, 1, test.name)
+		assert.Equal(t, "foo", string(msg[0].AsBytes()), test.name)
+		assert.Equal(t, test.ip, msg[0].MetaGetStr("socket_client_ip"), test.name)
+		assert.Equal(t, test.port, msg[0].MetaGetStr("socket_client_port"), test.name)
+
+		conn.Close()
+	}
+}
+
+func TestSocketServerProxyProtocolNotStrict(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*20)
+	defer done()
+
+	rdr, addr := socketServerInputFromConf(t, `
+socket_server:
+  network: tcp
+  address: 127.0.0.1:0
+  proxy_protocol: true
+  proxy_protocol_strict: false
+`)
+
+	defer func() {
+		rdr.TriggerStopConsuming()
+		assert.NoError(t, rdr.WaitForClose(tCtx))
+	}()
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_ = conn.SetWriteDeadline(time.Now().Add(time.Second * 5))
+	_, err = conn.Write([]byte("foo\n"))
+	require.NoError(t, err)
+
+	var tran message.Transaction
+	select {
+	case tran = <-rdr.TransactionChan():
+		require.NoError(t, tran.Ack(tCtx, nil))
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out")
+	}
+
+	localIP, localPort, err := net.SplitHostPort(conn.LocalAddr().String())
+	require.NoError(t, err)
+
+	require.Len(t, tran.Payload, 1)
+	assert.Equal(t, "foo", string(tran.Payload[0].AsBytes()))
+	assert.Equal(t, localIP, tran.Payload[0].MetaGetStr("socket_client_ip"))
+	assert.Equal(t, localPort, tran.Payload[0].MetaGetStr("socket_client_port"))
+}
+
+func TestTLSSocketServerProxyProtocol(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*20)
+	defer done()
+
+	rdr, addr := socketServerInputFromConf(t, `
+socket_server:
+  network: tls
+  address: 127.0.0.1:0
+  proxy_protocol: true
+  tls:
+    self_signed: true
+`)
+
+	defer func() {
+		rdr.TriggerStopConsuming()
+		assert.NoError(t, rdr.WaitForClose(tCtx))
+	}()
+
+	rawConn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+
+	_ = rawConn.SetDeadline(time.Now().Add(time.Second * 5))
+	_, err = rawConn.Write([]byte("PROXY TCP6 2001:db8::1 2001:db8::2 4000 443\r\n"))
+	require.NoError(t, err)
+
+	conn := tls.Client(rawConn, &tls.Config{
+		InsecureSkipVerify: true,
+	})
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("foo\n"))
+	require.NoError(t, err)
+
+	var tran message.Transaction
+	select {
+	case tran = <-rdr.TransactionChan():
+		require.NoError(t, tran.Ack(tCtx, nil))
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out")
+	}
+
+	require.Len(t, tran.Payload, 1)
+	assert.Equal(t, "foo", string(tran.Payload[0].AsBytes()))
+	assert.Equal(t, "2001:db8::1", tran.Payload[0].MetaGetStr("socket_client_ip"))
+	assert.Equal(t, "4000", tran.Payload[0].MetaGetStr("socket_client_port"))
+}
+
+func TestSocketServerMaxConnections(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*20)
+	defer done()
+
+	rdr, addr := socketServerInputFromConf(t, `
+socket_server:
+  network: tcp
+  address: 127.0.0.1:0
+  max_connections: 1
+`)
+
+	defer func() {
+		rdr.TriggerStopConsuming()
+		assert.NoError(t, rdr.WaitForClose(tCtx))
+	}()
+
+	readNextMsg := func() (message.Batch, error) {
+		var tran message.Transaction
+		select {
+		case tran = <-rdr.TransactionChan():
+			require.NoError(t, tran.Ack(tCtx, nil))
+		case <-time.After(time.Second * 5):
+			return nil, errors.New("timed out")
+		}
+		return tran.Payload, nil
+	}
+
+	connA, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer connA.Close()
+
+	_, err = connA.Write([]byte("foo\n"))
+	require.NoError(t, err)
+
+	msg, err := readNextMsg()
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("foo")}, message.GetAllBytes(msg))
+
+	connB, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+
+	_ = connB.SetReadDeadline(time.Now().Add(time.Second * 5))
+	_, err = connB.Read(make([]byte, 1))
+	require.Error(t, err)
+	connB.Close()
+
+	_, err = connA.Write([]byte("bar\n"))
+	require.NoError(t, err)
+
+	msg, err = readNextMsg()
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("bar")}, message.GetAllBytes(msg))
+	connA.Close()
+
+	// Once the first connection is closed a new one can take its place.
+	require.Eventually(t, func() bool {
+		connC, err := net.Dial("tcp", addr)
+		if err != nil {
+			return false
+		}
+		defer connC.Close()
+
+		if _, err = connC.Write([]byte("baz\n")); err != nil {
+			return false
+		}
+		msg, err := readNextMsg()
+		return err == nil && string(msg[0].AsBytes()) == "baz"
+	}, time.Second*10, time.Millisecond*100)
+}
+
+func TestSocketServerReadTimeout(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*20)
+	defer done()
+
+	rdr, addr := socketServerInputFromConf(t, `
+socket_server:
+  network: tcp
+  address: 127.0.0.1:0
+  read_timeout: 100ms
+`)
+
+	defer func() {
+		rdr.TriggerStopConsuming()
+		assert.NoError(t, rdr.WaitForClose(tCtx))
+	}()
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second * 5))
+	_, err = conn.Read(make([]byte, 1))
+	require.Error(t, err)
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		assert.False(t, netErr.Timeout(), "expected the server to close the connection")
+	}
+}
+
+func TestSocketServerMaxMessageSize(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*20)
+	defer done()
+
+	rdr, addr := socketServerInputFromConf(t, `
+socket_server:
+  network: tcp
+  address: 127.0.0.1:0
+  max_message_size: 5
+`)
+
+	defer func() {
+		rdr.TriggerStopConsuming()
+		assert.NoError(t, rdr.WaitForClose(tCtx))
+	}()
+
+	connA, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer connA.Close()
+
+	connB, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer connB.Close()
+
+	_, err = connB.Write([]byte("this is too long\n"))
+	require.NoError(t, err)
+
+	_ = connB.SetReadDeadline(time.Now().Add(time.Second * 5))
+	_, err = connB.Read(make([]byte, 1))
+	require.Error(t, err)
+
+	_, err = connA.Write([]byte("foo\n"))
+	require.NoError(t, err)
+
+	select {
+	case tran := <-rdr.TransactionChan():
+		require.NoError(t, tran.Ack(tCtx, nil))
+		assert.Equal(t, [][]byte{[]byte("foo")}, message.GetAllBytes(tran.Payload))
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out")
+	}
+}
+
+func TestSocketServerMaxMessageSizeLargeFrame(t *testing.T) {
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*20)
+	defer done()
+
+	rdr, addr := socketServerInputFromConf(t, `
+socket_server:
+  network: tcp
+  address: 127.0.0.1:0
+  max_message_size: 5
+  scanner:
+    lines:
+      max_buffer_size: 1073741824
+`)
+
+	defer func() {
+		rdr.TriggerStopConsuming()
+		assert.NoError(t, rdr.WaitForClose(tCtx))
+	}()
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("foo\n"))
+	require.NoError(t, err)
+
+	select {
+	case tran := <-rdr.TransactionChan():
+		require.NoError(t, tran.Ack(tCtx, nil))
+		assert.Equal(t, [][]byte{[]byte("foo")}, message.GetAllBytes(tran.Payload))
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out")
+	}
+
+	// A frame far larger than the limit without a delimiter must be rejected
+	// whilst it is being read, rather than buffered until the delimiter.
+	chunk := bytes.Repeat([]byte("a"), 1024*1024)
+	_ = conn.SetWriteDeadline(time.Now().Add(time.Second * 10))
+
+	var written int
+	for written < 256*1024*1024 {
+		var n int
+		if n, err = conn.Write(chunk); err != nil {
+			break
+		}
+		written += n
+	}
+	require.Error(t, err)
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		assert.False(t, netErr.Timeout(), "expected the server to close the connection")
+	}
+	assert.Less(t, written, 256*1024*1024)
+}
@@ -4,6 +4,7 @@ package io
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"net"
@@ -212,6 +213,84 @@ socket:
 	conn.Close()
 }
 
+func TestSocketInputLifecycleEvents(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*20)
+	defer done()
+
+	tmpDir := t.TempDir()
+
+	ln, err := net.Listen("unix", filepath.Join(tmpDir, "benthos.sock"))
+	require.NoError(t, err)
+	defer ln.Close()
+
+	rdr := inputFromConf(t, `
+socket:
+  network: %v
+  address: %v
+  emit_lifecycle_events: true
+`, ln.Addr().Network(), ln.Addr().String())
+
+	defer func() {
+		rdr.TriggerStopConsuming()
+		assert.NoError(t, rdr.WaitForClose(ctx))
+	}()
+
+	conn, err := ln.Accept()
+	require.NoError(t, err)
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_ = conn.SetWriteDeadline(time.Now().Add(time.Second * 5))
+		_, cerr := conn.Write([]byte("foo\n"))
+		assert.NoError(t, cerr)
+		conn.Close()
+
+		conn, cerr = ln.Accept()
+		if !assert.NoError(t, cerr) {
+			return
+		}
+		_, cerr = conn.Write([]byte("bar\n"))
+		assert.NoError(t, cerr)
+	}()
+
+	type event struct {
+		Event   string
+		Content string
+	}
+
+	var events []event
+	for len(events) < 5 {
+		select {
+		case tran := <-rdr.TransactionChan():
+			for _, p := range tran.Payload {
+				e, _ := p.MetaGetMut("socket_event")
+				eStr, _ := e.(string)
+				if eStr != "" {
+					addr, _ := p.MetaGetMut("socket_remote_address")
+					assert.Equal(t, ln.Addr().String(), addr)
+				}
+				events = append(events, event{Event: eStr, Content: string(p.AsBytes())})
+			}
+			require.NoError(t, tran.Ack(ctx, nil))
+		case <-time.After(time.Second * 5):
+			t.Fatalf("timed out with events: %v", events)
+		}
+	}
+
+	assert.Equal(t, []event{
+		{Event: "connected"},
+		{Content: "foo"},
+		{Event: "disconnected"},
+		{Event: "connected"},
+		{Content: "bar"},
+	}, events)
+
+	wg.Wait()
+	conn.Close()
+}
+
 func TestSocketInputMultipart(t *testing.T) {
 	ctx, done := context.WithTimeout(context.Background(), time.Second*20)
 	defer done()
@@ -1021,3 +1100,59 @@ socket:
 	wg.Wait()
 	conn.Close()
 }
+
+func TestTCPSocketInputTLS(t *testing.T) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*20)
+	defer done()
+
+	cert, err := createSelfSignedCertificate()
+	require.NoError(t, err)
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		NextProtos:   []string{"bar"},
+	})
+	require.NoError(t, err)
+	defer ln.Close()
+
+	rdr := inputFromConf(t, `
+socket:
+  network: tcp
+  address: %v
+  tls:
+    enabled: true
+    skip_cert_verify: true
+    server_name: example.com
+    alpn_protocols: [ foo, bar ]
+`, ln.Addr().String())
+
+	defer func() {
+		rdr.TriggerStopConsuming()
+		assert.NoError(t, rdr.WaitForClose(ctx))
+	}()
+
+	conn, err := ln.Accept()
+	require.NoError(t, err)
+	defer conn.Close()
+
+	tlsConn, ok := conn.(*tls.Conn)
+	require.True(t, ok)
+
+	_ = tlsConn.SetDeadline(time.Now().Add(time.Second * 5))
+	require.NoError(t, tlsConn.HandshakeContext(ctx))
+
+	state := tlsConn.ConnectionState()
+	assert.Equal(t, "example.com", state.ServerName)
+	assert.Equal(t, "bar", state.NegotiatedProtocol)
+
+	_, err = tlsConn.Write([]byte("foo\n"))
+	require.NoError(t, err)
+
+	select {
+	case tran := <-rdr.TransactionChan():
+		assert.Equal(t, [][]byte{[]byte("foo")}, message.GetAllBytes(tran.Payload))
+		require.NoError(t, tran.Ack(ctx, nil))
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out")
+	}
+}
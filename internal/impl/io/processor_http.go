@@ -4,8 +4,12 @@ package io
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
+	"net/http/httptrace"
+	"sync"
+	"time"
 
 	"github.com/redpanda-data/benthos/v4/internal/httpclient"
 	"github.com/redpanda-data/benthos/v4/public/service"
@@ -33,10 +37,19 @@ When a request returns a response code within the `+"`drop_on`"+` field it will
 
 == Add metadata
 
-If the request returns an error response code this processor sets a metadata field `+"`http_status_code`"+` on the resulting message.
+This processor sets a metadata field `+"`http_status_code`"+` on resulting messages, including those resulting from an error response code and those with a response code listed in `+"`successful_on`"+`.
 
 Use the field `+"`extract_headers`"+` to specify rules for which other headers should be copied into the resulting message from the response.
 
+When the field `+"`capture_timing`"+` is enabled the following metadata fields are added to each resulting message, with values in milliseconds:
+
+- http_duration_ms: The total time taken to perform the request, including any retries.
+- http_dns_ms: The time taken to resolve the host of the final request attempt.
+- http_connect_ms: The time taken to establish a connection for the final request attempt.
+- http_tls_ms: The time taken to perform the TLS handshake of the final request attempt.
+
+The DNS, connect and TLS fields are only added when the respective phase took place, which is not the case when an existing connection is reused.
+
 == Error handling
 
 When all retry attempts for a message are exhausted the processor cancels the attempt. These failed messages will continue through the pipeline unchanged, but can be dropped or placed in a dead letter queue according to your config, you can read about xref:configuration:error_handling.adoc[these patterns].`).
@@ -59,7 +72,8 @@ pipeline:
 		).
 		Field(httpclient.ConfigField("POST", false,
 			service.NewBoolField("batch_as_multipart").Description("Send message batches as a single request using https://www.w3.org/Protocols/rfc1341/7_2_Multipart.html[RFC1341^].").Advanced().Default(false),
-			service.NewBoolField("parallel").Description("When processing batched messages, whether to send messages of the batch in parallel, otherwise they are sent serially.").Default(false)),
+			service.NewBoolField("parallel").Description("When processing batched messages, whether to send messages of the batch in parallel, otherwise they are sent serially.").Default(false),
+			service.NewBoolField("capture_timing").Description("Whether to add metadata to resulting messages describing the timing of requests. Refer to the metadata section above for details.").Advanced().Default(false).Version("4.44.0")),
 		)
 }
 
@@ -75,11 +89,12 @@ func init() {
 }
 
 type httpProc struct {
-	client      *httpclient.Client
-	asMultipart bool
-	parallel    bool
-	rawURL      string
-	log         *service.Logger
+	client        *httpclient.Client
+	asMultipart   bool
+	parallel      bool
+	captureTiming bool
+	rawURL        string
+	log           *service.Logger
 }
 
 func newHTTPProcFromParsed(conf *service.ParsedConfig, mgr *service.Resources) (*httpProc, error) {
@@ -98,13 +113,19 @@ func newHTTPProcFromParsed(conf *service.ParsedConfig, mgr *service.Resources) (
 		return nil, err
 	}
 
+	captureTiming, err := conf.FieldBool("capture_timing")
+	if err != nil {
+		return nil, err
+	}
+
 	rawURL, _ := conf.FieldString("url")
 
 	g := &httpProc{
-		rawURL:      rawURL,
-		log:         mgr.Logger(),
-		asMultipart: asMultipart,
-		parallel:    parallel,
+		rawURL:        rawURL,
+		log:           mgr.Logger(),
+		asMultipart:   asMultipart,
+		parallel:      parallel,
+		captureTiming: captureTiming,
 	}
 	if g.client, err = httpclient.NewClientFromOldConfig(oldConf, mgr); err != nil {
 		return nil, err
@@ -112,12 +133,103 @@ func newHTTPProcFromParsed(conf *service.ParsedConfig, mgr *service.Resources) (
 	return g, nil
 }
 
+// httpTiming records the duration of the phases of a request via an
+// httptrace.ClientTrace, phases of previous attempts are reset when a retry
+// obtains a new connection.
+type httpTiming struct {
+	mut sync.Mutex
+
+	dnsStart, dnsDone         time.Time
+	connectStart, connectDone time.Time
+	tlsStart, tlsDone         time.Time
+
+	duration time.Duration
+}
+
+func (t *httpTiming) trace() *httptrace.ClientTrace {
+	now := func(target *time.Time) {
+		t.mut.Lock()
+		*target = time.Now()
+		t.mut.Unlock()
+	}
+	return &httptrace.ClientTrace{
+		GetConn: func(string) {
+			t.mut.Lock()
+			t.dnsStart, t.dnsDone = time.Time{}, time.Time{}
+			t.connectStart, t.connectDone = time.Time{}, time.Time{}
+			t.tlsStart, t.tlsDone = time.Time{}, time.Time{}
+			t.mut.Unlock()
+		},
+		DNSStart: func(httptrace.DNSStartInfo) { now(&t.dnsStart) },
+		DNSDone:  func(httptrace.DNSDoneInfo) { now(&t.dnsDone) },
+		ConnectStart: func(string, string) {
+			t.mut.Lock()
+			// Multiple connections may be attempted in parallel, in which case
+			// we measure from the first.
+			if t.connectStart.IsZero() {
+				t.connectStart = time.Now()
+			}
+			t.mut.Unlock()
+		},
+		ConnectDone: func(_, _ string, err error) {
+			if err == nil {
+				now(&t.connectDone)
+			}
+		},
+		TLSHandshakeStart: func() { now(&t.tlsStart) },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { now(&t.tlsDone) },
+	}
+}
+
+func (t *httpTiming) annotate(p *service.Message) {
+	if t == nil {
+		return
+	}
+
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	p.MetaSetMut("http_duration_ms", t.duration.Milliseconds())
+	for _, phase := range []struct {
+		key         string
+		start, done time.Time
+	}{
+		{key: "http_dns_ms", start: t.dnsStart, done: t.dnsDone},
+		{key: "http_connect_ms", start: t.connectStart, done: t.connectDone},
+		{key: "http_tls_ms", start: t.tlsStart, done: t.tlsDone},
+	} {
+		if !phase.start.IsZero() && !phase.done.IsZero() {
+			p.MetaSetMut(phase.key, phase.done.Sub(phase.start).Milliseconds())
+		}
+	}
+}
+
+// send performs a request with the client, and when capture_timing is enabled
+// returns the timing of the request.
+func (h *httpProc) send(msg service.MessageBatch) (service.MessageBatch, *httpTiming, error) {
+	if !h.captureTiming {
+		res, err := h.client.Send(context.Background(), msg)
+		return res, nil, err
+	}
+
+	timing := &httpTiming{}
+	ctx := httptrace.WithClientTrace(context.Background(), timing.trace())
+
+	startedAt := time.Now()
+	res, err := h.client.Send(ctx, msg)
+
+	timing.mut.Lock()
+	timing.duration = time.Since(startedAt)
+	timing.mut.Unlock()
+	return res, timing, err
+}
+
 func (h *httpProc) ProcessBatch(ctx context.Context, msg service.MessageBatch) ([]service.MessageBatch, error) {
 	var responseMsg service.MessageBatch
 
 	if h.asMultipart || len(msg) == 1 {
 		// Easy, just do a single request.
-		resultMsg, err := h.client.Send(context.Background(), msg)
+		resultMsg, timing, err := h.send(msg)
 		if err != nil {
 			var code int
 			var hErr httpclient.ErrUnexpectedHTTPRes
@@ -130,6 +242,7 @@ func (h *httpProc) ProcessBatch(ctx context.Context, msg service.MessageBatch) (
 				if code > 0 {
 					p.MetaSetMut("http_status_code", code)
 				}
+				timing.annotate(p)
 				p.SetError(err)
 			}
 		} else {
@@ -149,13 +262,14 @@ func (h *httpProc) ProcessBatch(ctx context.Context, msg service.MessageBatch) (
 					parts[i].MetaSetMut(k, v)
 					return nil
 				})
+				timing.annotate(parts[i])
 			}
 			responseMsg = parts
 		}
 	} else if !h.parallel {
 		for _, p := range msg {
 			tmpMsg := service.MessageBatch{p}
-			result, err := h.client.Send(context.Background(), tmpMsg)
+			result, timing, err := h.send(tmpMsg)
 			if err != nil {
 				h.log.Errorf("HTTP request to '%v' failed: %v", h.rawURL, err)
 
@@ -164,6 +278,7 @@ func (h *httpProc) ProcessBatch(ctx context.Context, msg service.MessageBatch) (
 				if ok := errors.As(err, &hErr); ok {
 					errPart.MetaSetMut("http_status_code", hErr.Code)
 				}
+				timing.annotate(errPart)
 				errPart.SetError(err)
 				responseMsg = append(responseMsg, errPart)
 			}
@@ -179,6 +294,7 @@ func (h *httpProc) ProcessBatch(ctx context.Context, msg service.MessageBatch) (
 					tmpPart.MetaSetMut(k, v)
 					return nil
 				})
+				timing.annotate(tmpPart)
 				responseMsg = append(responseMsg, tmpPart)
 			}
 		}
@@ -194,7 +310,7 @@ func (h *httpProc) ProcessBatch(ctx context.Context, msg service.MessageBatch) (
 			go func() {
 				for index := range reqChan {
 					tmpMsg := service.MessageBatch{msg[index]}
-					result, err := h.client.Send(context.Background(), tmpMsg)
+					result, timing, err := h.send(tmpMsg)
 					if err == nil && len(result) != 1 {
 						err = fmt.Errorf("unexpected response size: %v", len(result))
 					}
@@ -212,6 +328,7 @@ func (h *httpProc) ProcessBatch(ctx context.Context, msg service.MessageBatch) (
 						}
 						results[index].SetError(err)
 					}
+					timing.annotate(results[index])
 					resChan <- err
 				}
 			}()
@@ -434,3 +434,94 @@ http:
 		}
 	}
 }
+
+func TestHTTPClientCaptureTiming(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("foobar"))
+	}))
+	defer ts.Close()
+
+	conf := parseYAMLProcConf(t, `
+http:
+  url: %v/testpost
+  capture_timing: true
+  tls:
+    enabled: true
+    skip_cert_verify: true
+`, ts.URL)
+
+	h, err := mock.NewManager().NewProcessor(conf)
+	require.NoError(t, err)
+
+	msgs, res := h.ProcessBatch(context.Background(), message.QuickBatch([][]byte{[]byte("test")}))
+	require.NoError(t, res)
+	require.Len(t, msgs, 1)
+	require.Equal(t, 1, msgs[0].Len())
+
+	part := msgs[0].Get(0)
+	assert.Equal(t, "foobar", string(part.AsBytes()))
+	for _, k := range []string{"http_duration_ms", "http_connect_ms", "http_tls_ms"} {
+		v, exists := part.MetaGetMut(k)
+		require.True(t, exists, k)
+		assert.IsType(t, int64(0), v, k)
+	}
+
+	// The server address is an IP and therefore no lookup is performed.
+	_, exists := part.MetaGetMut("http_dns_ms")
+	assert.False(t, exists)
+
+	require.NoError(t, h.Close(context.Background()))
+}
+
+func TestHTTPClientCaptureTimingDisabled(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("foobar"))
+	}))
+	defer ts.Close()
+
+	conf := parseYAMLProcConf(t, `
+http:
+  url: %v/testpost
+`, ts.URL)
+
+	h, err := mock.NewManager().NewProcessor(conf)
+	require.NoError(t, err)
+
+	msgs, res := h.ProcessBatch(context.Background(), message.QuickBatch([][]byte{[]byte("test")}))
+	require.NoError(t, res)
+	require.Len(t, msgs, 1)
+
+	_, exists := msgs[0].Get(0).MetaGetMut("http_duration_ms")
+	assert.False(t, exists)
+
+	require.NoError(t, h.Close(context.Background()))
+}
+
+func TestHTTPClientSuccessfulOn404(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not found", http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	conf := parseYAMLProcConf(t, `
+http:
+  url: %v/testpost
+  retry_period: 1ms
+  successful_on: [ 404 ]
+`, ts.URL)
+
+	h, err := mock.NewManager().NewProcessor(conf)
+	require.NoError(t, err)
+
+	msgs, res := h.ProcessBatch(context.Background(), message.QuickBatch([][]byte{[]byte("test")}))
+	require.NoError(t, res)
+	require.Len(t, msgs, 1)
+	require.Equal(t, 1, msgs[0].Len())
+
+	part := msgs[0].Get(0)
+	assert.NoError(t, part.ErrorGet())
+	assert.Equal(t, "not found\n", string(part.AsBytes()))
+	assert.Equal(t, "404", part.MetaGetStr("http_status_code"))
+
+	require.NoError(t, h.Close(context.Background()))
+}
@@ -0,0 +1,154 @@
+// Copyright 2025 Redpanda Data, Inc.
+
+package io
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+)
+
+var (
+	proxyProtocolV1Sig = []byte("PROXY ")
+	proxyProtocolV2Sig = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+	errNoProxyProtocolHeader = errors.New("connection did not begin with a PROXY protocol header")
+)
+
+// The maximum length of a v1 header line, including the trailing CRLF, as
+// defined by the PROXY protocol specification.
+const proxyProtocolV1MaxLen = 107
+
+// readProxyProtocolHeader attempts to consume a PROXY protocol v1 or v2 header
+// from the beginning of a stream. If the stream does not begin with a header
+// then errNoProxyProtocolHeader is returned and nothing is consumed.
+//
+// A nil address is returned along with a nil error when a header was consumed
+// but it does not describe a source address, which is the case for LOCAL (v2)
+// and UNKNOWN (v1) connections such as health checks from the proxy itself.
+func readProxyProtocolHeader(r *bufio.Reader) (*net.TCPAddr, error) {
+	first, err := r.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+
+	switch first[0] {
+	case proxyProtocolV1Sig[0]:
+		sig, err := r.Peek(len(proxyProtocolV1Sig))
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		if !bytes.Equal(sig, proxyProtocolV1Sig) {
+			return nil, errNoProxyProtocolHeader
+		}
+		return readProxyProtocolV1(r)
+	case proxyProtocolV2Sig[0]:
+		sig, err := r.Peek(len(proxyProtocolV2Sig))
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		if !bytes.Equal(sig, proxyProtocolV2Sig) {
+			return nil, errNoProxyProtocolHeader
+		}
+		return readProxyProtocolV2(r)
+	}
+	return nil, errNoProxyProtocolHeader
+}
+
+func readProxyProtocolV1(r *bufio.Reader) (*net.TCPAddr, error) {
+	var line []byte
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("reading PROXY protocol v1 header: %w", err)
+		}
+		line = append(line, b)
+		if b == '\n' {
+			break
+		}
+		if len(line) >= proxyProtocolV1MaxLen {
+			return nil, errors.New("PROXY protocol v1 header exceeds maximum length")
+		}
+	}
+	if !bytes.HasSuffix(line, []byte("\r\n")) {
+		return nil, errors.New("PROXY protocol v1 header is not terminated with CRLF")
+	}
+
+	fields := strings.Split(string(line[:len(line)-2]), " ")
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return nil, nil
+	}
+	if len(fields) != 6 {
+		return nil, fmt.Errorf("PROXY protocol v1 header has %v fields, expected 6", len(fields))
+	}
+
+	switch fields[1] {
+	case "TCP4", "TCP6":
+	default:
+		return nil, fmt.Errorf("PROXY protocol v1 header has unsupported protocol: %v", fields[1])
+	}
+
+	ip := net.ParseIP(fields[2])
+	if ip == nil {
+		return nil, fmt.Errorf("PROXY protocol v1 header has invalid source address: %v", fields[2])
+	}
+	if (ip.To4() != nil) != (fields[1] == "TCP4") {
+		return nil, fmt.Errorf("PROXY protocol v1 header source address %v does not match protocol %v", fields[2], fields[1])
+	}
+
+	port, err := strconv.ParseUint(fields[4], 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("PROXY protocol v1 header has invalid source port: %v", fields[4])
+	}
+	return &net.TCPAddr{IP: ip, Port: int(port)}, nil
+}
+
+func readProxyProtocolV2(r *bufio.Reader) (*net.TCPAddr, error) {
+	var header [16]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, fmt.Errorf("reading PROXY protocol v2 header: %w", err)
+	}
+
+	if version := header[12] >> 4; version != 2 {
+		return nil, fmt.Errorf("PROXY protocol v2 header has unsupported version: %v", version)
+	}
+
+	payload := make([]byte, binary.BigEndian.Uint16(header[14:16]))
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, fmt.Errorf("reading PROXY protocol v2 addresses: %w", err)
+	}
+
+	switch command := header[12] & 0x0F; command {
+	case 0x0: // LOCAL
+		return nil, nil
+	case 0x1: // PROXY
+	default:
+		return nil, fmt.Errorf("PROXY protocol v2 header has unsupported command: %v", command)
+	}
+
+	var ipLen int
+	switch family := header[13] >> 4; family {
+	case 0x1: // AF_INET
+		ipLen = net.IPv4len
+	case 0x2: // AF_INET6
+		ipLen = net.IPv6len
+	default:
+		// Unix sockets and unspecified families carry no useful source, the
+		// address block is still consumed.
+		return nil, nil
+	}
+
+	if len(payload) < 2*ipLen+4 {
+		return nil, fmt.Errorf("PROXY protocol v2 address block is too short: %v bytes", len(payload))
+	}
+	return &net.TCPAddr{
+		IP:   net.IP(bytes.Clone(payload[:ipLen])),
+		Port: int(binary.BigEndian.Uint16(payload[2*ipLen:])),
+	}, nil
+}
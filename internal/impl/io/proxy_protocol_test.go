@@ -0,0 +1,127 @@
+// Copyright 2025 Redpanda Data, Inc.
+
+package io
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadProxyProtocolHeader(t *testing.T) {
+	v2Sig := "\r\n\r\n\x00\r\nQUIT\n"
+
+	tests := []struct {
+		name      string
+		input     string
+		addr      string
+		remaining string
+		err       string
+	}{
+		{
+			name:      "v1 tcp4",
+			input:     "PROXY TCP4 192.168.0.1 192.168.0.11 56324 443\r\nfoo",
+			addr:      "192.168.0.1:56324",
+			remaining: "foo",
+		},
+		{
+			name:      "v1 tcp6",
+			input:     "PROXY TCP6 2001:db8::1 2001:db8::2 4000 443\r\nfoo",
+			addr:      "[2001:db8::1]:4000",
+			remaining: "foo",
+		},
+		{
+			name:      "v1 unknown",
+			input:     "PROXY UNKNOWN\r\nfoo",
+			remaining: "foo",
+		},
+		{
+			name:  "v1 mismatched family",
+			input: "PROXY TCP4 2001:db8::1 2001:db8::2 4000 443\r\nfoo",
+			err:   "PROXY protocol v1 header source address 2001:db8::1 does not match protocol TCP4",
+		},
+		{
+			name:  "v1 bad port",
+			input: "PROXY TCP4 192.168.0.1 192.168.0.11 nope 443\r\nfoo",
+			err:   "PROXY protocol v1 header has invalid source port: nope",
+		},
+		{
+			name:  "v1 missing crlf",
+			input: "PROXY TCP4 192.168.0.1 192.168.0.11 56324 443\nfoo",
+			err:   "PROXY protocol v1 header is not terminated with CRLF",
+		},
+		{
+			name:  "v1 too long",
+			input: "PROXY TCP4 " + string(bytes.Repeat([]byte("1"), 120)) + "\r\n",
+			err:   "PROXY protocol v1 header exceeds maximum length",
+		},
+		{
+			name:      "v2 tcp4",
+			input:     v2Sig + "\x21\x11\x00\x0c" + "\x0a\x00\x00\x05\x0a\x00\x00\x06\x1f\x90\x01\xbb" + "foo",
+			addr:      "10.0.0.5:8080",
+			remaining: "foo",
+		},
+		{
+			name:      "v2 tcp4 with tlvs",
+			input:     v2Sig + "\x21\x11\x00\x0f" + "\x0a\x00\x00\x05\x0a\x00\x00\x06\x1f\x90\x01\xbb" + "\x04\x00\x00" + "foo",
+			addr:      "10.0.0.5:8080",
+			remaining: "foo",
+		},
+		{
+			name:      "v2 local",
+			input:     v2Sig + "\x20\x00\x00\x00" + "foo",
+			remaining: "foo",
+		},
+		{
+			name:  "v2 bad version",
+			input: v2Sig + "\x11\x11\x00\x00" + "foo",
+			err:   "PROXY protocol v2 header has unsupported version: 1",
+		},
+		{
+			name:  "v2 short addresses",
+			input: v2Sig + "\x21\x11\x00\x04" + "\x0a\x00\x00\x05" + "foo",
+			err:   "PROXY protocol v2 address block is too short: 4 bytes",
+		},
+		{
+			name:      "no header",
+			input:     "foo bar",
+			err:       errNoProxyProtocolHeader.Error(),
+			remaining: "foo bar",
+		},
+		{
+			name:      "partial v1 signature",
+			input:     "PROX",
+			err:       errNoProxyProtocolHeader.Error(),
+			remaining: "PROX",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rdr := bufio.NewReader(bytes.NewReader([]byte(test.input)))
+
+			addr, err := readProxyProtocolHeader(rdr)
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+			} else {
+				require.NoError(t, err)
+				if test.addr == "" {
+					assert.Nil(t, addr)
+				} else {
+					require.NotNil(t, addr)
+					assert.Equal(t, test.addr, addr.String())
+				}
+			}
+
+			if test.remaining != "" {
+				remaining, err := io.ReadAll(rdr)
+				require.NoError(t, err)
+				assert.Equal(t, test.remaining, string(remaining))
+			}
+		})
+	}
+}
@@ -0,0 +1,314 @@
+// Copyright 2025 Redpanda Data, Inc.
+
+package pure
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/redpanda-data/benthos/v4/internal/bloblang/query"
+	"github.com/redpanda-data/benthos/v4/internal/value"
+	"github.com/redpanda-data/benthos/v4/public/bloblang"
+)
+
+// archiveFiles extracts the contents of an object of file paths to contents,
+// returning the paths in sorted order so that archives are deterministic.
+func archiveFiles(m map[string]any) ([]string, map[string][]byte, error) {
+	paths := make([]string, 0, len(m))
+	contents := make(map[string][]byte, len(m))
+	for k, v := range m {
+		b, err := value.IGetBytes(v)
+		if err != nil {
+			return nil, nil, fmt.Errorf("file %v: %w", k, err)
+		}
+		paths = append(paths, k)
+		contents[k] = b
+	}
+	sort.Strings(paths)
+	return paths, contents, nil
+}
+
+type archiveLimits struct {
+	maxEntries int64
+	maxSize    int64
+
+	entries int64
+	size    int64
+}
+
+func archiveLimitsFromArgs(args *bloblang.ParsedParams) (archiveLimits, error) {
+	var l archiveLimits
+	var err error
+	if l.maxEntries, err = args.GetInt64("max_entries"); err != nil {
+		return l, err
+	}
+	if l.maxSize, err = args.GetInt64("max_size"); err != nil {
+		return l, err
+	}
+	return l, nil
+}
+
+// readEntry reads the contents of an archive entry whilst enforcing the limits
+// of the archive as a whole. The declared size of an entry is not trusted.
+func (l *archiveLimits) readEntry(r io.Reader) ([]byte, error) {
+	if l.entries++; l.entries > l.maxEntries {
+		return nil, fmt.Errorf("archive exceeds max_entries of %v", l.maxEntries)
+	}
+	b, err := io.ReadAll(io.LimitReader(r, l.maxSize-l.size+1))
+	if err != nil {
+		return nil, err
+	}
+	if l.size += int64(len(b)); l.size > l.maxSize {
+		return nil, fmt.Errorf("archive exceeds max_size of %v bytes", l.maxSize)
+	}
+	return b, nil
+}
+
+func archiveLimitParams(spec *bloblang.PluginSpec) *bloblang.PluginSpec {
+	return spec.
+		Param(bloblang.NewInt64Param("max_entries").Description("The maximum number of files the archive may contain, an error is returned if this is exceeded.").Default(1000)).
+		Param(bloblang.NewInt64Param("max_size").Description("The maximum total size in bytes of the extracted files, an error is returned if this is exceeded.").Default(100 * 1024 * 1024))
+}
+
+type tarFile struct {
+	contents []byte
+	mode     int64
+}
+
+// tarFiles extracts the contents of an object of file paths to either contents
+// or objects containing contents and a mode, returning the paths in sorted
+// order so that archives are deterministic.
+func tarFiles(m map[string]any) ([]string, map[string]tarFile, error) {
+	paths := make([]string, 0, len(m))
+	files := make(map[string]tarFile, len(m))
+	for k, v := range m {
+		f := tarFile{mode: 0o644}
+		var err error
+		if obj, ok := v.(map[string]any); ok {
+			if f.contents, err = value.IGetBytes(obj["contents"]); err != nil {
+				return nil, nil, fmt.Errorf("file %v: field contents: %w", k, err)
+			}
+			if modeV, exists := obj["mode"]; exists && modeV != nil {
+				if f.mode, err = value.IGetInt(modeV); err != nil {
+					return nil, nil, fmt.Errorf("file %v: field mode: %w", k, err)
+				}
+			}
+		} else if f.contents, err = value.IGetBytes(v); err != nil {
+			return nil, nil, fmt.Errorf("file %v: %w", k, err)
+		}
+		paths = append(paths, k)
+		files[k] = f
+	}
+	sort.Strings(paths)
+	return paths, files, nil
+}
+
+func init() {
+	if err := bloblang.RegisterMethodV2("zip_archive",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryEncoding).
+			Description(`Creates a ZIP archive from an object of file paths to their contents, where each content value is a string or byte array, and returns the archive as a byte array. Files are compressed with the deflate algorithm and added in lexicographical order of their paths.`).
+			Example("", `root = this.zip_archive().unzip().map_each(file -> file.value.string())`,
+				[2]string{
+					`{"hello.txt":"hello world","nested/data.json":"{\"foo\":\"bar\"}"}`,
+					`{"hello.txt":"hello world","nested/data.json":"{\"foo\":\"bar\"}"}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return bloblang.ObjectMethod(func(m map[string]any) (any, error) {
+				paths, contents, err := archiveFiles(m)
+				if err != nil {
+					return nil, err
+				}
+				var buf bytes.Buffer
+				w := zip.NewWriter(&buf)
+				for _, p := range paths {
+					fw, err := w.CreateHeader(&zip.FileHeader{Name: p, Method: zip.Deflate})
+					if err != nil {
+						return nil, fmt.Errorf("file %v: %w", p, err)
+					}
+					if _, err := fw.Write(contents[p]); err != nil {
+						return nil, fmt.Errorf("file %v: %w", p, err)
+					}
+				}
+				if err := w.Close(); err != nil {
+					return nil, err
+				}
+				return buf.Bytes(), nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("unzip",
+		archiveLimitParams(bloblang.NewPluginSpec().
+			Category(query.MethodCategoryEncoding).
+			Description(`Extracts the files of a ZIP archive into an object of file paths to their contents as byte arrays. Directory entries are omitted. In order to protect against maliciously crafted archives (zip bombs) the number of files and their total extracted size are limited, and an error is returned if either limit is exceeded.`)).
+			Example("", `root = this.archive.decode("base64").unzip().map_each(file -> file.value.string())`,
+				[2]string{
+					`{"archive":"UEsDBBQACAAIAAAAAAAAAAAAAAAAAAAAAAAJAAAAaGVsbG8udHh0AAsA9P9oZWxsbyB3b3JsZAMAUEsHCIURSg0SAAAACwAAAFBLAQIUABQACAAIAAAAAACFEUoNEgAAAAsAAAAJAAAAAAAAAAAAAAAAAAAAAABoZWxsby50eHRQSwUGAAAAAAEAAQA3AAAASQAAAAAA"}`,
+					`{"hello.txt":"hello world"}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			limits, err := archiveLimitsFromArgs(args)
+			if err != nil {
+				return nil, err
+			}
+			return bloblang.BytesMethod(func(b []byte) (any, error) {
+				r, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+				if err != nil {
+					return nil, fmt.Errorf("failed to read zip archive: %w", err)
+				}
+				l := limits
+				res := map[string]any{}
+				for _, f := range r.File {
+					if f.FileInfo().IsDir() {
+						continue
+					}
+					rc, err := f.Open()
+					if err != nil {
+						return nil, fmt.Errorf("file %v: %w", f.Name, err)
+					}
+					contents, err := l.readEntry(rc)
+					_ = rc.Close()
+					if err != nil {
+						return nil, fmt.Errorf("file %v: %w", f.Name, err)
+					}
+					res[f.Name] = contents
+				}
+				return res, nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("tar_archive",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryEncoding).
+			Description(`Creates a tar archive from an object of file paths to their contents and returns the archive as a byte array. Each value of the object is either a string or byte array of the file contents, or an object containing the field `+"`contents`"+` and optionally the field `+"`mode`"+`, the numeric permission bits of the file (defaulting to `+"`420`"+`, which is `+"`0644`"+` in octal). Files are added in lexicographical order of their paths.`).
+			Param(bloblang.NewBoolParam("gzip").Description("Whether to compress the archive with gzip, producing a `.tar.gz` archive.").Default(false)).
+			Example("", `root = this.tar_archive(gzip: true).untar().map_each(file -> file.value.string())`,
+				[2]string{
+					`{"hello.txt":"hello world","bin/run.sh":{"contents":"echo hi","mode":493}}`,
+					`{"bin/run.sh":"echo hi","hello.txt":"hello world"}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			useGzip, err := args.GetBool("gzip")
+			if err != nil {
+				return nil, err
+			}
+			return bloblang.ObjectMethod(func(m map[string]any) (any, error) {
+				paths, files, err := tarFiles(m)
+				if err != nil {
+					return nil, err
+				}
+
+				var buf bytes.Buffer
+				var gw *gzip.Writer
+				var tw *tar.Writer
+				if useGzip {
+					gw = gzip.NewWriter(&buf)
+					tw = tar.NewWriter(gw)
+				} else {
+					tw = tar.NewWriter(&buf)
+				}
+				for _, p := range paths {
+					f := files[p]
+					if err := tw.WriteHeader(&tar.Header{
+						Typeflag: tar.TypeReg,
+						Name:     p,
+						Mode:     f.mode,
+						Size:     int64(len(f.contents)),
+					}); err != nil {
+						return nil, fmt.Errorf("file %v: %w", p, err)
+					}
+					if _, err := tw.Write(f.contents); err != nil {
+						return nil, fmt.Errorf("file %v: %w", p, err)
+					}
+				}
+				if err := tw.Close(); err != nil {
+					return nil, err
+				}
+				if gw != nil {
+					if err := gw.Close(); err != nil {
+						return nil, err
+					}
+				}
+				return buf.Bytes(), nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("untar",
+		archiveLimitParams(bloblang.NewPluginSpec().
+			Category(query.MethodCategoryEncoding).
+			Description(`Extracts the files of a tar archive into an object of file paths to their contents as byte arrays. Archives compressed with gzip (`+"`.tar.gz`"+`) are detected and decompressed automatically. Only regular files are extracted, other entries such as directories and links are omitted. In order to protect against maliciously crafted archives the number of files and their total extracted size are limited, and an error is returned if either limit is exceeded.`).
+			Param(bloblang.NewBoolParam("preserve_modes").Description("Whether to extract each file as an object containing the fields `contents` and `mode` (the numeric permission bits of the file), rather than only its contents.").Default(false))).
+			Example("", `root = this.tar_archive().untar(preserve_modes: true).map_each(file -> file.value.mode)`,
+				[2]string{
+					`{"hello.txt":"hello world","bin/run.sh":{"contents":"echo hi","mode":493}}`,
+					`{"bin/run.sh":493,"hello.txt":420}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			limits, err := archiveLimitsFromArgs(args)
+			if err != nil {
+				return nil, err
+			}
+			preserveModes, err := args.GetBool("preserve_modes")
+			if err != nil {
+				return nil, err
+			}
+			return bloblang.BytesMethod(func(b []byte) (any, error) {
+				var r io.Reader = bytes.NewReader(b)
+				if len(b) >= 2 && b[0] == 0x1f && b[1] == 0x8b {
+					gr, err := gzip.NewReader(r)
+					if err != nil {
+						return nil, fmt.Errorf("failed to read gzip stream: %w", err)
+					}
+					defer gr.Close()
+					r = gr
+				}
+
+				tr := tar.NewReader(r)
+				l := limits
+				res := map[string]any{}
+				for {
+					h, err := tr.Next()
+					if errors.Is(err, io.EOF) {
+						break
+					}
+					if err != nil {
+						return nil, fmt.Errorf("failed to read tar archive: %w", err)
+					}
+					if !h.FileInfo().Mode().IsRegular() {
+						continue
+					}
+					contents, err := l.readEntry(tr)
+					if err != nil {
+						return nil, fmt.Errorf("file %v: %w", h.Name, err)
+					}
+					if preserveModes {
+						res[h.Name] = map[string]any{
+							"contents": contents,
+							"mode":     h.Mode,
+						}
+					} else {
+						res[h.Name] = contents
+					}
+				}
+				return res, nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+}
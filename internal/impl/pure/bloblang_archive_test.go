@@ -0,0 +1,176 @@
+// Copyright 2025 Redpanda Data, Inc.
+
+package pure
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/redpanda-data/benthos/v4/public/bloblang"
+)
+
+func TestZipArchive(t *testing.T) {
+	exec, err := bloblang.Parse(`root = this.zip_archive()`)
+	require.NoError(t, err)
+
+	res, err := exec.Query(map[string]any{
+		"b.txt":     "bar",
+		"a/foo.bin": []byte{0x00, 0x01, 0x02},
+	})
+	require.NoError(t, err)
+
+	b, ok := res.([]byte)
+	require.True(t, ok)
+
+	r, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	require.NoError(t, err)
+	require.Len(t, r.File, 2)
+	assert.Equal(t, "a/foo.bin", r.File[0].Name)
+	assert.Equal(t, "b.txt", r.File[1].Name)
+
+	_, err = exec.Query(map[string]any{"foo.txt": int64(10)})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "file foo.txt: expected bytes value, got number")
+}
+
+func TestUnzipLimits(t *testing.T) {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	_, err := w.Create("dir/")
+	require.NoError(t, err)
+	for _, name := range []string{"dir/a.txt", "dir/b.txt", "c.txt"} {
+		fw, err := w.Create(name)
+		require.NoError(t, err)
+		_, err = fw.Write(bytes.Repeat([]byte("x"), 100))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	archive := buf.Bytes()
+
+	testCases := []struct {
+		name    string
+		mapping string
+		output  any
+		execErr string
+	}{
+		{
+			name:    "within limits",
+			mapping: `root = this.unzip(max_entries: 3, max_size: 300).keys().sort()`,
+			output:  []any{"c.txt", "dir/a.txt", "dir/b.txt"},
+		},
+		{
+			name:    "too many entries",
+			mapping: `root = this.unzip(max_entries: 2)`,
+			execErr: "file c.txt: archive exceeds max_entries of 2",
+		},
+		{
+			name:    "too large",
+			mapping: `root = this.unzip(max_size: 250)`,
+			execErr: "file c.txt: archive exceeds max_size of 250 bytes",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			exec, err := bloblang.Parse(test.mapping)
+			require.NoError(t, err)
+
+			// Execute twice in order to ensure limits are not shared across
+			// executions.
+			for i := 0; i < 2; i++ {
+				res, err := exec.Query(archive)
+				if test.execErr == "" {
+					require.NoError(t, err)
+					assert.Equal(t, test.output, res)
+				} else {
+					require.Error(t, err)
+					assert.Contains(t, err.Error(), test.execErr)
+				}
+			}
+		})
+	}
+
+	exec, err := bloblang.Parse(`root = this.unzip()`)
+	require.NoError(t, err)
+	_, err = exec.Query([]byte("not a zip"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read zip archive")
+}
+
+func TestUntar(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: "dir/", Mode: 0o755}))
+	require.NoError(t, tw.WriteHeader(&tar.Header{Typeflag: tar.TypeSymlink, Name: "link", Linkname: "/etc/passwd"}))
+	for _, name := range []string{"dir/a.txt", "b.txt"} {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: name, Mode: 0o600, Size: 100}))
+		_, err := tw.Write(bytes.Repeat([]byte("x"), 100))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	archive := buf.Bytes()
+
+	testCases := []struct {
+		name    string
+		mapping string
+		output  any
+		execErr string
+	}{
+		{
+			name:    "regular files only",
+			mapping: `root = this.untar(preserve_modes: true).map_each(f -> f.value.mode)`,
+			output:  map[string]any{"dir/a.txt": int64(0o600), "b.txt": int64(0o600)},
+		},
+		{
+			name:    "too many entries",
+			mapping: `root = this.untar(max_entries: 1)`,
+			execErr: "file b.txt: archive exceeds max_entries of 1",
+		},
+		{
+			name:    "too large",
+			mapping: `root = this.untar(max_size: 150)`,
+			execErr: "file b.txt: archive exceeds max_size of 150 bytes",
+		},
+		{
+			name:    "gzipped",
+			mapping: `root = this.compress("gzip").untar().keys().sort()`,
+			output:  []any{"b.txt", "dir/a.txt"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			exec, err := bloblang.Parse(test.mapping)
+			require.NoError(t, err)
+
+			res, err := exec.Query(archive)
+			if test.execErr == "" {
+				require.NoError(t, err)
+				assert.Equal(t, test.output, res)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.execErr)
+			}
+		})
+	}
+}
+
+func TestTarArchiveErrors(t *testing.T) {
+	exec, err := bloblang.Parse(`root = this.tar_archive()`)
+	require.NoError(t, err)
+
+	_, err = exec.Query(map[string]any{"foo.txt": map[string]any{"contents": "foo", "mode": "nope"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "file foo.txt: field mode: expected number value, got string")
+
+	_, err = exec.Query(map[string]any{"foo.txt": map[string]any{"mode": int64(420)}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "file foo.txt: field contents: expected bytes value, got null")
+}
@@ -3,10 +3,18 @@
 package pure
 
 import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/gofrs/uuid"
+
 	"github.com/redpanda-data/benthos/v4/internal/bloblang/query"
 	"github.com/redpanda-data/benthos/v4/public/bloblang"
 )
 
+// shortUUIDLen is the length of a UUID encoded with unpadded base64.
+const shortUUIDLen = 22
+
 func init() {
 	if err := bloblang.RegisterMethodV2("compress",
 		bloblang.NewPluginSpec().
@@ -83,4 +91,55 @@ root.b_len = $long_content.compress("gzip").length()
 		}); err != nil {
 		panic(err)
 	}
+
+	if err := bloblang.RegisterMethodV2("uuid_to_short",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryEncoding).
+			Description(`Encodes a UUID string into a compact 22 character form, which is the 16 bytes of the UUID encoded with unpadded URL safe base64, making it suitable for use within URLs. The UUID can be recovered with `+"<<short_to_uuid, `short_to_uuid`>>"+`. An error is returned if the target is not a valid UUID.`).
+			Example("", `root.link = "https://example.com/items/" + this.id.uuid_to_short()`,
+				[2]string{
+					`{"id":"3f1e2d4c-5b6a-4978-8a9b-0c1d2e3f4a5b"}`,
+					`{"link":"https://example.com/items/Px4tTFtqSXiKmwwdLj9KWw"}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return bloblang.StringMethod(func(s string) (any, error) {
+				u, err := uuid.FromString(s)
+				if err != nil {
+					return nil, fmt.Errorf("failed to parse uuid: %w", err)
+				}
+				return base64.RawURLEncoding.EncodeToString(u.Bytes()), nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("short_to_uuid",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryEncoding).
+			Description(`Decodes a compact UUID created with `+"<<uuid_to_short, `uuid_to_short`>>"+` back into its canonical hyphenated string form. An error is returned if the target is not a 22 character unpadded URL safe base64 string.`).
+			Example("", `root.id = this.short.short_to_uuid()`,
+				[2]string{
+					`{"short":"Px4tTFtqSXiKmwwdLj9KWw"}`,
+					`{"id":"3f1e2d4c-5b6a-4978-8a9b-0c1d2e3f4a5b"}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return bloblang.StringMethod(func(s string) (any, error) {
+				if len(s) != shortUUIDLen {
+					return nil, fmt.Errorf("expected short uuid of length %v, got %v", shortUUIDLen, len(s))
+				}
+				b, err := base64.RawURLEncoding.Strict().DecodeString(s)
+				if err != nil {
+					return nil, fmt.Errorf("failed to decode short uuid: %w", err)
+				}
+				u, err := uuid.FromBytes(b)
+				if err != nil {
+					return nil, fmt.Errorf("failed to decode short uuid: %w", err)
+				}
+				return u.String(), nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
 }
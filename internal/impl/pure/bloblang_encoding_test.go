@@ -42,3 +42,41 @@ func TestCompressionDecompression(t *testing.T) {
 		assert.Equal(t, input, decompressed)
 	}
 }
+
+func TestShortUUID(t *testing.T) {
+	toShort, err := bloblang.Parse(`root = this.uuid_to_short()`)
+	require.NoError(t, err)
+
+	fromShort, err := bloblang.Parse(`root = this.short_to_uuid()`)
+	require.NoError(t, err)
+
+	for _, id := range []string{
+		"00000000-0000-0000-0000-000000000000",
+		"ffffffff-ffff-ffff-ffff-ffffffffffff",
+		"3f1e2d4c-5b6a-4978-8a9b-0c1d2e3f4a5b",
+	} {
+		short, err := toShort.Query(id)
+		require.NoError(t, err)
+		assert.Len(t, short, 22)
+
+		res, err := fromShort.Query(short)
+		require.NoError(t, err)
+		assert.Equal(t, id, res)
+	}
+
+	short, err := toShort.Query("3F1E2D4C-5B6A-4978-8A9B-0C1D2E3F4A5B")
+	require.NoError(t, err)
+	assert.Equal(t, "Px4tTFtqSXiKmwwdLj9KWw", short)
+
+	_, err = toShort.Query("not a uuid")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse uuid")
+
+	_, err = fromShort.Query("Px4tTFtqSXiKmwwdLj9K")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "expected short uuid of length 22, got 20")
+
+	_, err = fromShort.Query("Px4tTFtqSXiKmwwdLj9K+w")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to decode short uuid")
+}
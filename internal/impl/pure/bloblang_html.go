@@ -0,0 +1,159 @@
+// Copyright 2025 Redpanda Data, Inc.
+
+package pure
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"github.com/redpanda-data/benthos/v4/internal/bloblang/query"
+	"github.com/redpanda-data/benthos/v4/public/bloblang"
+)
+
+var htmlBlockElements = map[atom.Atom]struct{}{
+	atom.Address: {}, atom.Article: {}, atom.Aside: {}, atom.Blockquote: {},
+	atom.Br: {}, atom.Dd: {}, atom.Details: {}, atom.Dialog: {}, atom.Div: {},
+	atom.Dl: {}, atom.Dt: {}, atom.Fieldset: {}, atom.Figcaption: {},
+	atom.Figure: {}, atom.Footer: {}, atom.Form: {}, atom.H1: {}, atom.H2: {},
+	atom.H3: {}, atom.H4: {}, atom.H5: {}, atom.H6: {}, atom.Header: {},
+	atom.Hr: {}, atom.Li: {}, atom.Main: {}, atom.Nav: {}, atom.Ol: {},
+	atom.P: {}, atom.Pre: {}, atom.Section: {}, atom.Summary: {},
+	atom.Table: {}, atom.Td: {}, atom.Th: {}, atom.Tr: {}, atom.Ul: {},
+}
+
+var htmlSkippedElements = map[atom.Atom]struct{}{
+	atom.Head: {}, atom.Noscript: {}, atom.Script: {}, atom.Style: {},
+	atom.Template: {}, atom.Title: {},
+}
+
+type htmlTextWriter struct {
+	links bool
+	lines []string
+	line  strings.Builder
+	space bool
+	inPre int
+}
+
+func (w *htmlTextWriter) flush() {
+	if w.line.Len() > 0 {
+		w.lines = append(w.lines, w.line.String())
+	}
+	w.line.Reset()
+	w.space = false
+}
+
+func (w *htmlTextWriter) writeText(s string) {
+	for _, r := range s {
+		if w.inPre > 0 {
+			if r == '\n' {
+				w.lines = append(w.lines, w.line.String())
+				w.line.Reset()
+				continue
+			}
+			w.line.WriteRune(r)
+			continue
+		}
+		if unicode.IsSpace(r) {
+			w.space = true
+			continue
+		}
+		if w.space && w.line.Len() > 0 {
+			w.line.WriteByte(' ')
+		}
+		w.space = false
+		w.line.WriteRune(r)
+	}
+}
+
+func (w *htmlTextWriter) walk(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		w.writeText(n.Data)
+		return
+	case html.ElementNode:
+		if _, skip := htmlSkippedElements[n.DataAtom]; skip {
+			return
+		}
+	case html.CommentNode, html.DoctypeNode:
+		return
+	}
+
+	_, isBlock := htmlBlockElements[n.DataAtom]
+	if isBlock {
+		w.flush()
+	}
+	if n.DataAtom == atom.Pre {
+		w.inPre++
+	}
+
+	linkStart := w.line.Len()
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		w.walk(c)
+	}
+
+	if w.links && n.DataAtom == atom.A {
+		for _, attr := range n.Attr {
+			if attr.Key != "href" || attr.Val == "" {
+				continue
+			}
+			if linkStart <= w.line.Len() && strings.TrimSpace(w.line.String()[linkStart:]) == attr.Val {
+				break
+			}
+			w.writeText(" (" + attr.Val + ")")
+			break
+		}
+	}
+
+	if n.DataAtom == atom.Pre {
+		w.inPre--
+	}
+	if isBlock {
+		w.flush()
+	}
+}
+
+func htmlToText(s string, links bool) (string, error) {
+	doc, err := html.Parse(strings.NewReader(s))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse value as html: %w", err)
+	}
+	w := htmlTextWriter{links: links}
+	w.walk(doc)
+	w.flush()
+	return strings.Join(w.lines, "\n"), nil
+}
+
+func init() {
+	if err := bloblang.RegisterMethodV2("html_to_text",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryStrings).
+			Description(`Parses a string as HTML and returns its readable text content. Markup is removed and HTML entities are decoded, runs of whitespace are collapsed into a single space and block-level elements (such as paragraphs, list items, headings and line breaks) are separated by newlines. The contents of `+"`pre`"+` elements retain their original whitespace, and the contents of non-visible elements such as `+"`head`, `script` and `style`"+` are omitted.`).
+			Param(bloblang.NewBoolParam("links").Description("Whether to append the target of each link to its text in the form `text (url)`.").Default(false)).
+			Example("", `root.text = this.body.html_to_text()`,
+				[2]string{
+					`{"body":"<html><head><title>Hi</title><style>p{color:red}</style></head><body><h1>Hello   world</h1><p>Fish &amp; chips<br>are <b>great</b>.</p><ul><li>one</li><li>two</li></ul></body></html>"}`,
+					`{"text":"Hello world\nFish & chips\nare great.\none\ntwo"}`,
+				},
+			).
+			Example("", `root.text = this.body.html_to_text(links: true)`,
+				[2]string{
+					`{"body":"<p>Read the <a href=\"https://example.com/docs\">docs</a> or visit <a href=\"https://example.com\">https://example.com</a>.</p>"}`,
+					`{"text":"Read the docs (https://example.com/docs) or visit https://example.com."}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			links, err := args.GetBool("links")
+			if err != nil {
+				return nil, err
+			}
+			return bloblang.StringMethod(func(s string) (any, error) {
+				return htmlToText(s, links)
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2025 Redpanda Data, Inc.
+
+package pure
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTMLToText(t *testing.T) {
+	testCases := []struct {
+		name   string
+		input  string
+		links  bool
+		output string
+	}{
+		{
+			name:   "plain text",
+			input:  "  just some\n\ttext  ",
+			output: "just some text",
+		},
+		{
+			name:   "inline elements",
+			input:  "<p>foo<span>bar</span> <em>baz</em></p>",
+			output: "foobar baz",
+		},
+		{
+			name:   "preformatted",
+			input:  "<p>code:</p><pre>a  b\n  c</pre><p>done</p>",
+			output: "code:\na  b\n  c\ndone",
+		},
+		{
+			name:   "table",
+			input:  "<table><tr><td>a</td><td>b</td></tr><tr><td>c</td></tr></table>",
+			output: "a\nb\nc",
+		},
+		{
+			name:   "comments and scripts",
+			input:  "<div><!-- hidden --><script>alert('x')</script>shown</div>",
+			output: "shown",
+		},
+		{
+			name:   "links disabled",
+			input:  `<a href="https://example.com">here</a>`,
+			output: "here",
+		},
+		{
+			name:   "link without href",
+			input:  `<a name="top">top</a>`,
+			links:  true,
+			output: "top",
+		},
+		{
+			name:   "entities",
+			input:  "&lt;tag&gt; &copy; &#8364;",
+			output: "<tag> © €",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			res, err := htmlToText(test.input, test.links)
+			require.NoError(t, err)
+			assert.Equal(t, test.output, res)
+		})
+	}
+}
@@ -0,0 +1,672 @@
+// Copyright 2025 Redpanda Data, Inc.
+
+package pure
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/redpanda-data/benthos/v4/internal/bloblang/query"
+	"github.com/redpanda-data/benthos/v4/internal/value"
+	"github.com/redpanda-data/benthos/v4/public/bloblang"
+)
+
+var sameSiteToString = map[http.SameSite]any{
+	http.SameSiteDefaultMode: nil,
+	http.SameSiteLaxMode:     "lax",
+	http.SameSiteStrictMode:  "strict",
+	http.SameSiteNoneMode:    "none",
+}
+
+func setCookieToMap(c *http.Cookie) map[string]any {
+	res := map[string]any{
+		"name":     c.Name,
+		"value":    c.Value,
+		"domain":   nil,
+		"path":     nil,
+		"expires":  nil,
+		"max_age":  nil,
+		"secure":   c.Secure,
+		"httponly": c.HttpOnly,
+		"samesite": sameSiteToString[c.SameSite],
+	}
+	if c.Domain != "" {
+		res["domain"] = c.Domain
+	}
+	if c.Path != "" {
+		res["path"] = c.Path
+	}
+	if !c.Expires.IsZero() {
+		res["expires"] = c.Expires
+	}
+	if c.MaxAge != 0 {
+		// The standard library represents Max-Age=0 as a negative value.
+		maxAge := int64(c.MaxAge)
+		if maxAge < 0 {
+			maxAge = 0
+		}
+		res["max_age"] = maxAge
+	}
+	return res
+}
+
+func cookieFromMap(m map[string]any) (*http.Cookie, error) {
+	var c http.Cookie
+	var err error
+
+	getStr := func(key string) (string, error) {
+		v, exists := m[key]
+		if !exists || v == nil {
+			return "", nil
+		}
+		s, err := value.IGetString(v)
+		if err != nil {
+			return "", fmt.Errorf("field %v: %w", key, err)
+		}
+		return s, nil
+	}
+	getBool := func(key string) (bool, error) {
+		v, exists := m[key]
+		if !exists || v == nil {
+			return false, nil
+		}
+		b, err := value.IGetBool(v)
+		if err != nil {
+			return false, fmt.Errorf("field %v: %w", key, err)
+		}
+		return b, nil
+	}
+
+	if c.Name, err = getStr("name"); err != nil {
+		return nil, err
+	}
+	if c.Name == "" {
+		return nil, errors.New("field name: must not be empty")
+	}
+	if c.Value, err = getStr("value"); err != nil {
+		return nil, err
+	}
+	if c.Domain, err = getStr("domain"); err != nil {
+		return nil, err
+	}
+	if c.Path, err = getStr("path"); err != nil {
+		return nil, err
+	}
+	if v, exists := m["expires"]; exists && v != nil {
+		if c.Expires, err = value.IGetTimestamp(v); err != nil {
+			return nil, fmt.Errorf("field expires: %w", err)
+		}
+	}
+	if v, exists := m["max_age"]; exists && v != nil {
+		maxAge, err := value.IGetInt(v)
+		if err != nil {
+			return nil, fmt.Errorf("field max_age: %w", err)
+		}
+		if c.MaxAge = int(maxAge); c.MaxAge == 0 {
+			c.MaxAge = -1
+		}
+	}
+	if c.Secure, err = getBool("secure"); err != nil {
+		return nil, err
+	}
+	if c.HttpOnly, err = getBool("httponly"); err != nil {
+		return nil, err
+	}
+
+	sameSite, err := getStr("samesite")
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(sameSite) {
+	case "":
+	case "lax":
+		c.SameSite = http.SameSiteLaxMode
+	case "strict":
+		c.SameSite = http.SameSiteStrictMode
+	case "none":
+		c.SameSite = http.SameSiteNoneMode
+	default:
+		return nil, fmt.Errorf("field samesite: unrecognised value %q, expected one of: lax, strict, none", sameSite)
+	}
+	return &c, nil
+}
+
+func parseRangeHeader(s string, totalSize int64) ([]any, error) {
+	unit, specs, found := strings.Cut(strings.TrimSpace(s), "=")
+	if !found || strings.TrimSpace(unit) != "bytes" {
+		return nil, fmt.Errorf("invalid range header, expected a bytes unit: %q", s)
+	}
+
+	var ranges []any
+	for _, spec := range strings.Split(specs, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		startStr, endStr, found := strings.Cut(spec, "-")
+		if !found {
+			return nil, fmt.Errorf("invalid range %q", spec)
+		}
+		startStr, endStr = strings.TrimSpace(startStr), strings.TrimSpace(endStr)
+
+		var start, end int64
+		if startStr == "" {
+			// A suffix range refers to the final N bytes.
+			n, err := strconv.ParseInt(endStr, 10, 64)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid range %q", spec)
+			}
+			if n == 0 || totalSize == 0 {
+				continue
+			}
+			if n > totalSize {
+				n = totalSize
+			}
+			start, end = totalSize-n, totalSize-1
+		} else {
+			var err error
+			if start, err = strconv.ParseInt(startStr, 10, 64); err != nil || start < 0 {
+				return nil, fmt.Errorf("invalid range %q", spec)
+			}
+			if endStr == "" {
+				end = totalSize - 1
+			} else if end, err = strconv.ParseInt(endStr, 10, 64); err != nil || end < start {
+				return nil, fmt.Errorf("invalid range %q", spec)
+			}
+			if start >= totalSize {
+				continue
+			}
+			if end >= totalSize {
+				end = totalSize - 1
+			}
+		}
+		ranges = append(ranges, map[string]any{
+			"start":  start,
+			"end":    end,
+			"length": end - start + 1,
+		})
+	}
+	if len(ranges) == 0 {
+		return nil, fmt.Errorf("range not satisfiable: %q", s)
+	}
+	return ranges, nil
+}
+
+func contentDispositionFromMap(m map[string]any) (string, error) {
+	typeV, exists := m["type"]
+	if !exists {
+		return "", errors.New("field type: must not be empty")
+	}
+	dispType, err := value.IGetString(typeV)
+	if err != nil {
+		return "", fmt.Errorf("field type: %w", err)
+	}
+
+	params := map[string]string{}
+	if paramsV, exists := m["params"]; exists && paramsV != nil {
+		paramsObj, ok := paramsV.(map[string]any)
+		if !ok {
+			return "", fmt.Errorf("field params: %w", value.NewTypeError(paramsV, value.TObject))
+		}
+		for k, v := range paramsObj {
+			if params[k], err = value.IGetString(v); err != nil {
+				return "", fmt.Errorf("field params.%v: %w", k, err)
+			}
+		}
+	}
+	if filenameV, exists := m["filename"]; exists && filenameV != nil {
+		if params["filename"], err = value.IGetString(filenameV); err != nil {
+			return "", fmt.Errorf("field filename: %w", err)
+		}
+	}
+
+	res := mime.FormatMediaType(dispType, params)
+	if res == "" {
+		return "", fmt.Errorf("invalid content disposition type or parameters: %q", dispType)
+	}
+	return res, nil
+}
+
+func multipartBoundary(boundaryOrContentType string, body []byte) (string, error) {
+	if boundaryOrContentType == "" {
+		// Detect the boundary from the first delimiter line of the body,
+		// skipping any preamble.
+		for _, line := range bytes.Split(body, []byte("\n")) {
+			line = bytes.TrimSpace(line)
+			if len(line) > 2 && bytes.HasPrefix(line, []byte("--")) {
+				return string(line[2:]), nil
+			}
+		}
+		return "", errors.New("unable to detect multipart boundary")
+	}
+	// Values that do not parse as a content type with a subtype are treated
+	// as a raw boundary.
+	mediaType, params, err := mime.ParseMediaType(boundaryOrContentType)
+	if err != nil || !strings.Contains(mediaType, "/") {
+		return boundaryOrContentType, nil
+	}
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		return "", fmt.Errorf("expected a multipart content type, got %v", mediaType)
+	}
+	boundary, exists := params["boundary"]
+	if !exists || boundary == "" {
+		return "", errors.New("content type is missing a boundary parameter")
+	}
+	return boundary, nil
+}
+
+func parseMultipart(body []byte, boundary string) ([]any, error) {
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+
+	var parts []any
+	for {
+		p, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read multipart part %v: %w", len(parts), err)
+		}
+		partBody, err := io.ReadAll(p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read multipart part %v: %w", len(parts), err)
+		}
+
+		part := map[string]any{
+			"headers": urlValuesToMap(url.Values(p.Header)),
+			"body":    partBody,
+		}
+		if mediaType, params, err := mime.ParseMediaType(p.Header.Get("Content-Type")); err == nil &&
+			strings.HasPrefix(mediaType, "multipart/") && params["boundary"] != "" {
+			nested, err := parseMultipart(partBody, params["boundary"])
+			if err != nil {
+				return nil, fmt.Errorf("part %v: %w", len(parts), err)
+			}
+			part["parts"] = nested
+		}
+		parts = append(parts, part)
+	}
+	return parts, nil
+}
+
+type acceptRange struct {
+	mediaType string
+	q         float64
+	params    map[string]string
+}
+
+func parseAcceptHeader(s string) ([]acceptRange, error) {
+	var ranges []acceptRange
+	for _, entry := range strings.Split(s, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		mediaType, params, err := mime.ParseMediaType(entry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse media range %q: %w", entry, err)
+		}
+		r := acceptRange{mediaType: mediaType, q: 1, params: params}
+		if qStr, exists := params["q"]; exists {
+			if r.q, err = strconv.ParseFloat(qStr, 64); err != nil || r.q < 0 || r.q > 1 {
+				return nil, fmt.Errorf("invalid q value for media range %q: %v", entry, qStr)
+			}
+			delete(params, "q")
+		}
+		ranges = append(ranges, r)
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].q > ranges[j].q
+	})
+	return ranges, nil
+}
+
+// acceptRangeSpecificity returns how specifically an accept range matches a
+// media type, or -1 if it does not match at all.
+func acceptRangeSpecificity(r acceptRange, mediaType string) int {
+	if r.mediaType == "*/*" {
+		return 0
+	}
+	rType, rSubType, _ := strings.Cut(r.mediaType, "/")
+	mType, mSubType, _ := strings.Cut(mediaType, "/")
+	if rType != mType {
+		return -1
+	}
+	if rSubType == "*" {
+		return 1
+	}
+	if rSubType == mSubType {
+		return 2
+	}
+	return -1
+}
+
+func negotiateMediaType(ranges []acceptRange, available []string) any {
+	if len(ranges) == 0 {
+		if len(available) == 0 {
+			return nil
+		}
+		return available[0]
+	}
+
+	var best any
+	bestQ := 0.0
+	for _, a := range available {
+		mediaType, _, err := mime.ParseMediaType(a)
+		if err != nil {
+			mediaType = strings.ToLower(a)
+		}
+		specificity, q := -1, 0.0
+		for _, r := range ranges {
+			if s := acceptRangeSpecificity(r, mediaType); s > specificity {
+				specificity, q = s, r.q
+			}
+		}
+		if specificity >= 0 && q > bestQ {
+			best, bestQ = a, q
+		}
+	}
+	return best
+}
+
+func init() {
+	if err := bloblang.RegisterMethodV2("parse_range_header",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryParsing).
+			Description(`Parses the value of a HTTP `+"`Range`"+` request header, such as `+"`bytes=0-499,-500`"+`, into an array of objects containing the fields `+"`start`, `end` (both inclusive byte offsets) and `length`"+`. Open-ended ranges (`+"`500-`"+`) and suffix ranges (`+"`-500`"+`) are resolved against the total size of the resource, and ranges that extend beyond the end of the resource are truncated.
+
+An error is returned if the header is malformed or uses a unit other than `+"`bytes`"+`. Individual ranges that cannot be satisfied (those starting beyond the end of the resource) are omitted from the result, and if none of the ranges can be satisfied an error is returned, which corresponds to a `+"`416 Range Not Satisfiable`"+` response.`).
+			Param(bloblang.NewInt64Param("total_size").Description("The total size in bytes of the resource being requested.")).
+			Example("", `root.ranges = this.header.parse_range_header(10000)`,
+				[2]string{
+					`{"header":"bytes=0-499, 9500-, -100"}`,
+					`{"ranges":[{"end":499,"length":500,"start":0},{"end":9999,"length":500,"start":9500},{"end":9999,"length":100,"start":9900}]}`,
+				},
+			).
+			Example("", `root.ranges = this.header.parse_range_header(1000).catch("unsatisfiable")`,
+				[2]string{
+					`{"header":"bytes=2000-3000"}`,
+					`{"ranges":"unsatisfiable"}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			totalSize, err := args.GetInt64("total_size")
+			if err != nil {
+				return nil, err
+			}
+			if totalSize < 0 {
+				return nil, fmt.Errorf("total_size must not be negative, got %v", totalSize)
+			}
+			return bloblang.StringMethod(func(s string) (any, error) {
+				return parseRangeHeader(s, totalSize)
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("parse_cookies",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryParsing).
+			Description(`Parses the value of a HTTP `+"`Cookie`"+` request header into an object of cookie names to values. When a cookie name appears more than once only the first value is kept. Malformed cookies are ignored.`).
+			Example("", `root.cookies = this.header.parse_cookies()`,
+				[2]string{
+					`{"header":"session=abc123; theme=dark; lang=en"}`,
+					`{"cookies":{"lang":"en","session":"abc123","theme":"dark"}}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return bloblang.StringMethod(func(s string) (any, error) {
+				req := http.Request{Header: http.Header{"Cookie": []string{s}}}
+				res := map[string]any{}
+				for _, c := range req.Cookies() {
+					if _, exists := res[c.Name]; !exists {
+						res[c.Name] = c.Value
+					}
+				}
+				return res, nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("parse_set_cookie",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryParsing).
+			Description(`Parses the value of a HTTP `+"`Set-Cookie`"+` response header into an object containing the fields `+"`name`, `value`, `domain`, `path`, `expires` (a timestamp), `max_age` (in seconds), `secure`, `httponly` and `samesite` (one of `lax`, `strict` or `none`)"+`. Attributes that are not present are set to `+"`null`"+`, or `+"`false`"+` for boolean attributes.`).
+			Example("", `root.cookie = this.header.parse_set_cookie()`,
+				[2]string{
+					`{"header":"session=abc123; Path=/; Domain=example.com; Expires=Wed, 21 Oct 2026 07:28:00 GMT; Secure; HttpOnly; SameSite=Strict"}`,
+					`{"cookie":{"domain":"example.com","expires":"2026-10-21T07:28:00Z","httponly":true,"max_age":null,"name":"session","path":"/","samesite":"strict","secure":true,"value":"abc123"}}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return bloblang.StringMethod(func(s string) (any, error) {
+				res := http.Response{Header: http.Header{"Set-Cookie": []string{s}}}
+				cookies := res.Cookies()
+				if len(cookies) == 0 {
+					return nil, errors.New("failed to parse value as a set-cookie header")
+				}
+				return setCookieToMap(cookies[0]), nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("format_cookie",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryParsing).
+			Description(`Formats an object into the value of a HTTP `+"`Set-Cookie`"+` header, and is the inverse of `+"<<parse_set_cookie, `parse_set_cookie`>>"+`. The field `+"`name`"+` is required, and the optional fields `+"`value`, `domain`, `path`, `expires` (a timestamp), `max_age` (in seconds), `secure`, `httponly` and `samesite` (one of `lax`, `strict` or `none`)"+` are added as attributes when present. When only a name and value are provided the result is also suitable for use within a `+"`Cookie`"+` request header.`).
+			Example("", `root.header = this.cookie.format_cookie()`,
+				[2]string{
+					`{"cookie":{"name":"session","value":"abc123","path":"/","max_age":3600,"secure":true,"httponly":true,"samesite":"lax"}}`,
+					`{"header":"session=abc123; Path=/; Max-Age=3600; HttpOnly; Secure; SameSite=Lax"}`,
+				},
+			).
+			Example("", `root.header = this.cookies.key_values().map_each(kv -> {"name":kv.key,"value":kv.value}.format_cookie()).sort().join("; ")`,
+				[2]string{
+					`{"cookies":{"session":"abc123","theme":"dark"}}`,
+					`{"header":"session=abc123; theme=dark"}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return bloblang.ObjectMethod(func(m map[string]any) (any, error) {
+				c, err := cookieFromMap(m)
+				if err != nil {
+					return nil, err
+				}
+				s := c.String()
+				if s == "" {
+					return nil, fmt.Errorf("invalid cookie name: %q", c.Name)
+				}
+				return s, nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("parse_content_disposition",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryParsing).
+			Description(`Parses the value of a `+"`Content-Disposition`"+` header into an object containing the fields `+"`type`"+` (the lowercased disposition type such as `+"`attachment`"+`), `+"`filename`"+` and `+"`params`"+` (an object of all parameters). Filenames provided in the RFC 5987 extended form (`+"`filename*=UTF-8''...`"+`) are decoded and take precedence over a plain `+"`filename`"+` parameter. When no filename is present the field `+"`filename`"+` is set to `+"`null`"+`.`).
+			Example("", `root.disposition = this.header.parse_content_disposition()`,
+				[2]string{
+					`{"header":"attachment; filename=\"report.pdf\"; size=1024"}`,
+					`{"disposition":{"filename":"report.pdf","params":{"filename":"report.pdf","size":"1024"},"type":"attachment"}}`,
+				},
+				[2]string{
+					`{"header":"attachment; filename=\"naive.txt\"; filename*=UTF-8''%E2%82%AC%20rates.txt"}`,
+					`{"disposition":{"filename":"€ rates.txt","params":{"filename":"€ rates.txt"},"type":"attachment"}}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return bloblang.StringMethod(func(s string) (any, error) {
+				dispType, params, err := mime.ParseMediaType(s)
+				if err != nil {
+					return nil, fmt.Errorf("failed to parse value as content disposition: %w", err)
+				}
+				paramsObj := make(map[string]any, len(params))
+				for k, v := range params {
+					paramsObj[k] = v
+				}
+				var filename any
+				if f, exists := params["filename"]; exists {
+					filename = f
+				}
+				return map[string]any{
+					"type":     dispType,
+					"filename": filename,
+					"params":   paramsObj,
+				}, nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("format_content_disposition",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryParsing).
+			Description(`Formats an object into the value of a `+"`Content-Disposition`"+` header, and is the inverse of `+"<<parse_content_disposition, `parse_content_disposition`>>"+`. The field `+"`type`"+` is required, and the optional fields `+"`filename`"+` and `+"`params`"+` (an object of additional parameters) are added as parameters. Values that contain non-ASCII characters are encoded using the RFC 2231 extended form.`).
+			Example("", `root.header = this.format_content_disposition()`,
+				[2]string{
+					`{"type":"attachment","filename":"report final.pdf"}`,
+					`{"header":"attachment; filename=\"report final.pdf\""}`,
+				},
+				[2]string{
+					`{"type":"attachment","filename":"€ rates.txt"}`,
+					`{"header":"attachment; filename*=utf-8''%E2%82%AC%20rates.txt"}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return bloblang.ObjectMethod(func(m map[string]any) (any, error) {
+				return contentDispositionFromMap(m)
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("parse_multipart",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryParsing).
+			Description(`Parses a MIME multipart body (such as an email or a `+"`multipart/form-data`"+` upload) into an array of objects, one for each part, containing the fields `+"`headers`"+` (an object of header keys to values, where headers with multiple values are represented as an array) and `+"`body`"+` (the raw bytes of the part). Parts that are themselves multipart bodies additionally contain the field `+"`parts`"+`, an array of their parsed sub-parts.
+
+Parts encoded with `+"`quoted-printable`"+` are decoded automatically, and the `+"`Content-Transfer-Encoding`"+` header is removed from their headers. Other transfer encodings such as `+"`base64`"+` are left untouched and can be decoded with the `+"<<decode, `decode`>>"+` method.`).
+			Param(bloblang.NewStringParam("boundary").Description("Either the boundary separating parts or a full `Content-Type` header value containing a `boundary` parameter. When omitted the boundary is detected from the first delimiter line of the body.").Optional()).
+			Example("", `root.parts = this.body.parse_multipart(this.content_type).map_each(p -> {"name": p.headers."Content-Disposition".parse_content_disposition().params.name, "value": p.body.string()})`,
+				[2]string{
+					`{"content_type":"multipart/form-data; boundary=XYZ","body":"--XYZ\r\nContent-Disposition: form-data; name=\"a\"\r\n\r\nfoo\r\n--XYZ\r\nContent-Disposition: form-data; name=\"b\"\r\n\r\nbar\r\n--XYZ--\r\n"}`,
+					`{"parts":[{"name":"a","value":"foo"},{"name":"b","value":"bar"}]}`,
+				},
+			).
+			Example("When the boundary is omitted it is detected from the body.", `root.types = this.body.parse_multipart().map_each(p -> p.headers."Content-Type")`,
+				[2]string{
+					`{"body":"--XYZ\r\nContent-Type: text/plain\r\n\r\nhello\r\n--XYZ\r\nContent-Type: text/html\r\n\r\n<p>hello</p>\r\n--XYZ--\r\n"}`,
+					`{"types":["text/plain","text/html"]}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			boundaryArg, err := args.GetOptionalString("boundary")
+			if err != nil {
+				return nil, err
+			}
+			return bloblang.BytesMethod(func(b []byte) (any, error) {
+				var boundaryOrContentType string
+				if boundaryArg != nil {
+					boundaryOrContentType = *boundaryArg
+				}
+				boundary, err := multipartBoundary(boundaryOrContentType, b)
+				if err != nil {
+					return nil, err
+				}
+				return parseMultipart(b, boundary)
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("parse_accept_header",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryParsing).
+			Description(`Parses the value of a HTTP `+"`Accept`"+` header into an array of objects, one for each media range, containing the fields `+"`media_type`"+` (lowercased), `+"`q`"+` (the quality value, defaulting to `+"`1`"+`) and `+"`params`"+` (an object of any other parameters). The array is sorted by descending quality value, and ranges of equal quality retain the order in which they appear within the header.`).
+			Example("", `root.accept = this.header.parse_accept_header()`,
+				[2]string{
+					`{"header":"text/html;q=0.8, application/json, */*;q=0.1, text/plain;format=flowed"}`,
+					`{"accept":[{"media_type":"application/json","params":{},"q":1},{"media_type":"text/plain","params":{"format":"flowed"},"q":1},{"media_type":"text/html","params":{},"q":0.8},{"media_type":"*/*","params":{},"q":0.1}]}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return bloblang.StringMethod(func(s string) (any, error) {
+				ranges, err := parseAcceptHeader(s)
+				if err != nil {
+					return nil, err
+				}
+				res := make([]any, len(ranges))
+				for i, r := range ranges {
+					params := make(map[string]any, len(r.params))
+					for k, v := range r.params {
+						params[k] = v
+					}
+					res[i] = map[string]any{
+						"media_type": r.mediaType,
+						"q":          r.q,
+						"params":     params,
+					}
+				}
+				return res, nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("negotiate",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryParsing).
+			Description(`Performs content negotiation by selecting the best media type from an array of available types according to the value of a HTTP `+"`Accept`"+` header. Each available type is given the quality value of the most specific media range that matches it (an exact match, followed by a `+"`type/*`"+` wildcard, followed by `+"`*/*`"+`), and the type with the highest quality is returned, with ties resolved by the order of the available array. Types with a quality of zero are never selected. When none of the available types are acceptable `+"`null`"+` is returned, and when the header is empty the first available type is returned.`).
+			Param(bloblang.NewAnyParam("available").Description("An array of media types that can be served, in order of preference.")).
+			Example("", `root.format = this.header.negotiate(["application/json","application/xml","text/html"])`,
+				[2]string{
+					`{"header":"text/html;q=0.9, application/*;q=0.8"}`,
+					`{"format":"text/html"}`,
+				},
+				[2]string{
+					`{"header":"application/xml, application/json, */*;q=0.1"}`,
+					`{"format":"application/json"}`,
+				},
+				[2]string{
+					`{"header":"image/png"}`,
+					`{"format":null}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			availableV, err := args.Get("available")
+			if err != nil {
+				return nil, err
+			}
+			availableArr, ok := availableV.([]any)
+			if !ok {
+				return nil, value.NewTypeError(availableV, value.TArray)
+			}
+			available := make([]string, len(availableArr))
+			for i, a := range availableArr {
+				if available[i], err = value.IGetString(a); err != nil {
+					return nil, fmt.Errorf("available element %v: %w", i, err)
+				}
+			}
+			return bloblang.StringMethod(func(s string) (any, error) {
+				ranges, err := parseAcceptHeader(s)
+				if err != nil {
+					return nil, err
+				}
+				return negotiateMediaType(ranges, available), nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,414 @@
+// Copyright 2025 Redpanda Data, Inc.
+
+package pure
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/redpanda-data/benthos/v4/public/bloblang"
+)
+
+func TestCookieMethods(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mapping string
+		input   any
+		output  any
+		execErr string
+	}{
+		{
+			name:    "parse cookies duplicate names",
+			mapping: `root = this.parse_cookies()`,
+			input:   `a=1; b=2; a=3`,
+			output:  map[string]any{"a": "1", "b": "2"},
+		},
+		{
+			name:    "parse cookies empty",
+			mapping: `root = this.parse_cookies()`,
+			input:   ``,
+			output:  map[string]any{},
+		},
+		{
+			name:    "parse set cookie max age zero",
+			mapping: `root = this.parse_set_cookie().with("name","max_age","samesite")`,
+			input:   `a=1; Max-Age=0`,
+			output:  map[string]any{"name": "a", "max_age": int64(0), "samesite": nil},
+		},
+		{
+			name:    "parse set cookie invalid",
+			mapping: `root = this.parse_set_cookie()`,
+			input:   `nope`,
+			execErr: "failed to parse value as a set-cookie header",
+		},
+		{
+			name:    "round trip",
+			mapping: `root = this.parse_set_cookie().format_cookie()`,
+			input:   `id=a3fWa; Path=/docs; Domain=example.com; Expires=Thu, 21 Oct 2021 07:28:00 GMT; Max-Age=0; Secure; SameSite=None`,
+			output:  `id=a3fWa; Path=/docs; Domain=example.com; Expires=Thu, 21 Oct 2021 07:28:00 GMT; Max-Age=0; Secure; SameSite=None`,
+		},
+		{
+			name:    "format cookie missing name",
+			mapping: `root = this.format_cookie()`,
+			input:   map[string]any{"value": "foo"},
+			execErr: "field name: must not be empty",
+		},
+		{
+			name:    "format cookie bad samesite",
+			mapping: `root = this.format_cookie()`,
+			input:   map[string]any{"name": "foo", "samesite": "sometimes"},
+			execErr: `field samesite: unrecognised value "sometimes"`,
+		},
+		{
+			name:    "format cookie bad name",
+			mapping: `root = this.format_cookie()`,
+			input:   map[string]any{"name": "foo bar"},
+			execErr: `invalid cookie name: "foo bar"`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			exec, err := bloblang.Parse(test.mapping)
+			require.NoError(t, err)
+
+			res, err := exec.Query(test.input)
+			if test.execErr == "" {
+				require.NoError(t, err)
+				assert.Equal(t, test.output, res)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.execErr)
+			}
+		})
+	}
+}
+
+func TestParseRangeHeader(t *testing.T) {
+	testCases := []struct {
+		name      string
+		input     string
+		totalSize int64
+		output    []any
+		err       string
+	}{
+		{
+			name:      "end beyond size",
+			input:     "bytes=900-2000",
+			totalSize: 1000,
+			output:    []any{map[string]any{"start": int64(900), "end": int64(999), "length": int64(100)}},
+		},
+		{
+			name:      "suffix larger than size",
+			input:     "bytes=-5000",
+			totalSize: 1000,
+			output:    []any{map[string]any{"start": int64(0), "end": int64(999), "length": int64(1000)}},
+		},
+		{
+			name:      "unsatisfiable dropped",
+			input:     "bytes=1000-,0-0",
+			totalSize: 1000,
+			output:    []any{map[string]any{"start": int64(0), "end": int64(0), "length": int64(1)}},
+		},
+		{
+			name:      "zero suffix",
+			input:     "bytes=-0",
+			totalSize: 1000,
+			err:       "range not satisfiable",
+		},
+		{
+			name:      "empty resource",
+			input:     "bytes=0-",
+			totalSize: 0,
+			err:       "range not satisfiable",
+		},
+		{
+			name:      "wrong unit",
+			input:     "items=0-5",
+			totalSize: 1000,
+			err:       "expected a bytes unit",
+		},
+		{
+			name:      "end before start",
+			input:     "bytes=10-5",
+			totalSize: 1000,
+			err:       `invalid range "10-5"`,
+		},
+		{
+			name:      "not a number",
+			input:     "bytes=a-5",
+			totalSize: 1000,
+			err:       `invalid range "a-5"`,
+		},
+		{
+			name:      "missing dash",
+			input:     "bytes=5",
+			totalSize: 1000,
+			err:       `invalid range "5"`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			res, err := parseRangeHeader(test.input, test.totalSize)
+			if test.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.output, res)
+		})
+	}
+}
+
+func TestContentDispositionMethods(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mapping string
+		input   any
+		output  any
+		execErr string
+	}{
+		{
+			name:    "inline without filename",
+			mapping: `root = this.parse_content_disposition()`,
+			input:   `INLINE`,
+			output: map[string]any{
+				"type":     "inline",
+				"filename": nil,
+				"params":   map[string]any{},
+			},
+		},
+		{
+			name:    "invalid",
+			mapping: `root = this.parse_content_disposition()`,
+			input:   `attachment; filename=`,
+			execErr: "failed to parse value as content disposition",
+		},
+		{
+			name:    "round trip",
+			mapping: `root = this.parse_content_disposition().format_content_disposition().parse_content_disposition()`,
+			input:   `form-data; name="upload"; filename*=UTF-8''na%C3%AFve.txt`,
+			output: map[string]any{
+				"type":     "form-data",
+				"filename": "naïve.txt",
+				"params":   map[string]any{"name": "upload", "filename": "naïve.txt"},
+			},
+		},
+		{
+			name:    "format missing type",
+			mapping: `root = this.format_content_disposition()`,
+			input:   map[string]any{"filename": "foo.txt"},
+			execErr: "field type: must not be empty",
+		},
+		{
+			name:    "format params not object",
+			mapping: `root = this.format_content_disposition()`,
+			input:   map[string]any{"type": "attachment", "params": "nope"},
+			execErr: "field params: expected object value, got string",
+		},
+		{
+			name:    "format invalid type",
+			mapping: `root = this.format_content_disposition()`,
+			input:   map[string]any{"type": "not valid"},
+			execErr: "invalid content disposition type or parameters",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			exec, err := bloblang.Parse(test.mapping)
+			require.NoError(t, err)
+
+			res, err := exec.Query(test.input)
+			if test.execErr == "" {
+				require.NoError(t, err)
+				assert.Equal(t, test.output, res)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.execErr)
+			}
+		})
+	}
+}
+
+func TestParseMultipart(t *testing.T) {
+	nestedBody := "This is a multi-part message in MIME format.\r\n" +
+		"--outer\r\n" +
+		"Content-Type: multipart/alternative; boundary=inner\r\n" +
+		"\r\n" +
+		"--inner\r\n" +
+		"Content-Type: text/plain\r\n" +
+		"Content-Transfer-Encoding: quoted-printable\r\n" +
+		"\r\n" +
+		"caf=C3=A9\r\n" +
+		"--inner--\r\n" +
+		"\r\n" +
+		"--outer\r\n" +
+		"Content-Type: application/octet-stream\r\n" +
+		"X-Tag: a\r\n" +
+		"X-Tag: b\r\n" +
+		"\r\n" +
+		"\x00\x01\r\n" +
+		"--outer--\r\n"
+
+	testCases := []struct {
+		name    string
+		mapping string
+		input   any
+		output  any
+		execErr string
+	}{
+		{
+			name:    "nested with preamble",
+			mapping: `root = this.parse_multipart().map_each(p -> p.without("body"))`,
+			input:   nestedBody,
+			output: []any{
+				map[string]any{
+					"headers": map[string]any{"Content-Type": "multipart/alternative; boundary=inner"},
+					"parts": []any{
+						map[string]any{
+							"headers": map[string]any{"Content-Type": "text/plain"},
+							"body":    []byte("café"),
+						},
+					},
+				},
+				map[string]any{
+					"headers": map[string]any{
+						"Content-Type": "application/octet-stream",
+						"X-Tag":        []any{"a", "b"},
+					},
+				},
+			},
+		},
+		{
+			name:    "binary body",
+			mapping: `root = this.parse_multipart("outer").index(1).body`,
+			input:   nestedBody,
+			output:  []byte{0x00, 0x01},
+		},
+		{
+			name:    "quoted boundary in content type",
+			mapping: `root = this.parse_multipart("multipart/mixed; boundary=\"outer\"").length()`,
+			input:   nestedBody,
+			output:  int64(2),
+		},
+		{
+			name:    "raw boundary with special characters",
+			mapping: `root = this.parse_multipart("=_a/b?c").index(0).body`,
+			input:   "--=_a/b?c\r\n\r\nhello\r\n--=_a/b?c--\r\n",
+			output:  []byte("hello"),
+		},
+		{
+			name:    "no boundary in content type",
+			mapping: `root = this.parse_multipart("multipart/mixed")`,
+			input:   nestedBody,
+			execErr: "content type is missing a boundary parameter",
+		},
+		{
+			name:    "non multipart content type",
+			mapping: `root = this.parse_multipart("text/plain; boundary=foo")`,
+			input:   nestedBody,
+			execErr: "expected a multipart content type, got text/plain",
+		},
+		{
+			name:    "undetectable boundary",
+			mapping: `root = this.parse_multipart()`,
+			input:   "just some text",
+			execErr: "unable to detect multipart boundary",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			exec, err := bloblang.Parse(test.mapping)
+			require.NoError(t, err)
+
+			res, err := exec.Query(test.input)
+			if test.execErr == "" {
+				require.NoError(t, err)
+				assert.Equal(t, test.output, res)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.execErr)
+			}
+		})
+	}
+}
+
+func TestAcceptHeaderMethods(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mapping string
+		input   any
+		output  any
+		execErr string
+	}{
+		{
+			name:    "parse empty",
+			mapping: `root = this.parse_accept_header()`,
+			input:   ``,
+			output:  []any{},
+		},
+		{
+			name:    "parse invalid q",
+			mapping: `root = this.parse_accept_header()`,
+			input:   `text/html;q=2`,
+			execErr: `invalid q value for media range "text/html;q=2": 2`,
+		},
+		{
+			name:    "parse invalid media range",
+			mapping: `root = this.parse_accept_header()`,
+			input:   `text/html, ;;`,
+			execErr: `failed to parse media range ";;"`,
+		},
+		{
+			name:    "negotiate empty header",
+			mapping: `root = this.negotiate(["text/plain","text/html"])`,
+			input:   ``,
+			output:  "text/plain",
+		},
+		{
+			name:    "negotiate excluded by specific range",
+			mapping: `root = this.negotiate(["application/json","text/html"])`,
+			input:   `*/*, application/json;q=0`,
+			output:  "text/html",
+		},
+		{
+			name:    "negotiate case insensitive with params",
+			mapping: `root = this.negotiate(["Text/HTML; charset=utf-8"])`,
+			input:   `text/html`,
+			output:  "Text/HTML; charset=utf-8",
+		},
+		{
+			name:    "negotiate nothing acceptable",
+			mapping: `root = this.negotiate(["application/json"])`,
+			input:   `application/json;q=0`,
+			output:  nil,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			exec, err := bloblang.Parse(test.mapping)
+			require.NoError(t, err)
+
+			res, err := exec.Query(test.input)
+			if test.execErr == "" {
+				require.NoError(t, err)
+				assert.Equal(t, test.output, res)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.execErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright 2025 Redpanda Data, Inc.
+
+package pure
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/redpanda-data/benthos/v4/internal/bloblang/query"
+	"github.com/redpanda-data/benthos/v4/internal/value"
+	"github.com/redpanda-data/benthos/v4/public/bloblang"
+)
+
+// jcsNumber formats a float64 following the number serialisation rules of
+// ECMAScript, as required by RFC 8785.
+func jcsNumber(f float64) (string, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", fmt.Errorf("value %v cannot be represented in JSON", f)
+	}
+	if f == 0 {
+		return "0", nil
+	}
+
+	var sign string
+	if f < 0 {
+		f = -f
+		sign = "-"
+	}
+
+	format := byte('e')
+	if f < 1e21 && f >= 1e-6 {
+		format = 'f'
+	}
+	s := strconv.FormatFloat(f, format, -1, 64)
+
+	// Go zero pads exponents ("1e+06") whereas ECMAScript does not ("1e+6").
+	if i := strings.IndexByte(s, 'e'); i > 0 && s[i+2] == '0' {
+		s = s[:i+2] + s[i+3:]
+	}
+	return sign + s, nil
+}
+
+func jcsString(buf *bytes.Buffer, s string) {
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(buf, `\u%04x`, r)
+			} else {
+				buf.WriteRune(r)
+			}
+		}
+	}
+	buf.WriteByte('"')
+}
+
+// jcsKeyLess compares object keys by their UTF-16 code units, as required by
+// RFC 8785.
+func jcsKeyLess(a, b string) bool {
+	ua, ub := utf16.Encode([]rune(a)), utf16.Encode([]rune(b))
+	for i := 0; i < len(ua) && i < len(ub); i++ {
+		if ua[i] != ub[i] {
+			return ua[i] < ub[i]
+		}
+	}
+	return len(ua) < len(ub)
+}
+
+// jsonCanonical writes the canonical JSON serialisation of a value as
+// described by RFC 8785 (JCS).
+func jsonCanonical(buf *bytes.Buffer, v any) error {
+	switch t := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(t))
+	case string:
+		if !utf8.ValidString(t) {
+			return errors.New("string contains invalid UTF-8")
+		}
+		jcsString(buf, t)
+	case []byte:
+		jcsString(buf, base64.StdEncoding.EncodeToString(t))
+	case time.Time:
+		jcsString(buf, t.Format(time.RFC3339Nano))
+	case json.Number:
+		// Integers are kept exact in the same way as int64 and uint64 values,
+		// all other numbers are rounded to an IEEE 754 double.
+		if i, err := t.Int64(); err == nil {
+			return jsonCanonical(buf, i)
+		}
+		if u, err := strconv.ParseUint(t.String(), 10, 64); err == nil {
+			return jsonCanonical(buf, u)
+		}
+		f, err := t.Float64()
+		if err != nil {
+			return err
+		}
+		return jsonCanonical(buf, f)
+	case float64:
+		s, err := jcsNumber(t)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(s)
+	case float32:
+		return jsonCanonical(buf, float64(t))
+	case int64:
+		buf.WriteString(strconv.FormatInt(t, 10))
+	case uint64:
+		buf.WriteString(strconv.FormatUint(t, 10))
+	case []any:
+		buf.WriteByte('[')
+		for i, e := range t {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := jsonCanonical(buf, e); err != nil {
+				return fmt.Errorf("index %v: %w", i, err)
+			}
+		}
+		buf.WriteByte(']')
+	case map[string]any:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			return jcsKeyLess(keys[i], keys[j])
+		})
+		buf.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if !utf8.ValidString(k) {
+				return fmt.Errorf("key %q contains invalid UTF-8", k)
+			}
+			jcsString(buf, k)
+			buf.WriteByte(':')
+			if err := jsonCanonical(buf, t[k]); err != nil {
+				return fmt.Errorf("field %v: %w", k, err)
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		// Only recurse when sanitising results in a different type, values
+		// such as deleted() and nothing are returned unchanged.
+		sanitised := value.ISanitize(v)
+		if sanitised == nil || reflect.TypeOf(sanitised) == reflect.TypeOf(v) {
+			return value.NewTypeError(v, value.TObject, value.TArray, value.TString, value.TNumber, value.TBool, value.TNull)
+		}
+		return jsonCanonical(buf, sanitised)
+	}
+	return nil
+}
+
+func init() {
+	if err := bloblang.RegisterMethodV2("json_canonical",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryParsing).
+			Description(`Serializes a target value into a canonical JSON byte array following https://www.rfc-editor.org/rfc/rfc8785[RFC 8785 (JSON Canonicalization Scheme)^]. Object keys are sorted recursively, insignificant whitespace is omitted, strings are escaped minimally (HTML characters are not escaped) and numbers are formatted deterministically, which makes the result suitable for hashing, content addressing and signing documents.
+
+Numbers with a fractional part or exponent are rounded to IEEE 754 double precision and formatted following the rules of JCS, whereas integers (including those parsed from JSON documents) are written in full without rounding. Therefore integers are only guaranteed to match the output of other JCS implementations, which round all numbers to doubles, when their magnitude does not exceed 2^53. Byte arrays are serialized as base64 encoded strings and timestamps as RFC 3339 strings. An error is returned if the value contains a number that cannot be represented in JSON (`+"`NaN` or infinity"+`), or a string that is not valid UTF-8.`).
+			Example("", `root = this.json_canonical().string()`,
+				[2]string{
+					`{"b":[3.0,1e21,0.000001],"a":{"z":"<hello>","y":null}}`,
+					`{"a":{"y":null,"z":"<hello>"},"b":[3,1e+21,0.000001]}`,
+				},
+			).
+			Example("The canonical form of a document can be hashed in order to derive an identifier that is independent of key ordering and formatting.", `root.id = this.json_canonical().hash("sha256").encode("hex")`,
+				[2]string{
+					`{"name":"foo","tags":["a","b"]}`,
+					`{"id":"e6948fe4586d95e4451054e08326232ecd938ea3cd993fe0c7d4808f6827d5a6"}`,
+				},
+				[2]string{
+					`{ "tags": ["a", "b"], "name": "foo" }`,
+					`{"id":"e6948fe4586d95e4451054e08326232ecd938ea3cd993fe0c7d4808f6827d5a6"}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return func(v any) (any, error) {
+				var buf bytes.Buffer
+				if err := jsonCanonical(&buf, v); err != nil {
+					return nil, err
+				}
+				return buf.Bytes(), nil
+			}, nil
+		}); err != nil {
+		panic(err)
+	}
+}
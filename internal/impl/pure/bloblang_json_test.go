@@ -0,0 +1,138 @@
+// Copyright 2025 Redpanda Data, Inc.
+
+package pure
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/redpanda-data/benthos/v4/internal/value"
+	"github.com/redpanda-data/benthos/v4/public/bloblang"
+)
+
+func TestJCSNumber(t *testing.T) {
+	for _, test := range []struct {
+		input  float64
+		output string
+	}{
+		{input: 0, output: "0"},
+		{input: math.Copysign(0, -1), output: "0"},
+		{input: 1, output: "1"},
+		{input: -1.5, output: "-1.5"},
+		{input: 333333333.3333333, output: "333333333.3333333"},
+		{input: 1e21, output: "1e+21"},
+		{input: 1e20, output: "100000000000000000000"},
+		{input: 0.000001, output: "0.000001"},
+		{input: 0.0000001, output: "1e-7"},
+		{input: 9007199254740992, output: "9007199254740992"},
+		{input: 5e-324, output: "5e-324"},
+		{input: 1.7976931348623157e308, output: "1.7976931348623157e+308"},
+	} {
+		s, err := jcsNumber(test.input)
+		require.NoError(t, err)
+		assert.Equal(t, test.output, s, "%v", test.input)
+	}
+
+	_, err := jcsNumber(math.NaN())
+	require.Error(t, err)
+
+	_, err = jcsNumber(math.Inf(1))
+	require.Error(t, err)
+}
+
+func TestJSONCanonical(t *testing.T) {
+	for name, test := range map[string]struct {
+		input  any
+		output string
+		err    string
+	}{
+		"sorted keys": {
+			input: map[string]any{
+				"b": int64(1),
+				"a": map[string]any{"d": true, "c": nil},
+			},
+			output: `{"a":{"c":null,"d":true},"b":1}`,
+		},
+		"utf16 key ordering": {
+			input: map[string]any{
+				"\U0001F600": int64(1),
+				"\uFB33":     int64(2),
+				"\r":         int64(3),
+				"1":          int64(4),
+			},
+			output: "{\"\\r\":3,\"1\":4,\"\U0001F600\":1,\"\uFB33\":2}",
+		},
+		"string escapes": {
+			input:  "a\"b\\c\n\t\u0001<>&é",
+			output: `"a\"b\\c\n\t\u0001<>&é"`,
+		},
+		"mixed numbers": {
+			input:  []any{int64(-5), uint64(10), 1.5, float32(0.5)},
+			output: `[-5,10,1.5,0.5]`,
+		},
+		"bytes and time": {
+			input: []any{
+				[]byte("hello"),
+				time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+			output: `["aGVsbG8=","2025-01-01T00:00:00Z"]`,
+		},
+		"exact json numbers": {
+			input:  []any{json.Number("9007199254740993"), json.Number("18446744073709551615"), json.Number("-12"), json.Number("1.50")},
+			output: `[9007199254740993,18446744073709551615,-12,1.5]`,
+		},
+		"nested delete": {
+			input: map[string]any{"a": value.Delete(nil)},
+			err:   "field a: expected object, array, string, number, bool or null value, got delete",
+		},
+		"nested nothing": {
+			input: []any{value.Nothing(nil)},
+			err:   "index 0: expected object, array, string, number, bool or null value, got nothing",
+		},
+		"nested nan": {
+			input: map[string]any{"a": []any{math.NaN()}},
+			err:   "field a: index 0: value NaN cannot be represented in JSON",
+		},
+		"invalid utf8": {
+			input: []any{"\xff"},
+			err:   "index 0: string contains invalid UTF-8",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := jsonCanonical(&buf, test.input)
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.output, buf.String())
+		})
+	}
+}
+
+func TestJSONCanonicalMapping(t *testing.T) {
+	for _, mapping := range []string{
+		`root = deleted().json_canonical()`,
+		`root = (if false { 1 }).json_canonical()`,
+	} {
+		exec, err := bloblang.Parse(mapping)
+		require.NoError(t, err, mapping)
+
+		_, err = exec.Query(nil)
+		require.Error(t, err, mapping)
+	}
+
+	exec, err := bloblang.Parse(`root = this.doc.parse_json(use_number: true).json_canonical().string()`)
+	require.NoError(t, err)
+
+	res, err := exec.Query(map[string]any{"doc": `{"id":9007199254740993,"ratio":0.50}`})
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":9007199254740993,"ratio":0.5}`, res)
+}
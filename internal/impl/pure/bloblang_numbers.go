@@ -3,7 +3,10 @@
 package pure
 
 import (
+	"fmt"
 	"math"
+	"math/big"
+	"strconv"
 	"strings"
 
 	"github.com/redpanda-data/benthos/v4/internal/bloblang/query"
@@ -48,6 +51,286 @@ root = this.$NAME()
 	}
 }
 
+func ordinalSuffix(n uint64) string {
+	if n%100 >= 11 && n%100 <= 13 {
+		return "th"
+	}
+	switch n % 10 {
+	case 1:
+		return "st"
+	case 2:
+		return "nd"
+	case 3:
+		return "rd"
+	}
+	return "th"
+}
+
+var (
+	cardinalSmallWords = []string{
+		"zero", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine",
+		"ten", "eleven", "twelve", "thirteen", "fourteen", "fifteen", "sixteen", "seventeen", "eighteen", "nineteen",
+	}
+	cardinalTensWords = []string{
+		"", "", "twenty", "thirty", "forty", "fifty", "sixty", "seventy", "eighty", "ninety",
+	}
+	cardinalScaleWords = []string{
+		"", "thousand", "million", "billion", "trillion", "quadrillion", "quintillion",
+	}
+	ordinalIrregularWords = map[string]string{
+		"one":    "first",
+		"two":    "second",
+		"three":  "third",
+		"five":   "fifth",
+		"eight":  "eighth",
+		"nine":   "ninth",
+		"twelve": "twelfth",
+	}
+)
+
+// cardinalWords spells out a number in English words, such as "one hundred
+// twenty-three".
+func cardinalWords(n uint64) string {
+	if n == 0 {
+		return cardinalSmallWords[0]
+	}
+	var groups []string
+	for scale := 0; n > 0; scale++ {
+		if g := n % 1000; g > 0 {
+			words := cardinalWordsUnderThousand(g)
+			if cardinalScaleWords[scale] != "" {
+				words += " " + cardinalScaleWords[scale]
+			}
+			groups = append([]string{words}, groups...)
+		}
+		n /= 1000
+	}
+	return strings.Join(groups, " ")
+}
+
+func cardinalWordsUnderThousand(n uint64) string {
+	var words []string
+	if n >= 100 {
+		words = append(words, cardinalSmallWords[n/100], "hundred")
+		n %= 100
+	}
+	switch {
+	case n >= 20:
+		w := cardinalTensWords[n/10]
+		if n%10 > 0 {
+			w += "-" + cardinalSmallWords[n%10]
+		}
+		words = append(words, w)
+	case n > 0:
+		words = append(words, cardinalSmallWords[n])
+	}
+	return strings.Join(words, " ")
+}
+
+// ordinalWords spells out the ordinal form of a number in English words, such
+// as "one hundred twenty-third".
+func ordinalWords(n uint64) string {
+	words := cardinalWords(n)
+	i := strings.LastIndexAny(words, " -") + 1
+	last := words[i:]
+	switch {
+	case ordinalIrregularWords[last] != "":
+		last = ordinalIrregularWords[last]
+	case strings.HasSuffix(last, "y"):
+		last = strings.TrimSuffix(last, "y") + "ieth"
+	default:
+		last += "th"
+	}
+	return words[:i] + last
+}
+
+func ordinalize(n int64, words bool) string {
+	var prefix string
+	u := uint64(n)
+	if n < 0 {
+		u = -u
+		prefix = "-"
+		if words {
+			prefix = "minus "
+		}
+	}
+	if words {
+		return prefix + ordinalWords(u)
+	}
+	return prefix + strconv.FormatUint(u, 10) + ordinalSuffix(u)
+}
+
+var (
+	humanizeDecimalUnits = []string{"", "K", "M", "B", "T"}
+	humanizeBinaryUnits  = []string{"", "Ki", "Mi", "Gi", "Ti", "Pi", "Ei"}
+)
+
+// humanizeNumber abbreviates a number with a unit suffix, rounding it to a
+// number of decimal places with any trailing zeros removed.
+func humanizeNumber(f float64, decimals int64, binary bool) string {
+	base, units := 1000.0, humanizeDecimalUnits
+	if binary {
+		base, units = 1024.0, humanizeBinaryUnits
+	}
+
+	abs := math.Abs(f)
+	if abs < base || math.IsInf(f, 0) || math.IsNaN(f) {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+
+	unit := 0
+	for abs >= base && unit < len(units)-1 {
+		abs /= base
+		unit++
+	}
+
+	s := strconv.FormatFloat(abs, 'f', int(decimals), 64)
+	if rounded, _ := strconv.ParseFloat(s, 64); rounded >= base && unit < len(units)-1 {
+		// Rounding has pushed the value into the next unit, e.g. 999.95K
+		// becomes 1000.0K, which should instead be 1M.
+		unit++
+		s = strconv.FormatFloat(rounded/base, 'f', int(decimals), 64)
+	}
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	if f < 0 {
+		s = "-" + s
+	}
+	return s + units[unit]
+}
+
+var romanNumerals = []struct {
+	value  int64
+	symbol string
+}{
+	{1000, "M"}, {900, "CM"}, {500, "D"}, {400, "CD"},
+	{100, "C"}, {90, "XC"}, {50, "L"}, {40, "XL"},
+	{10, "X"}, {9, "IX"}, {5, "V"}, {4, "IV"}, {1, "I"},
+}
+
+func toRoman(n int64) (string, error) {
+	if n < 1 || n > 3999 {
+		return "", fmt.Errorf("value must be between 1 and 3999, got %v", n)
+	}
+	var b strings.Builder
+	for _, r := range romanNumerals {
+		for ; n >= r.value; n -= r.value {
+			b.WriteString(r.symbol)
+		}
+	}
+	return b.String(), nil
+}
+
+// fromRoman parses a Roman numeral in its canonical form, which is verified by
+// converting the result back into a numeral.
+func fromRoman(s string) (int64, error) {
+	upper := strings.ToUpper(s)
+	var n int64
+	rest := upper
+	for _, r := range romanNumerals {
+		for strings.HasPrefix(rest, r.symbol) {
+			n += r.value
+			rest = rest[len(r.symbol):]
+		}
+	}
+	if rest != "" || n == 0 {
+		return 0, fmt.Errorf("malformed roman numeral: %q", s)
+	}
+	if canonical, err := toRoman(n); err != nil || canonical != upper {
+		return 0, fmt.Errorf("malformed roman numeral: %q", s)
+	}
+	return n, nil
+}
+
+func checkBigRadix(radix int64) error {
+	if radix < 2 || radix > big.MaxBase {
+		return fmt.Errorf("radix must be between 2 and %v, got %v", big.MaxBase, radix)
+	}
+	return nil
+}
+
+// bigIntFromValue converts a value into an arbitrary precision integer, where
+// strings are parsed as decimal integers.
+func bigIntFromValue(v any) (*big.Int, error) {
+	switch t := value.ISanitize(v).(type) {
+	case string:
+		n, ok := new(big.Int).SetString(t, 10)
+		if !ok {
+			return nil, fmt.Errorf("failed to parse %q as a decimal integer", t)
+		}
+		return n, nil
+	case []byte:
+		return bigIntFromValue(string(t))
+	case int64:
+		return big.NewInt(t), nil
+	case uint64:
+		return new(big.Int).SetUint64(t), nil
+	case float64:
+		if t != math.Trunc(t) || math.IsInf(t, 0) {
+			return nil, fmt.Errorf("value %v is not an integer", t)
+		}
+		n, _ := big.NewFloat(t).Int(nil)
+		return n, nil
+	}
+	return nil, value.NewTypeError(v, value.TString, value.TInt)
+}
+
+func absUint64(n int64) uint64 {
+	if n < 0 {
+		return -uint64(n)
+	}
+	return uint64(n)
+}
+
+func gcdUint64(a, b uint64) uint64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+func gcd(a, b int64) (int64, error) {
+	g := gcdUint64(absUint64(a), absUint64(b))
+	if g > math.MaxInt64 {
+		return 0, fmt.Errorf("greatest common divisor of %v and %v overflows int64", a, b)
+	}
+	return int64(g), nil
+}
+
+func lcm(a, b int64) (int64, error) {
+	if a == 0 || b == 0 {
+		return 0, nil
+	}
+	ua, ub := absUint64(a), absUint64(b)
+	l := ua / gcdUint64(ua, ub)
+	if l > math.MaxInt64/ub {
+		return 0, fmt.Errorf("least common multiple of %v and %v overflows int64", a, b)
+	}
+	return int64(l * ub), nil
+}
+
+// primeFactorsMax is the largest value that can be factorized, which bounds
+// the trial division to one million iterations.
+const primeFactorsMax = 1_000_000_000_000
+
+func primeFactors(n int64) ([]any, error) {
+	if n < 1 || n > primeFactorsMax {
+		return nil, fmt.Errorf("value must be between 1 and %v, got %v", int64(primeFactorsMax), n)
+	}
+	factors := []any{}
+	for p := int64(2); p*p <= n; p++ {
+		for n%p == 0 {
+			factors = append(factors, p)
+			n /= p
+		}
+	}
+	if n > 1 {
+		factors = append(factors, n)
+	}
+	return factors, nil
+}
+
 func init() {
 	registerIntMethod(
 		"int64", "64-bit signed integer",
@@ -246,6 +529,216 @@ root.outs = this.ins.map_each(ele -> ele.abs())
 		panic(err)
 	}
 
+	if err := bloblang.RegisterMethodV2("ordinalize",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryNumbers).
+			Description(`Converts an integer into its English ordinal form as a string, such as `+"`1st`, `2nd`, `23rd` or `111th`"+`. Numbers ending in 11, 12 and 13 always use the suffix `+"`th`"+`. Non-integer numbers result in an error.`).
+			Param(bloblang.NewBoolParam("words").Description("Whether to spell the ordinal out in words, such as `first` or `twenty-third`.").Default(false)).
+			Example("", `root.places = this.places.map_each(p -> p.ordinalize())`,
+				[2]string{`{"places":[1,2,3,4,11,12,13,21,22,23,111]}`, `{"places":["1st","2nd","3rd","4th","11th","12th","13th","21st","22nd","23rd","111th"]}`}).
+			Example("", `root.places = this.places.map_each(p -> p.ordinalize(words: true))`,
+				[2]string{`{"places":[1,2,12,20,23,100,1001]}`, `{"places":["first","second","twelfth","twentieth","twenty-third","one hundredth","one thousand first"]}`}),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			words, err := args.GetBool("words")
+			if err != nil {
+				return nil, err
+			}
+			return bloblang.Int64Method(func(n int64) (any, error) {
+				return ordinalize(n, words), nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("humanize_number",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryNumbers).
+			Description(`Abbreviates a number into a short human readable string with a unit suffix of `+"`K`, `M`, `B` or `T`"+` (thousands, millions, billions and trillions), such as `+"`1.2M`"+` for `+"`1200000`"+`. The number is rounded to a given number of decimal places and any trailing zeros are removed. Negative numbers keep their sign, and numbers with an absolute value below one thousand (or 1024 in binary mode) are returned as a string without a suffix or rounding.`).
+			Param(bloblang.NewInt64Param("decimals").Description("The maximum number of decimal places to render.").Default(1)).
+			Param(bloblang.NewBoolParam("binary").Description("Whether to use powers of 1024 with the binary suffixes `Ki`, `Mi`, `Gi`, `Ti`, `Pi` and `Ei` rather than powers of 1000.").Default(false)).
+			Example("", `root.counts = this.counts.map_each(c -> c.humanize_number())`,
+				[2]string{`{"counts":[12,1200,1260000,-3400000000,999999]}`, `{"counts":["12","1.2K","1.3M","-3.4B","1M"]}`}).
+			Example("", `root.count = this.count.humanize_number(decimals: 2, binary: true)`,
+				[2]string{`{"count":1572864}`, `{"count":"1.5Mi"}`},
+				[2]string{`{"count":1000}`, `{"count":"1000"}`}),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			decimals, err := args.GetInt64("decimals")
+			if err != nil {
+				return nil, err
+			}
+			if decimals < 0 {
+				return nil, fmt.Errorf("decimals must not be negative, got %v", decimals)
+			}
+			binary, err := args.GetBool("binary")
+			if err != nil {
+				return nil, err
+			}
+			return bloblang.Float64Method(func(f float64) (any, error) {
+				return humanizeNumber(f, decimals, binary), nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("to_roman",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryNumbers).
+			Description(`Converts an integer between 1 and 3999 into an upper case Roman numeral string. Values outside of this range result in an error.`).
+			Example("", `root.numerals = this.values.map_each(v -> v.to_roman())`,
+				[2]string{`{"values":[1,4,9,14,40,90,400,1994,3999]}`, `{"numerals":["I","IV","IX","XIV","XL","XC","CD","MCMXCIV","MMMCMXCIX"]}`}),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return bloblang.Int64Method(func(n int64) (any, error) {
+				return toRoman(n)
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("from_roman",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryStrings).
+			Description(`Parses a Roman numeral string into an integer. Numerals are case insensitive but must be in their canonical form, malformed numerals such as `+"`IIII` or `IC`"+` result in an error.`).
+			Example("", `root.values = this.numerals.map_each(n -> n.from_roman())`,
+				[2]string{`{"numerals":["I","iv","MCMXCIV","MMMCMXCIX"]}`, `{"values":[1,4,1994,3999]}`}),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return bloblang.StringMethod(func(s string) (any, error) {
+				return fromRoman(s)
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("big_to_base",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryNumbers).
+			Description(`Converts an arbitrary precision integer into a string representation of it in a given base, using lower case letters for digit values of 10 and above, followed by upper case letters for digit values of 36 and above. The target can either be a number or a string containing a decimal integer of any size. Large numbers should be provided as strings, as numbers beyond 2^53 may have already lost precision when they were parsed as floats.`).
+			Param(bloblang.NewInt64Param("radix").Description("The base to convert into, between 2 and 62.")).
+			Example("", `root.hex = this.id.big_to_base(16)`,
+				[2]string{`{"id":"340282366920938463463374607431768211455"}`, `{"hex":"ffffffffffffffffffffffffffffffff"}`}).
+			Example("", `root.short = this.id.big_to_base(62)`,
+				[2]string{`{"id":"123456789012345678901234567890"}`, `{"short":"2AyLS9BKAMjjsWHR0"}`}),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			radix, err := args.GetInt64("radix")
+			if err != nil {
+				return nil, err
+			}
+			if err := checkBigRadix(radix); err != nil {
+				return nil, err
+			}
+			return func(v any) (any, error) {
+				n, err := bigIntFromValue(v)
+				if err != nil {
+					return nil, err
+				}
+				return n.Text(int(radix)), nil
+			}, nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("big_from_base",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryNumbers).
+			Description(`Parses a string representation of an arbitrary precision integer in a given base and returns it as a decimal string, which prevents large values from overflowing or losing precision. For bases up to 36 letters are case insensitive, for larger bases lower case letters represent digit values 10 to 35 and upper case letters 36 to 61. The result can be converted into a number with `+"<<int64, `int64`>>"+` if it is known to fit.`).
+			Param(bloblang.NewInt64Param("radix").Description("The base to convert from, between 2 and 62.")).
+			Example("", `root.id = this.hex.big_from_base(16)`,
+				[2]string{`{"hex":"ffffffffffffffffffffffffffffffff"}`, `{"id":"340282366920938463463374607431768211455"}`}).
+			Example("", `root.id = this.short.big_from_base(62)`,
+				[2]string{`{"short":"2AyLS9BKAMjjsWHR0"}`, `{"id":"123456789012345678901234567890"}`}),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			radix, err := args.GetInt64("radix")
+			if err != nil {
+				return nil, err
+			}
+			if err := checkBigRadix(radix); err != nil {
+				return nil, err
+			}
+			return bloblang.StringMethod(func(s string) (any, error) {
+				n, ok := new(big.Int).SetString(s, int(radix))
+				if !ok {
+					return nil, fmt.Errorf("failed to parse %q as a base %v integer", s, radix)
+				}
+				return n.String(), nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("gcd",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryNumbers).
+			Description(`Returns the greatest common divisor of an integer and another integer. The absolute values of both integers are used, and the greatest common divisor of zero and zero is zero.`).
+			Param(bloblang.NewInt64Param("other").Description("The other integer.")).
+			Example("", `root.gcd = this.a.gcd(this.b)`,
+				[2]string{`{"a":12,"b":18}`, `{"gcd":6}`},
+				[2]string{`{"a":-12,"b":0}`, `{"gcd":12}`}).
+			Example("Reduce a fraction to its lowest terms.", `let d = this.numerator.gcd(this.denominator)
+root.numerator = this.numerator / $d
+root.denominator = this.denominator / $d`,
+				[2]string{`{"numerator":6,"denominator":8}`, `{"denominator":4,"numerator":3}`}),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			other, err := args.GetInt64("other")
+			if err != nil {
+				return nil, err
+			}
+			return bloblang.Int64Method(func(n int64) (any, error) {
+				return gcd(n, other)
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("lcm",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryNumbers).
+			Description(`Returns the least common multiple of an integer and another integer. The absolute values of both integers are used, and the least common multiple is zero when either integer is zero. An error is returned if the result would overflow an int64.`).
+			Param(bloblang.NewInt64Param("other").Description("The other integer.")).
+			Example("", `root.lcm = this.a.lcm(this.b)`,
+				[2]string{`{"a":4,"b":6}`, `{"lcm":12}`},
+				[2]string{`{"a":-3,"b":5}`, `{"lcm":15}`},
+				[2]string{`{"a":0,"b":5}`, `{"lcm":0}`}),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			other, err := args.GetInt64("other")
+			if err != nil {
+				return nil, err
+			}
+			return bloblang.Int64Method(func(n int64) (any, error) {
+				return lcm(n, other)
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("is_prime",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryNumbers).
+			Description(`Checks whether an integer is a prime number, returning a boolean. Integers less than two are not prime. The check is exact for all int64 values.`).
+			Example("", `root.primes = this.values.filter(v -> v.is_prime())`,
+				[2]string{`{"values":[-7,0,1,2,3,4,5,9,11,7919,7921]}`, `{"primes":[2,3,5,11,7919]}`}),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return bloblang.Int64Method(func(n int64) (any, error) {
+				return n > 1 && big.NewInt(n).ProbablyPrime(0), nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("prime_factors",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryNumbers).
+			Description(`Returns an array of the prime factors of an integer in ascending order, where repeated factors are included once for each time they divide the integer. The prime factors of one are an empty array. In order to bound the cost of factorization an error is returned for integers that are less than one or greater than one trillion (10^12).`).
+			Example("", `root.factors = this.value.prime_factors()`,
+				[2]string{`{"value":360}`, `{"factors":[2,2,2,3,3,5]}`},
+				[2]string{`{"value":97}`, `{"factors":[97]}`},
+				[2]string{`{"value":1}`, `{"factors":[]}`}),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return bloblang.Int64Method(func(n int64) (any, error) {
+				return primeFactors(n)
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
 	//------------------------------------------------------------------------------
 
 	if err := bloblang.RegisterFunctionV2("pi",
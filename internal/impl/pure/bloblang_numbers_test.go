@@ -0,0 +1,198 @@
+// Copyright 2025 Redpanda Data, Inc.
+
+package pure
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/redpanda-data/benthos/v4/public/bloblang"
+)
+
+func TestOrdinalize(t *testing.T) {
+	for _, test := range []struct {
+		input    int64
+		output   string
+		outWords string
+	}{
+		{input: 0, output: "0th", outWords: "zeroth"},
+		{input: 1, output: "1st", outWords: "first"},
+		{input: 2, output: "2nd", outWords: "second"},
+		{input: 3, output: "3rd", outWords: "third"},
+		{input: 4, output: "4th", outWords: "fourth"},
+		{input: 11, output: "11th", outWords: "eleventh"},
+		{input: 12, output: "12th", outWords: "twelfth"},
+		{input: 13, output: "13th", outWords: "thirteenth"},
+		{input: 21, output: "21st", outWords: "twenty-first"},
+		{input: 40, output: "40th", outWords: "fortieth"},
+		{input: 111, output: "111th", outWords: "one hundred eleventh"},
+		{input: 112, output: "112th", outWords: "one hundred twelfth"},
+		{input: 123, output: "123rd", outWords: "one hundred twenty-third"},
+		{input: 1000000, output: "1000000th", outWords: "one millionth"},
+		{input: 2000005, output: "2000005th", outWords: "two million fifth"},
+		{input: -2, output: "-2nd", outWords: "minus second"},
+		{input: math.MinInt64, output: "-9223372036854775808th", outWords: "minus nine quintillion two hundred twenty-three quadrillion three hundred seventy-two trillion thirty-six billion eight hundred fifty-four million seven hundred seventy-five thousand eight hundred eighth"},
+	} {
+		assert.Equal(t, test.output, ordinalize(test.input, false), test.input)
+		assert.Equal(t, test.outWords, ordinalize(test.input, true), test.input)
+	}
+}
+
+func TestHumanizeNumber(t *testing.T) {
+	for _, test := range []struct {
+		input    float64
+		decimals int64
+		binary   bool
+		output   string
+	}{
+		{input: 0, decimals: 1, output: "0"},
+		{input: 999, decimals: 1, output: "999"},
+		{input: 12.345, decimals: 1, output: "12.345"},
+		{input: 1000, decimals: 1, output: "1K"},
+		{input: 1200, decimals: 1, output: "1.2K"},
+		{input: 1_200_000, decimals: 1, output: "1.2M"},
+		{input: 1_234_567, decimals: 2, output: "1.23M"},
+		{input: 1_234_567, decimals: 0, output: "1M"},
+		{input: 999_950, decimals: 1, output: "1M"},
+		{input: 5_000_000_000, decimals: 1, output: "5B"},
+		{input: 7_100_000_000_000, decimals: 1, output: "7.1T"},
+		{input: 7_100_000_000_000_000, decimals: 1, output: "7100T"},
+		{input: -1500, decimals: 1, output: "-1.5K"},
+		{input: -12, decimals: 1, output: "-12"},
+		{input: 1000, decimals: 1, binary: true, output: "1000"},
+		{input: 1024, decimals: 1, binary: true, output: "1Ki"},
+		{input: 1536, decimals: 1, binary: true, output: "1.5Ki"},
+		{input: 3 * 1024 * 1024 * 1024, decimals: 1, binary: true, output: "3Gi"},
+	} {
+		assert.Equal(t, test.output, humanizeNumber(test.input, test.decimals, test.binary), test.input)
+	}
+}
+
+func TestRomanNumerals(t *testing.T) {
+	for n := int64(1); n <= 3999; n++ {
+		s, err := toRoman(n)
+		require.NoError(t, err)
+		v, err := fromRoman(s)
+		require.NoError(t, err)
+		require.Equal(t, n, v, s)
+	}
+
+	for _, n := range []int64{0, -1, 4000} {
+		_, err := toRoman(n)
+		assert.Error(t, err, n)
+	}
+
+	for _, s := range []string{"", "IIII", "IC", "VX", "MMMM", "XIIV", "ABC", "I I"} {
+		_, err := fromRoman(s)
+		assert.Error(t, err, s)
+	}
+
+	v, err := fromRoman("mcmxciv")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1994), v)
+}
+
+func TestBigBaseConversion(t *testing.T) {
+	exec, err := bloblang.Parse(`root.hex = this.id.big_to_base(16)
+root.b62 = this.id.big_to_base(62)
+root.from_hex = root.hex.big_from_base(16)
+root.from_b62 = root.b62.big_from_base(62)`)
+	require.NoError(t, err)
+
+	for _, id := range []any{"340282366920938463463374607431768211455", "-255", int64(255), uint64(math.MaxUint64), float64(1024), "0"} {
+		res, err := exec.Query(map[string]any{"id": id})
+		require.NoError(t, err, id)
+
+		resMap := res.(map[string]any)
+		n, err := bigIntFromValue(id)
+		require.NoError(t, err, id)
+		assert.Equal(t, n.String(), resMap["from_hex"], id)
+		assert.Equal(t, n.String(), resMap["from_b62"], id)
+	}
+
+	for _, id := range []any{"12.5", "nope", 1.5, true} {
+		_, err := exec.Query(map[string]any{"id": id})
+		assert.Error(t, err, id)
+	}
+
+	_, err = bloblang.Parse(`root = this.big_to_base(63)`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "radix must be between 2 and 62")
+
+	_, err = bloblang.Parse(`root = this.big_from_base(1)`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "radix must be between 2 and 62")
+}
+
+func TestGCDLCM(t *testing.T) {
+	for _, test := range []struct {
+		a, b     int64
+		gcd, lcm int64
+	}{
+		{a: 0, b: 0, gcd: 0, lcm: 0},
+		{a: 0, b: 7, gcd: 7, lcm: 0},
+		{a: 12, b: 18, gcd: 6, lcm: 36},
+		{a: -12, b: 18, gcd: 6, lcm: 36},
+		{a: -12, b: -18, gcd: 6, lcm: 36},
+		{a: 7, b: 13, gcd: 1, lcm: 91},
+		{a: math.MaxInt64, b: math.MaxInt64, gcd: math.MaxInt64, lcm: math.MaxInt64},
+	} {
+		g, err := gcd(test.a, test.b)
+		require.NoError(t, err)
+		assert.Equal(t, test.gcd, g, "gcd(%v, %v)", test.a, test.b)
+
+		l, err := lcm(test.a, test.b)
+		require.NoError(t, err)
+		assert.Equal(t, test.lcm, l, "lcm(%v, %v)", test.a, test.b)
+	}
+
+	_, err := gcd(math.MinInt64, 0)
+	assert.Error(t, err)
+
+	_, err = lcm(math.MaxInt64, 2)
+	assert.Error(t, err)
+}
+
+func TestPrimes(t *testing.T) {
+	exec, err := bloblang.Parse(`root = this.is_prime()`)
+	require.NoError(t, err)
+
+	for n, prime := range map[int64]bool{
+		math.MinInt64:       false,
+		-7:                  false,
+		0:                   false,
+		1:                   false,
+		2:                   true,
+		4:                   false,
+		561:                 false,
+		7919:                true,
+		1_000_000_007:       true,
+		math.MaxInt64:       false,
+		9223372036854775783: true,
+	} {
+		res, err := exec.Query(n)
+		require.NoError(t, err)
+		assert.Equal(t, prime, res, n)
+	}
+
+	for n, factors := range map[int64][]any{
+		1:                 {},
+		2:                 {int64(2)},
+		360:               {int64(2), int64(2), int64(2), int64(3), int64(3), int64(5)},
+		1_000_000_007:     {int64(1_000_000_007)},
+		999_999_999_989:   {int64(999_999_999_989)},
+		1_000_000_000_000: {int64(2), int64(2), int64(2), int64(2), int64(2), int64(2), int64(2), int64(2), int64(2), int64(2), int64(2), int64(2), int64(5), int64(5), int64(5), int64(5), int64(5), int64(5), int64(5), int64(5), int64(5), int64(5), int64(5), int64(5)},
+	} {
+		res, err := primeFactors(n)
+		require.NoError(t, err)
+		assert.Equal(t, factors, res, n)
+	}
+
+	for _, n := range []int64{0, -4, 1_000_000_000_001} {
+		_, err := primeFactors(n)
+		assert.Error(t, err, n)
+	}
+}
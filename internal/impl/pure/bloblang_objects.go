@@ -5,6 +5,9 @@ package pure
 import (
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/Jeffail/gabs/v2"
 
@@ -13,6 +16,78 @@ import (
 	"github.com/redpanda-data/benthos/v4/public/bloblang"
 )
 
+var coerceTypes = map[string]func(v any) (any, error){
+	"int": func(v any) (any, error) {
+		return value.IToInt(v)
+	},
+	"float": func(v any) (any, error) {
+		return value.IToNumber(v)
+	},
+	"bool": func(v any) (any, error) {
+		return value.IToBool(v)
+	},
+	"string": func(v any) (any, error) {
+		return value.IToString(v), nil
+	},
+	"timestamp": func(v any) (any, error) {
+		return value.IGetTimestamp(v)
+	},
+}
+
+type coercePath struct {
+	path   string
+	coerce func(v any) (any, error)
+}
+
+type coerceSpec []coercePath
+
+func newCoerceSpec(m map[string]any) (coerceSpec, error) {
+	paths := make([]string, 0, len(m))
+	for k := range m {
+		paths = append(paths, k)
+	}
+	sort.Strings(paths)
+
+	spec := make(coerceSpec, 0, len(paths))
+	for _, p := range paths {
+		typeStr, err := value.IGetString(m[p])
+		if err != nil {
+			return nil, fmt.Errorf("path %v: %w", p, err)
+		}
+		fn, exists := coerceTypes[typeStr]
+		if !exists {
+			return nil, fmt.Errorf("path %v: unrecognised type: %v", p, typeStr)
+		}
+		spec = append(spec, coercePath{path: p, coerce: fn})
+	}
+	return spec, nil
+}
+
+func (s coerceSpec) coerce(m map[string]any) map[string]any {
+	root := gabs.Wrap(value.IClone(m))
+	errs := []any{}
+	for _, p := range s {
+		path := gabs.DotPathToSlice(p.path)
+		v := root.S(path...).Data()
+		if v == nil {
+			continue
+		}
+		coerced, err := p.coerce(v)
+		if err != nil {
+			errs = append(errs, map[string]any{
+				"path":  p.path,
+				"error": err.Error(),
+			})
+			continue
+		}
+		_, _ = root.Set(coerced, path...)
+	}
+	return map[string]any{
+		"value":  root.Data(),
+		"errors": errs,
+	}
+}
+
 func init() {
 	if err := bloblang.RegisterMethodV2("squash",
 		bloblang.NewPluginSpec().
@@ -150,6 +225,512 @@ If a key within a nested path does not exist then it is ignored.`).
 		}); err != nil {
 		panic(err)
 	}
+
+	if err := bloblang.RegisterMethodV2("interleave",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryObjectAndArray).
+			Variadic().
+			Description("Interleaves an array value with one or more argument arrays by taking one element from each array in turn, starting with the target, until all arrays are exhausted. Arrays may differ in length, in which case exhausted arrays are skipped. Unlike `zip` the result is a single flat array.").
+			Example("", `root.feed = this.news.interleave(this.sports, this.weather)`,
+				[2]string{
+					`{"news":["n1","n2","n3"],"sports":["s1"],"weather":["w1","w2"]}`,
+					`{"feed":["n1","s1","w1","n2","w2","n3"]}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			argAnys := args.AsSlice()
+			argSlices := make([][]any, len(argAnys))
+			tally, longest := 0, 0
+			for i, a := range argAnys {
+				var ok bool
+				if argSlices[i], ok = a.([]any); !ok {
+					return nil, value.NewTypeError(a, value.TArray)
+				}
+				tally += len(argSlices[i])
+				longest = max(longest, len(argSlices[i]))
+			}
+
+			return bloblang.ArrayMethod(func(i []any) (any, error) {
+				resSlice := make([]any, 0, len(i)+tally)
+				for offset := 0; offset < max(longest, len(i)); offset++ {
+					if offset < len(i) {
+						resSlice = append(resSlice, i[offset])
+					}
+					for _, s := range argSlices {
+						if offset < len(s) {
+							resSlice = append(resSlice, s[offset])
+						}
+					}
+				}
+				return resSlice, nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("cartesian",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryObjectAndArray).
+			Description("Returns the cartesian product of an array value and an array of argument arrays, which is an array containing every combination of one element from each array, where each combination is an array with the element of the target first. The combinations are ordered such that the elements of the last array vary fastest. If any of the arrays are empty then the result is an empty array. As the size of the result grows very quickly with the number and length of arrays an error is returned when it would exceed a maximum number of combinations.\n\nUnlike `interleave` the argument arrays are provided as a single array rather than as separate arguments, as variadic methods cannot also accept the named `max_size` parameter.").
+			Param(bloblang.NewAnyParam("others").Description("An array of arrays to combine with the target.")).
+			Param(bloblang.NewInt64Param("max_size").Description("The maximum number of combinations allowed in the result.").Default(10000)).
+			Example("", `root.variants = this.sizes.cartesian([this.colours])`,
+				[2]string{
+					`{"sizes":["S","M"],"colours":["red","blue"]}`,
+					`{"variants":[["S","red"],["S","blue"],["M","red"],["M","blue"]]}`,
+				},
+			).
+			Example("", `root.error = this.a.cartesian(others: [this.b, this.c], max_size: 5).catch(err -> err)`,
+				[2]string{
+					`{"a":[1,2],"b":[3,4],"c":[5,6]}`,
+					`{"error":"cartesian product would exceed max_size of 5 combinations"}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			othersV, err := args.Get("others")
+			if err != nil {
+				return nil, err
+			}
+			othersArr, ok := othersV.([]any)
+			if !ok {
+				return nil, value.NewTypeError(othersV, value.TArray)
+			}
+			others := make([][]any, len(othersArr))
+			for i, o := range othersArr {
+				if others[i], ok = o.([]any); !ok {
+					return nil, fmt.Errorf("others index %v: %w", i, value.NewTypeError(o, value.TArray))
+				}
+			}
+			maxSize, err := args.GetInt64("max_size")
+			if err != nil {
+				return nil, err
+			}
+			return bloblang.ArrayMethod(func(i []any) (any, error) {
+				return cartesianProduct(append([][]any{i}, others...), maxSize)
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("matches_shape",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryObjectAndArray).
+			Description(`Checks whether the target value has the same shape as a reference value, returning a boolean. Scalar values are not compared, instead only their types (`+"`string`, `number`, `bool`, `null`, etc"+`) must match. Objects match when every key of the reference exists in the target with a value of a matching shape. Arrays match when every element of the target matches the shape of the first element of the reference array, an empty reference array matches any array.
+
+This makes it possible to detect schema drift by comparing a message against a known good example without the need for a JSON Schema.`).
+			Param(bloblang.NewAnyParam("reference").Description("A value to compare the shape of the target against.")).
+			Param(bloblang.NewBoolParam("strict").Description("Whether objects of the target are prohibited from containing keys that are not present in the reference.").Default(false)).
+			Example("", `root.valid = this.matches_shape({"id":"","tags":[""],"meta":{"score":0}})`,
+				[2]string{
+					`{"id":"foo","tags":["a","b"],"meta":{"score":5.5}}`,
+					`{"valid":true}`,
+				},
+				[2]string{
+					`{"id":"foo","tags":["a",3],"meta":{"score":5.5}}`,
+					`{"valid":false}`,
+				},
+				[2]string{
+					`{"id":"foo","tags":[]}`,
+					`{"valid":false}`,
+				},
+			).
+			Example("With `strict` enabled any keys missing from the reference result in a mismatch.", `root.valid = this.matches_shape(reference: {"id":""}, strict: true)`,
+				[2]string{
+					`{"id":"foo"}`,
+					`{"valid":true}`,
+				},
+				[2]string{
+					`{"id":"foo","extra":"bar"}`,
+					`{"valid":false}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			reference, err := args.Get("reference")
+			if err != nil {
+				return nil, err
+			}
+			strict, err := args.GetBool("strict")
+			if err != nil {
+				return nil, err
+			}
+			return func(v any) (any, error) {
+				return matchesShape(v, reference, strict), nil
+			}, nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("diff_keys",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryObjectAndArray).
+			Description(`Compares the target object against another object and returns an object containing the xref:configuration:field_paths.adoc[field paths] that differ between them. The field `+"`added`"+` lists paths that exist in the argument but not the target, `+"`removed`"+` lists paths that exist in the target but not the argument, and `+"`changed`"+` lists paths that exist in both but with different values. Nested objects are walked recursively, whereas all other values (including arrays) are compared as a whole. Each list is sorted.`).
+			Param(bloblang.NewAnyParam("other").Description("An object to compare the target against.")).
+			Example("", `root = this.before.diff_keys(this.after)`,
+				[2]string{
+					`{"before":{"id":"foo","name":"bar","meta":{"a":1,"b":2}},"after":{"id":"foo","name":"baz","meta":{"b":2,"c":3}}}`,
+					`{"added":["meta.c"],"changed":["name"],"removed":["meta.a"]}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			otherV, err := args.Get("other")
+			if err != nil {
+				return nil, err
+			}
+			other, ok := otherV.(map[string]any)
+			if !ok {
+				return nil, value.NewTypeError(otherV, value.TObject)
+			}
+			return bloblang.ObjectMethod(func(i map[string]any) (any, error) {
+				var added, removed, changed []string
+				diffKeys(nil, i, other, &added, &removed, &changed)
+				return map[string]any{
+					"added":   sortedPathsToAny(added),
+					"removed": sortedPathsToAny(removed),
+					"changed": sortedPathsToAny(changed),
+				}, nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("diff",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryObjectAndArray).
+			Description(`Compares the target array against another array and returns an edit script describing how to transform the target into the argument, computed from their longest common subsequence. The result is an array of operations in order, each an object with the fields `+"`op`"+` (one of `+"`equal`, `delete` or `insert`"+`), `+"`value`"+` and `+"`index`"+`, which is the index of the value within the target for `+"`equal` and `delete`"+` operations, and within the argument for `+"`insert`"+` operations. Where both a deletion and an insertion are possible deletions are listed first.
+
+Elements are equal when they're deeply equal, where numbers are compared irrespective of their representation (float versus integer) and objects and arrays are compared element by element. As the comparison grows with the product of the lengths of both arrays an error is returned if that product exceeds 10,000,000. In order to compare the keys of objects use `+"<<diff_keys, `diff_keys`>>"+` instead.`).
+			Param(bloblang.NewAnyParam("other").Description("An array to compare the target against.")).
+			Example("", `root.edits = this.before.diff(this.after).filter(e -> e.op != "equal")`,
+				[2]string{
+					`{"before":["a","b","c","d"],"after":["a","c","d","e"]}`,
+					`{"edits":[{"index":1,"op":"delete","value":"b"},{"index":3,"op":"insert","value":"e"}]}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			otherV, err := args.Get("other")
+			if err != nil {
+				return nil, err
+			}
+			other, ok := otherV.([]any)
+			if !ok {
+				return nil, value.NewTypeError(otherV, value.TArray)
+			}
+			return bloblang.ArrayMethod(func(arr []any) (any, error) {
+				return diffArrays(arr, other)
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("coerce",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryObjectAndArray).
+			Description(`Coerces the values of an object at a set of xref:configuration:field_paths.adoc[field paths] into specific types, where the spec is an object of field paths to one of the types `+"`int`, `float`, `bool`, `string` or `timestamp`"+`. Rather than failing on the first value that cannot be coerced, all paths are attempted and an object is returned containing the field `+"`value`"+`, the coerced object, and `+"`errors`"+`, an array of objects with the fields `+"`path` and `error`"+` for each value that could not be coerced, sorted by path. Values that could not be coerced are left unchanged, and paths that do not exist or contain `+"`null`"+` are ignored.`).
+			Param(bloblang.NewAnyParam("spec").Description("An object of field paths to the type their values should be coerced into.")).
+			Example("", `root = this.coerce({"id":"int","price":"float","active":"bool","created_at":"timestamp","tags.0":"string"})`,
+				[2]string{
+					`{"id":"42","price":"9.99","active":"true","created_at":"2023-11-14T22:13:20Z","tags":[5]}`,
+					`{"errors":[],"value":{"active":true,"created_at":"2023-11-14T22:13:20Z","id":42,"price":9.99,"tags":["5"]}}`,
+				},
+				[2]string{
+					`{"id":"nope","price":"9.99","active":"yes"}`,
+					`{"errors":[{"error":"expected bool value, got string (\"yes\")","path":"active"},{"error":"strconv.ParseInt: parsing \"nope\": invalid syntax","path":"id"}],"value":{"active":"yes","id":"nope","price":9.99}}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			specV, err := args.Get("spec")
+			if err != nil {
+				return nil, err
+			}
+			specObj, ok := specV.(map[string]any)
+			if !ok {
+				return nil, value.NewTypeError(specV, value.TObject)
+			}
+			spec, err := newCoerceSpec(specObj)
+			if err != nil {
+				return nil, err
+			}
+			return bloblang.ObjectMethod(func(i map[string]any) (any, error) {
+				return spec.coerce(i), nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("common_prefix",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryObjectAndArray).
+			Description(`Returns the longest prefix shared by all strings of an array, or an empty string if there isn't one or the array is empty. Strings are only ever split between characters, never within a multi-byte character.`).
+			Example("", `root.root = this.paths.common_prefix()`,
+				[2]string{`{"paths":["/var/log/app/a.log","/var/log/app/b.log","/var/log/audit.log"]}`, `{"root":"/var/log/a"}`},
+				[2]string{`{"paths":["foo","bar"]}`, `{"root":""}`},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return bloblang.ArrayMethod(func(arr []any) (any, error) {
+				strs, err := stringsFromArray(arr)
+				if err != nil {
+					return nil, err
+				}
+				return commonPrefix(strs), nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("common_suffix",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryObjectAndArray).
+			Description(`Returns the longest suffix shared by all strings of an array, or an empty string if there isn't one or the array is empty. Strings are only ever split between characters, never within a multi-byte character.`).
+			Example("", `root.domain = this.hosts.common_suffix()`,
+				[2]string{`{"hosts":["api.eu.example.com","web.us.example.com"]}`, `{"domain":".example.com"}`},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return bloblang.ArrayMethod(func(arr []any) (any, error) {
+				strs, err := stringsFromArray(arr)
+				if err != nil {
+					return nil, err
+				}
+				return commonSuffix(strs), nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("jaccard",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryObjectAndArray).
+			Description(`Computes the https://en.wikipedia.org/wiki/Jaccard_index[Jaccard similarity^] between the target array and another array, where both are treated as sets, which is the number of elements within their intersection divided by the number of elements within their union. The result is a float between `+"`0`"+`, where the sets share no elements, and `+"`1`"+`, where they are identical, and two empty sets are considered identical. Duplicate elements are ignored and elements are compared by their string representation.`).
+			Param(bloblang.NewAnyParam("other").Description("An array to compare the target against.")).
+			Param(bloblang.NewBoolParam("case_insensitive").Description("Whether string elements are compared case insensitively.").Default(false)).
+			Example("", `root.similarity = this.a.split(" ").jaccard(this.b.split(" "), true)`,
+				[2]string{
+					`{"a":"the quick brown fox","b":"The slow brown dog"}`,
+					`{"similarity":0.3333333333333333}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			otherV, err := args.Get("other")
+			if err != nil {
+				return nil, err
+			}
+			other, ok := otherV.([]any)
+			if !ok {
+				return nil, value.NewTypeError(otherV, value.TArray)
+			}
+			caseInsensitive, err := args.GetBool("case_insensitive")
+			if err != nil {
+				return nil, err
+			}
+			otherSet := arraySet(other, caseInsensitive)
+			return bloblang.ArrayMethod(func(arr []any) (any, error) {
+				return jaccard(arraySet(arr, caseInsensitive), otherSet), nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	setOpSpec := func(description string) *bloblang.PluginSpec {
+		return bloblang.NewPluginSpec().
+			Category(query.MethodCategoryObjectAndArray).
+			Description(description + ` Both arrays are treated as sets, where elements are compared by their string representation, and so the result contains no duplicates and retains the order in which elements first appear.`).
+			Param(bloblang.NewAnyParam("other").Description("An array to combine with the target."))
+	}
+	setOpCtor := func(op func(target, other []any) []any) bloblang.MethodConstructorV2 {
+		return func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			otherV, err := args.Get("other")
+			if err != nil {
+				return nil, err
+			}
+			other, ok := otherV.([]any)
+			if !ok {
+				return nil, value.NewTypeError(otherV, value.TArray)
+			}
+			return bloblang.ArrayMethod(func(arr []any) (any, error) {
+				return op(arr, other), nil
+			}), nil
+		}
+	}
+
+	if err := bloblang.RegisterMethodV2("set_union",
+		setOpSpec(`Returns the elements that exist in either the target array or the argument array, with the elements of the target first.`).
+			Example("", `root.tags = this.a.set_union(this.b)`,
+				[2]string{`{"a":["x","y","x"],"b":["z","y"]}`, `{"tags":["x","y","z"]}`},
+			),
+		setOpCtor(func(target, other []any) []any {
+			return setFilter(append(append([]any{}, target...), other...), nil)
+		})); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("set_intersection",
+		setOpSpec(`Returns the elements of the target array that also exist in the argument array.`).
+			Example("", `root.tags = this.a.set_intersection(this.b)`,
+				[2]string{`{"a":["x","y","z","y"],"b":["z","y","w"]}`, `{"tags":["y","z"]}`},
+			),
+		setOpCtor(func(target, other []any) []any {
+			otherSet := arraySet(other, false)
+			return setFilter(target, func(k string) bool {
+				_, exists := otherSet[k]
+				return exists
+			})
+		})); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("set_difference",
+		setOpSpec(`Returns the elements of the target array that do not exist in the argument array.`).
+			Example("", `root.tags = this.a.set_difference(this.b)`,
+				[2]string{`{"a":["x","y","z","x"],"b":["z","y","w"]}`, `{"tags":["x"]}`},
+			),
+		setOpCtor(func(target, other []any) []any {
+			otherSet := arraySet(other, false)
+			return setFilter(target, func(k string) bool {
+				_, exists := otherSet[k]
+				return !exists
+			})
+		})); err != nil {
+		panic(err)
+	}
+}
+
+func stringsFromArray(arr []any) ([]string, error) {
+	strs := make([]string, len(arr))
+	for i, v := range arr {
+		s, err := value.IGetString(v)
+		if err != nil {
+			return nil, fmt.Errorf("index %v: %w", i, err)
+		}
+		strs[i] = s
+	}
+	return strs, nil
+}
+
+// commonPrefix returns the longest prefix shared by all strings, only ever
+// splitting strings at character boundaries.
+func commonPrefix(strs []string) string {
+	if len(strs) == 0 {
+		return ""
+	}
+	prefix := strs[0]
+	for _, s := range strs[1:] {
+		end := 0
+		for i, r := range prefix {
+			if !strings.HasPrefix(s[i:], string(r)) {
+				break
+			}
+			end = i + utf8.RuneLen(r)
+		}
+		prefix = prefix[:end]
+	}
+	return prefix
+}
+
+// commonSuffix returns the longest suffix shared by all strings, only ever
+// splitting strings at character boundaries.
+func commonSuffix(strs []string) string {
+	if len(strs) == 0 {
+		return ""
+	}
+	suffix := strs[0]
+	for _, s := range strs[1:] {
+		start := len(suffix)
+		for start > 0 {
+			r, size := utf8.DecodeLastRuneInString(suffix[:start])
+			if !strings.HasSuffix(s, suffix[start-size:]) || (r == utf8.RuneError && size == 1) {
+				break
+			}
+			start -= size
+		}
+		suffix = suffix[start:]
+	}
+	return suffix
+}
+
+// setKey returns the key by which an element of an array is identified when
+// the array is treated as a set.
+func setKey(v any, caseInsensitive bool) string {
+	if s, ok := v.(string); ok && caseInsensitive {
+		return strings.ToLower(s)
+	}
+	return value.IToString(v)
+}
+
+func arraySet(arr []any, caseInsensitive bool) map[string]struct{} {
+	set := make(map[string]struct{}, len(arr))
+	for _, v := range arr {
+		set[setKey(v, caseInsensitive)] = struct{}{}
+	}
+	return set
+}
+
+// setFilter returns the elements of an array that satisfy a filter on their set
+// keys, with duplicates removed and in the order of their first appearance. A
+// nil filter keeps all elements.
+func setFilter(arr []any, keep func(k string) bool) []any {
+	seen := make(map[string]struct{}, len(arr))
+	res := make([]any, 0, len(arr))
+	for _, v := range arr {
+		k := setKey(v, false)
+		if _, exists := seen[k]; exists {
+			continue
+		}
+		seen[k] = struct{}{}
+		if keep == nil || keep(k) {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+func jaccard(a, b map[string]struct{}) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	intersection := 0
+	for k := range a {
+		if _, exists := b[k]; exists {
+			intersection++
+		}
+	}
+	return float64(intersection) / float64(len(a)+len(b)-intersection)
+}
+
+func cartesianProduct(arrs [][]any, maxSize int64) ([]any, error) {
+	size := int64(1)
+	for _, arr := range arrs {
+		if len(arr) == 0 {
+			return []any{}, nil
+		}
+	}
+	for _, arr := range arrs {
+		if size > maxSize/int64(len(arr)) {
+			return nil, fmt.Errorf("cartesian product would exceed max_size of %v combinations", maxSize)
+		}
+		size *= int64(len(arr))
+	}
+
+	res := make([]any, 0, size)
+	indexes := make([]int, len(arrs))
+	for {
+		combination := make([]any, len(arrs))
+		for j, arr := range arrs {
+			combination[j] = arr[indexes[j]]
+		}
+		res = append(res, combination)
+
+		// Advance the indexes like an odometer, with the last array varying
+		// fastest.
+		j := len(arrs) - 1
+		for ; j >= 0; j-- {
+			if indexes[j]++; indexes[j] < len(arrs[j]) {
+				break
+			}
+			indexes[j] = 0
+		}
+		if j < 0 {
+			return res, nil
+		}
+	}
 }
 
 func mapWith(m map[string]any, paths [][]string) map[string]any {
@@ -180,3 +761,131 @@ func mapWith(m map[string]any, paths [][]string) map[string]any {
 	}
 	return newMap
 }
+
+func matchesShape(v, reference any, strict bool) bool {
+	switch ref := reference.(type) {
+	case map[string]any:
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return false
+		}
+		if strict {
+			for k := range obj {
+				if _, exists := ref[k]; !exists {
+					return false
+				}
+			}
+		}
+		for k, refV := range ref {
+			objV, exists := obj[k]
+			if !exists || !matchesShape(objV, refV, strict) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		arr, ok := v.([]any)
+		if !ok {
+			return false
+		}
+		if len(ref) == 0 {
+			return true
+		}
+		for _, ele := range arr {
+			if !matchesShape(ele, ref[0], strict) {
+				return false
+			}
+		}
+		return true
+	}
+	return value.ITypeOf(v) == value.ITypeOf(reference)
+}
+
+func diffKeys(path []string, from, to map[string]any, added, removed, changed *[]string) {
+	for k, fromV := range from {
+		keyPath := append(path[:len(path):len(path)], k)
+		toV, exists := to[k]
+		if !exists {
+			*removed = append(*removed, query.SliceToDotPath(keyPath...))
+			continue
+		}
+		fromObj, fromIsObj := fromV.(map[string]any)
+		toObj, toIsObj := toV.(map[string]any)
+		if fromIsObj && toIsObj {
+			diffKeys(keyPath, fromObj, toObj, added, removed, changed)
+			continue
+		}
+		if !value.ICompare(fromV, toV) {
+			*changed = append(*changed, query.SliceToDotPath(keyPath...))
+		}
+	}
+	for k := range to {
+		if _, exists := from[k]; !exists {
+			keyPath := append(path[:len(path):len(path)], k)
+			*added = append(*added, query.SliceToDotPath(keyPath...))
+		}
+	}
+}
+
+const diffArraysMaxCells = 10_000_000
+
+// diffArrays computes an edit script transforming one array into another from
+// their longest common subsequence.
+func diffArrays(from, to []any) ([]any, error) {
+	if int64(len(from)+1)*int64(len(to)+1) > diffArraysMaxCells {
+		return nil, fmt.Errorf("arrays of length %v and %v are too large to compare", len(from), len(to))
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of from[i:]
+	// and to[j:].
+	lcs := make([][]int32, len(from)+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, len(to)+1)
+	}
+	for i := len(from) - 1; i >= 0; i-- {
+		for j := len(to) - 1; j >= 0; j-- {
+			if value.ICompare(from[i], to[j]) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	edit := func(op string, v any, index int) any {
+		return map[string]any{"op": op, "value": v, "index": int64(index)}
+	}
+
+	ops := make([]any, 0, max(len(from), len(to)))
+	i, j := 0, 0
+	for i < len(from) && j < len(to) {
+		switch {
+		case value.ICompare(from[i], to[j]):
+			ops = append(ops, edit("equal", from[i], i))
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, edit("delete", from[i], i))
+			i++
+		default:
+			ops = append(ops, edit("insert", to[j], j))
+			j++
+		}
+	}
+	for ; i < len(from); i++ {
+		ops = append(ops, edit("delete", from[i], i))
+	}
+	for ; j < len(to); j++ {
+		ops = append(ops, edit("insert", to[j], j))
+	}
+	return ops, nil
+}
+
+func sortedPathsToAny(paths []string) []any {
+	sort.Strings(paths)
+	res := make([]any, len(paths))
+	for i, p := range paths {
+		res[i] = p
+	}
+	return res
+}
@@ -4,6 +4,7 @@ package pure
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -164,3 +165,426 @@ func TestZipMethod(t *testing.T) {
 		})
 	}
 }
+
+func TestMatchesShapeMethod(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mapping string
+		input   any
+		output  any
+	}{
+		{
+			name:    "numbers of different kinds",
+			mapping: `root = this.matches_shape({"a":0,"b":0.5})`,
+			input:   map[string]any{"a": 10.5, "b": int64(3)},
+			output:  true,
+		},
+		{
+			name:    "array of objects",
+			mapping: `root = this.matches_shape([{"id":""}])`,
+			input: []any{
+				map[string]any{"id": "foo", "extra": true},
+				map[string]any{"id": "bar"},
+			},
+			output: true,
+		},
+		{
+			name:    "array of objects strict",
+			mapping: `root = this.matches_shape(reference: [{"id":""}], strict: true)`,
+			input: []any{
+				map[string]any{"id": "foo", "extra": true},
+				map[string]any{"id": "bar"},
+			},
+			output: false,
+		},
+		{
+			name:    "null mismatch",
+			mapping: `root = this.matches_shape({"a":""})`,
+			input:   map[string]any{"a": nil},
+			output:  false,
+		},
+		{
+			name:    "object vs array",
+			mapping: `root = this.matches_shape({"a":{}})`,
+			input:   map[string]any{"a": []any{}},
+			output:  false,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			exec, err := bloblang.Parse(test.mapping)
+			require.NoError(t, err)
+
+			res, err := exec.Query(test.input)
+			require.NoError(t, err)
+			assert.Equal(t, test.output, res)
+		})
+	}
+}
+
+func TestDiffKeysMethod(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mapping string
+		input   any
+		output  any
+		execErr string
+	}{
+		{
+			name:    "no differences",
+			mapping: `root = this.a.diff_keys(this.b)`,
+			input: map[string]any{
+				"a": map[string]any{"x": int64(1), "y": []any{"foo"}},
+				"b": map[string]any{"x": 1.0, "y": []any{"foo"}},
+			},
+			output: map[string]any{
+				"added":   []any{},
+				"removed": []any{},
+				"changed": []any{},
+			},
+		},
+		{
+			name:    "object replaced with scalar",
+			mapping: `root = this.a.diff_keys(this.b)`,
+			input: map[string]any{
+				"a": map[string]any{"x": map[string]any{"y": "foo"}, "z.w": true},
+				"b": map[string]any{"x": "foo", "z.w": false, "n": map[string]any{"m": 1}},
+			},
+			output: map[string]any{
+				"added":   []any{"n"},
+				"removed": []any{},
+				"changed": []any{"x", "z~1w"},
+			},
+		},
+		{
+			name:    "non object argument",
+			mapping: `root = this.a.diff_keys(this.b)`,
+			input: map[string]any{
+				"a": map[string]any{},
+				"b": "nope",
+			},
+			execErr: "expected object value, got string",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			exec, err := bloblang.Parse(test.mapping)
+			require.NoError(t, err)
+
+			res, err := exec.Query(test.input)
+			if test.execErr == "" {
+				require.NoError(t, err)
+				assert.Equal(t, test.output, res)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.execErr)
+			}
+		})
+	}
+}
+
+func TestCoerceMethod(t *testing.T) {
+	exec, err := bloblang.Parse(`root = this.coerce({"a.b":"int","a.c":"float","d":"bool","e":"string","f":"timestamp","g":"int"})`)
+	require.NoError(t, err)
+
+	input := map[string]any{
+		"a": map[string]any{"b": "10", "c": int64(3)},
+		"d": "nope",
+		"e": []any{"foo"},
+		"f": int64(0),
+		"g": nil,
+	}
+	res, err := exec.Query(input)
+	require.NoError(t, err)
+
+	resMap := res.(map[string]any)
+	coerced := resMap["value"].(map[string]any)
+	assert.Equal(t, map[string]any{"b": int64(10), "c": 3.0}, coerced["a"])
+	assert.Equal(t, "nope", coerced["d"])
+	assert.Equal(t, `["foo"]`, coerced["e"])
+	assert.Equal(t, int64(0), coerced["f"].(time.Time).Unix())
+	assert.Nil(t, coerced["g"])
+
+	errs := resMap["errors"].([]any)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "d", errs[0].(map[string]any)["path"])
+
+	// The input must not be mutated
+	assert.Equal(t, "10", input["a"].(map[string]any)["b"])
+
+	for _, mapping := range []string{
+		`root = this.coerce({"a":"nope"})`,
+		`root = this.coerce({"a":5})`,
+		`root = this.coerce("nope")`,
+	} {
+		_, err := bloblang.Parse(mapping)
+		assert.Error(t, err, mapping)
+	}
+}
+
+func TestCommonPrefixSuffix(t *testing.T) {
+	testCases := []struct {
+		input  []string
+		prefix string
+		suffix string
+	}{
+		{input: nil, prefix: "", suffix: ""},
+		{input: []string{"foo"}, prefix: "foo", suffix: "foo"},
+		{input: []string{"foobar", "foobaz"}, prefix: "fooba", suffix: ""},
+		{input: []string{"abc", "xbc", "bc"}, prefix: "", suffix: "bc"},
+		{input: []string{"foo", ""}, prefix: "", suffix: ""},
+		{input: []string{"héllo", "hélp"}, prefix: "hél", suffix: ""},
+		{input: []string{"éa", "èa"}, prefix: "", suffix: "a"},
+		{input: []string{"aé", "bé"}, prefix: "", suffix: "é"},
+		{input: []string{"aé", "bè"}, prefix: "", suffix: ""},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.prefix, commonPrefix(test.input), test.input)
+		assert.Equal(t, test.suffix, commonSuffix(test.input), test.input)
+	}
+
+	exec, err := bloblang.Parse(`root = this.common_prefix()`)
+	require.NoError(t, err)
+
+	_, err = exec.Query([]any{"foo", 10})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "index 1")
+}
+
+func TestJaccard(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mapping string
+		exp     float64
+	}{
+		{name: "identical", mapping: `root = ["a","b"].jaccard(["b","a","a"])`, exp: 1},
+		{name: "disjoint", mapping: `root = ["a","b"].jaccard(["c"])`, exp: 0},
+		{name: "partial", mapping: `root = ["a","b","c"].jaccard(["b","c","d"])`, exp: 0.5},
+		{name: "both empty", mapping: `root = [].jaccard([])`, exp: 1},
+		{name: "one empty", mapping: `root = ["a"].jaccard([])`, exp: 0},
+		{name: "case sensitive", mapping: `root = ["A","b"].jaccard(["a","b"])`, exp: 1.0 / 3.0},
+		{name: "case insensitive", mapping: `root = ["A","b"].jaccard(["a","b"], true)`, exp: 1},
+		{name: "mixed types", mapping: `root = [1,true,null].jaccard([1,"true",false])`, exp: 0.5},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			exec, err := bloblang.Parse(test.mapping)
+			require.NoError(t, err)
+
+			res, err := exec.Query(nil)
+			require.NoError(t, err)
+			assert.InDelta(t, test.exp, res, 1e-9)
+		})
+	}
+
+	_, err := bloblang.Parse(`root = [].jaccard("foo")`)
+	require.Error(t, err)
+}
+
+func TestSetOperations(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mapping string
+		exp     any
+	}{
+		{name: "union", mapping: `root = ["a","b","a"].set_union(["c","b","d","c"])`, exp: []any{"a", "b", "c", "d"}},
+		{name: "union empty", mapping: `root = [].set_union([])`, exp: []any{}},
+		{name: "union mixed types", mapping: `root = [1,"1",true].set_union(["true",2])`, exp: []any{int64(1), true, int64(2)}},
+		{name: "intersection", mapping: `root = ["a","b","c","b"].set_intersection(["c","b"])`, exp: []any{"b", "c"}},
+		{name: "intersection disjoint", mapping: `root = ["a"].set_intersection(["b"])`, exp: []any{}},
+		{name: "intersection structured", mapping: `root = [{"a":1},[1,2]].set_intersection([[1,2]])`, exp: []any{[]any{int64(1), int64(2)}}},
+		{name: "difference", mapping: `root = ["a","b","a","c"].set_difference(["b"])`, exp: []any{"a", "c"}},
+		{name: "difference empty other", mapping: `root = ["a","a"].set_difference([])`, exp: []any{"a"}},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			exec, err := bloblang.Parse(test.mapping)
+			require.NoError(t, err)
+
+			res, err := exec.Query(nil)
+			require.NoError(t, err)
+			assert.Equal(t, test.exp, res)
+		})
+	}
+
+	_, err := bloblang.Parse(`root = [].set_union("foo")`)
+	require.Error(t, err)
+}
+
+func TestInterleave(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mapping string
+		exp     any
+	}{
+		{name: "equal lengths", mapping: `root = [1,2].interleave([3,4],[5,6])`, exp: []any{int64(1), int64(3), int64(5), int64(2), int64(4), int64(6)}},
+		{name: "target shortest", mapping: `root = [1].interleave([2,3,4])`, exp: []any{int64(1), int64(2), int64(3), int64(4)}},
+		{name: "target longest", mapping: `root = [1,2,3].interleave([4])`, exp: []any{int64(1), int64(4), int64(2), int64(3)}},
+		{name: "empty target", mapping: `root = [].interleave([1],[])`, exp: []any{int64(1)}},
+		{name: "no args", mapping: `root = [1,2].interleave()`, exp: []any{int64(1), int64(2)}},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			exec, err := bloblang.Parse(test.mapping)
+			require.NoError(t, err)
+
+			res, err := exec.Query(nil)
+			require.NoError(t, err)
+			assert.Equal(t, test.exp, res)
+		})
+	}
+
+	_, err := bloblang.Parse(`root = [].interleave("foo")`)
+	require.Error(t, err)
+}
+
+func TestCartesian(t *testing.T) {
+	testCases := []struct {
+		name        string
+		mapping     string
+		exp         any
+		errContains string
+	}{
+		{
+			name:    "three arrays",
+			mapping: `root = [1,2].cartesian([["a"],[true,false]])`,
+			exp: []any{
+				[]any{int64(1), "a", true},
+				[]any{int64(1), "a", false},
+				[]any{int64(2), "a", true},
+				[]any{int64(2), "a", false},
+			},
+		},
+		{
+			name:    "no others",
+			mapping: `root = [1,2].cartesian([])`,
+			exp:     []any{[]any{int64(1)}, []any{int64(2)}},
+		},
+		{
+			name:    "empty other",
+			mapping: `root = [1,2].cartesian([[]])`,
+			exp:     []any{},
+		},
+		{
+			name:    "empty target",
+			mapping: `root = [].cartesian([[1]])`,
+			exp:     []any{},
+		},
+		{
+			name:    "exactly max size",
+			mapping: `root = [1,2].cartesian([[3,4]], 4).length()`,
+			exp:     int64(4),
+		},
+		{
+			name:        "exceeds max size",
+			mapping:     `root = [1,2].cartesian([[3,4]], 3)`,
+			errContains: "would exceed max_size of 3 combinations",
+		},
+		{
+			name:        "exceeds max size without overflow",
+			mapping:     `root = range(0, 1000).cartesian([range(0, 1000), range(0, 1000), range(0, 1000), range(0, 1000), range(0, 1000), range(0, 1000), range(0, 1000)])`,
+			errContains: "would exceed max_size of 10000 combinations",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			exec, err := bloblang.Parse(test.mapping)
+			require.NoError(t, err)
+
+			res, err := exec.Query(nil)
+			if test.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.exp, res)
+		})
+	}
+
+	_, err := bloblang.Parse(`root = [].cartesian(["foo"])`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "others index 0")
+}
+
+func TestDiffArrays(t *testing.T) {
+	op := func(op string, v any, index int64) any {
+		return map[string]any{"op": op, "value": v, "index": index}
+	}
+
+	testCases := []struct {
+		name string
+		from []any
+		to   []any
+		exp  []any
+	}{
+		{name: "both empty", exp: []any{}},
+		{
+			name: "all inserts",
+			to:   []any{"a", "b"},
+			exp:  []any{op("insert", "a", 0), op("insert", "b", 1)},
+		},
+		{
+			name: "all deletes",
+			from: []any{"a", "b"},
+			exp:  []any{op("delete", "a", 0), op("delete", "b", 1)},
+		},
+		{
+			name: "replace",
+			from: []any{"a", "b", "c"},
+			to:   []any{"a", "x", "c"},
+			exp: []any{
+				op("equal", "a", 0),
+				op("delete", "b", 1),
+				op("insert", "x", 1),
+				op("equal", "c", 2),
+			},
+		},
+		{
+			name: "duplicates",
+			from: []any{"a", "a", "b"},
+			to:   []any{"a", "b", "a"},
+			exp: []any{
+				op("equal", "a", 0),
+				op("delete", "a", 1),
+				op("equal", "b", 2),
+				op("insert", "a", 2),
+			},
+		},
+		{
+			name: "numbers and structures",
+			from: []any{int64(1), map[string]any{"a": 1.0}},
+			to:   []any{1.0, map[string]any{"a": int64(1)}},
+			exp: []any{
+				op("equal", int64(1), 0),
+				op("equal", map[string]any{"a": 1.0}, 1),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			res, err := diffArrays(test.from, test.to)
+			require.NoError(t, err)
+			assert.Equal(t, test.exp, res)
+		})
+	}
+
+	_, err := diffArrays(make([]any, 5000), make([]any, 5000))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "too large to compare")
+}
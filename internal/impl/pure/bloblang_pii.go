@@ -0,0 +1,181 @@
+// Copyright 2025 Redpanda Data, Inc.
+
+package pure
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/redpanda-data/benthos/v4/internal/bloblang/query"
+	"github.com/redpanda-data/benthos/v4/internal/value"
+	"github.com/redpanda-data/benthos/v4/public/bloblang"
+)
+
+type piiDetector struct {
+	name    string
+	pattern *regexp.Regexp
+	// validate is an optional secondary check applied to each match of pattern,
+	// matches that fail validation are left untouched.
+	validate func(match string) bool
+}
+
+// piiDetectors are applied in order, detectors that are prone to overlapping
+// with others (phone numbers) are placed last so that more specific matches
+// take precedence.
+var piiDetectors = []piiDetector{
+	{
+		name:    "email",
+		pattern: regexp.MustCompile(`[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}`),
+	},
+	{
+		name:     "credit_card",
+		pattern:  regexp.MustCompile(`\b(?:\d[ \-]?){12,18}\d\b`),
+		validate: luhnValid,
+	},
+	{
+		name:    "ssn",
+		pattern: regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`),
+		validate: func(match string) bool {
+			area, group, serial := match[:3], match[4:6], match[7:]
+			return area != "000" && area != "666" && area[0] != '9' && group != "00" && serial != "0000"
+		},
+	},
+	{
+		name:    "phone",
+		pattern: regexp.MustCompile(`(?:\+\d{1,3}[ .\-]?)?(?:\(\d{3}\) ?|\b\d{3}[ .\-])\d{3}[ .\-]\d{4}\b|\+\d{8,15}\b`),
+	},
+}
+
+func luhnValid(match string) bool {
+	var sum, n int
+	for i := len(match) - 1; i >= 0; i-- {
+		c := match[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n >= 13 && sum%10 == 0
+}
+
+func piiDetectorNames() []string {
+	names := make([]string, len(piiDetectors))
+	for i, d := range piiDetectors {
+		names[i] = d.name
+	}
+	return names
+}
+
+func redactPII(v any, detectors []piiDetector, mask string) any {
+	switch t := v.(type) {
+	case string:
+		for _, d := range detectors {
+			t = d.pattern.ReplaceAllStringFunc(t, func(match string) string {
+				if d.validate != nil && !d.validate(match) {
+					return match
+				}
+				return mask
+			})
+		}
+		return t
+	case map[string]any:
+		res := make(map[string]any, len(t))
+		for k, ele := range t {
+			res[k] = redactPII(ele, detectors, mask)
+		}
+		return res
+	case []any:
+		res := make([]any, len(t))
+		for i, ele := range t {
+			res[i] = redactPII(ele, detectors, mask)
+		}
+		return res
+	}
+	return v
+}
+
+func init() {
+	defaultTypes := []any{}
+	for _, n := range piiDetectorNames() {
+		defaultTypes = append(defaultTypes, n)
+	}
+
+	if err := bloblang.RegisterMethodV2("redact_pii",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryObjectAndArray).
+			Description(`Walks the target value and replaces any portion of a string value that looks like personally identifiable information with a mask. Objects and arrays are walked recursively and the keys of objects are left untouched, non-string values are also left untouched.
+
+The following detectors are available:
+
+- `+"`email`"+`: Anything resembling `+"`local@domain.tld`"+`.
+- `+"`credit_card`"+`: Sequences of 13 to 19 digits, optionally separated by single spaces or hyphens, that pass a Luhn checksum.
+- `+"`ssn`"+`: US social security numbers in the hyphenated form `+"`123-45-6789`"+`, excluding numbers that are never issued (such as those beginning with `+"`000`, `666` or `9`"+`).
+- `+"`phone`"+`: North American style numbers containing separators such as `+"`(555) 123-4567`"+` or `+"`555.123.4567`"+`, optionally prefixed with a country code, and E.164 numbers such as `+"`+15551234567`"+`.
+
+These detectors are heuristics and should be treated as a defense-in-depth measure rather than a guarantee. Values that happen to resemble a detected type (such as order numbers or timestamps in a phone number format) will also be masked, and PII in unusual formats (such as phone numbers without any separators) will not be detected.`).
+			Param(bloblang.NewAnyParam("types").Description("An array of detector names to apply.").Default(defaultTypes)).
+			Param(bloblang.NewStringParam("mask").Description("A string to replace detected values with.").Default("[REDACTED]")).
+			Example("", `root = this.redact_pii()`,
+				[2]string{
+					`{"user":{"contact":"reach me at jane.doe@example.com or (555) 123-4567"},"ssn":"123-45-6789","orders":[{"card":"4111 1111 1111 1111","count":3}]}`,
+					`{"orders":[{"card":"[REDACTED]","count":3}],"ssn":"[REDACTED]","user":{"contact":"reach me at [REDACTED] or [REDACTED]"}}`,
+				},
+			).
+			Example("", `root = this.redact_pii(types: ["email"], mask: "***")`,
+				[2]string{
+					`{"from":"jane.doe@example.com","phone":"555-123-4567"}`,
+					`{"from":"***","phone":"555-123-4567"}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			typesV, err := args.Get("types")
+			if err != nil {
+				return nil, err
+			}
+			typesArr, ok := typesV.([]any)
+			if !ok {
+				return nil, value.NewTypeError(typesV, value.TArray)
+			}
+
+			requested := map[string]struct{}{}
+			for i, tV := range typesArr {
+				tStr, err := value.IGetString(tV)
+				if err != nil {
+					return nil, fmt.Errorf("types element %v: %w", i, err)
+				}
+				requested[tStr] = struct{}{}
+			}
+
+			// Retain the canonical detector order regardless of the order of
+			// the types provided.
+			var detectors []piiDetector
+			for _, d := range piiDetectors {
+				if _, exists := requested[d.name]; exists {
+					detectors = append(detectors, d)
+					delete(requested, d.name)
+				}
+			}
+			for unknown := range requested {
+				return nil, fmt.Errorf("unrecognised pii type '%v', expected one of: %v", unknown, strings.Join(piiDetectorNames(), ", "))
+			}
+
+			mask, err := args.GetString("mask")
+			if err != nil {
+				return nil, err
+			}
+
+			return func(v any) (any, error) {
+				return redactPII(v, detectors, mask), nil
+			}, nil
+		}); err != nil {
+		panic(err)
+	}
+}
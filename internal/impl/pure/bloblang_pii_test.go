@@ -0,0 +1,69 @@
+// Copyright 2025 Redpanda Data, Inc.
+
+package pure
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/redpanda-data/benthos/v4/public/bloblang"
+)
+
+func TestRedactPIIMethod(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mapping  string
+		input    any
+		output   any
+		parseErr string
+	}{
+		{
+			name:    "card failing luhn",
+			mapping: `root = this.redact_pii(types: ["credit_card"])`,
+			input:   "4111 1111 1111 1112",
+			output:  "4111 1111 1111 1112",
+		},
+		{
+			name:    "card with hyphens",
+			mapping: `root = this.redact_pii(types: ["credit_card"])`,
+			input:   "paid with 5500-0000-0000-0004 today",
+			output:  "paid with [REDACTED] today",
+		},
+		{
+			name:    "unissued ssn",
+			mapping: `root = this.redact_pii(types: ["ssn"])`,
+			input:   "000-12-3456 and 666-12-3456 and 912-34-5678",
+			output:  "000-12-3456 and 666-12-3456 and 912-34-5678",
+		},
+		{
+			name:    "international phone",
+			mapping: `root = this.redact_pii(types: ["phone"], mask: "#")`,
+			input:   []any{"+44 207-123-4567", "+442071234567", "5551234567", int64(5551234567)},
+			output:  []any{"#", "#", "5551234567", int64(5551234567)},
+		},
+		{
+			name:     "unknown type",
+			mapping:  `root = this.redact_pii(types: ["email","passport"])`,
+			parseErr: "unrecognised pii type 'passport'",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			exec, err := bloblang.Parse(test.mapping)
+			if test.parseErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.parseErr)
+				return
+			}
+			require.NoError(t, err)
+
+			res, err := exec.Query(test.input)
+			require.NoError(t, err)
+			assert.Equal(t, test.output, res)
+		})
+	}
+}
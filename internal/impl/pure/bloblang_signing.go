@@ -0,0 +1,243 @@
+// Copyright 2025 Redpanda Data, Inc.
+
+package pure
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+
+	"github.com/redpanda-data/benthos/v4/internal/bloblang/query"
+	"github.com/redpanda-data/benthos/v4/internal/value"
+	"github.com/redpanda-data/benthos/v4/public/bloblang"
+)
+
+// canonicalSignedURL returns the canonical form of a URL used for computing
+// its signature, which is the URL without a fragment or signature parameter
+// and with its query parameters sorted by key and then value.
+func canonicalSignedURL(u *url.URL) string {
+	c := *u
+	c.Fragment = ""
+	c.RawFragment = ""
+	q := c.Query()
+	q.Del("signature")
+	for _, values := range q {
+		sort.Strings(values)
+	}
+	c.RawQuery = q.Encode()
+	return c.String()
+}
+
+func urlSignature(secret []byte, canonical string) string {
+	mac := hmac.New(sha256.New, secret)
+	_, _ = mac.Write([]byte(canonical))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func signURL(s string, secret []byte, expires time.Time) (string, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse url: %w", err)
+	}
+	q := u.Query()
+	q.Set("expires", strconv.FormatInt(expires.Unix(), 10))
+	u.RawQuery = q.Encode()
+
+	canonical := canonicalSignedURL(u)
+	q.Set("signature", urlSignature(secret, canonical))
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+func verifySignedURL(s string, secret []byte, now time.Time) (bool, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse url: %w", err)
+	}
+	q := u.Query()
+	signature, err := hex.DecodeString(q.Get("signature"))
+	if err != nil || len(signature) == 0 {
+		return false, nil
+	}
+	expires, err := strconv.ParseInt(q.Get("expires"), 10, 64)
+	if err != nil {
+		return false, nil
+	}
+	expected, _ := hex.DecodeString(urlSignature(secret, canonicalSignedURL(u)))
+	if !hmac.Equal(signature, expected) {
+		return false, nil
+	}
+	return now.Unix() < expires, nil
+}
+
+func awsSigV4SignRequest(m map[string]any, creds aws.Credentials, region, service string, signTime time.Time) (map[string]any, error) {
+	method := "GET"
+	if v, exists := m["method"]; exists && v != nil {
+		var err error
+		if method, err = value.IGetString(v); err != nil {
+			return nil, fmt.Errorf("field method: %w", err)
+		}
+	}
+
+	urlStr, err := value.IGetString(m["url"])
+	if err != nil {
+		return nil, fmt.Errorf("field url: %w", err)
+	}
+
+	var body []byte
+	if v, exists := m["body"]; exists && v != nil {
+		if body, err = value.IGetBytes(v); err != nil {
+			return nil, fmt.Errorf("field body: %w", err)
+		}
+	}
+
+	req, err := http.NewRequest(method, urlStr, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if v, exists := m["headers"]; exists && v != nil {
+		headers, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("field headers: %w", value.NewTypeError(v, value.TObject))
+		}
+		for k, hv := range headers {
+			if arr, isArr := hv.([]any); isArr {
+				for _, ele := range arr {
+					req.Header.Add(k, value.IToString(ele))
+				}
+			} else {
+				req.Header.Set(k, value.IToString(hv))
+			}
+		}
+	}
+
+	payloadHash := sha256.Sum256(body)
+	signer := v4.NewSigner()
+	if err := signer.SignHTTP(context.Background(), creds, req, hex.EncodeToString(payloadHash[:]), service, region, signTime); err != nil {
+		return nil, fmt.Errorf("failed to sign request: %w", err)
+	}
+	return urlValuesToMap(url.Values(req.Header)), nil
+}
+
+func init() {
+	canonicalisationDocs := `The signature is a hex encoded HMAC-SHA256 of the canonical form of the URL, which is the URL (including its ` + "`expires`" + ` parameter) with any fragment and ` + "`signature`" + ` parameter removed and with its query parameters sorted by key and then value, encoded with standard URL query escaping.`
+
+	if err := bloblang.RegisterMethodV2("sign_url",
+		bloblang.NewPluginSpec().
+			Impure().
+			Category(query.MethodCategoryStrings).
+			Description(`Signs a URL so that it can be verified later with `+"<<verify_signed_url, `verify_signed_url`>>"+`, allowing time-limited links (such as downloads) to be issued without storing any state. An `+"`expires`"+` query parameter containing the unix timestamp at which the URL expires is added, followed by a `+"`signature`"+` query parameter. Any existing `+"`expires` or `signature`"+` parameters are replaced, and the query parameters of the resulting URL are sorted by key.
+
+`+canonicalisationDocs+` The secret should be kept private, anyone with access to it can sign URLs.`).
+			Param(bloblang.NewStringParam("secret").Description("The secret key used to sign the URL.")).
+			Param(bloblang.NewStringParam("ttl").Description("A duration string describing how long the signed URL remains valid for, such as `15m` or `24h`.")).
+			ExampleNotTested("The expiry and signature depend on the time of signing, the output shown is for a URL signed at `2025-01-01T00:00:00Z` with the secret `example-secret`.", `root.link = this.url.sign_url(env("LINK_SECRET"), "1h")`,
+				[2]string{
+					`{"url":"https://example.com/files/report.pdf?user=ash"}`,
+					`{"link":"https://example.com/files/report.pdf?expires=1735693200&signature=184398491b287a800cc9b426f84fd38a8a96ba5665944730f703177b822314c3&user=ash"}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			secret, err := args.GetString("secret")
+			if err != nil {
+				return nil, err
+			}
+			ttlStr, err := args.GetString("ttl")
+			if err != nil {
+				return nil, err
+			}
+			ttl, err := time.ParseDuration(ttlStr)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse ttl: %w", err)
+			}
+			return bloblang.StringMethod(func(s string) (any, error) {
+				return signURL(s, []byte(secret), time.Now().Add(ttl))
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("verify_signed_url",
+		bloblang.NewPluginSpec().
+			Impure().
+			Category(query.MethodCategoryStrings).
+			Description(`Checks whether a URL signed with `+"<<sign_url, `sign_url`>>"+` has a valid signature and has not yet expired, returning a boolean. An error is only returned if the target cannot be parsed as a URL, URLs with missing or malformed `+"`expires` or `signature`"+` parameters are considered invalid.
+
+`+canonicalisationDocs+` The comparison of signatures is performed in constant time.`).
+			Param(bloblang.NewStringParam("secret").Description("The secret key that was used to sign the URL.")).
+			ExampleNotTested("The output shown is for a URL verified before it expires with the secret `example-secret`.", `root.allowed = this.url.verify_signed_url(env("LINK_SECRET"))`,
+				[2]string{
+					`{"url":"https://example.com/files/report.pdf?expires=1735693200&signature=184398491b287a800cc9b426f84fd38a8a96ba5665944730f703177b822314c3&user=ash"}`,
+					`{"allowed":true}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			secret, err := args.GetString("secret")
+			if err != nil {
+				return nil, err
+			}
+			return bloblang.StringMethod(func(s string) (any, error) {
+				return verifySignedURL(s, []byte(secret), time.Now())
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("aws_sigv4",
+		bloblang.NewPluginSpec().
+			Impure().
+			Category(query.MethodCategoryEncoding).
+			Description(`Signs a HTTP request with https://docs.aws.amazon.com/IAM/latest/UserGuide/reference_aws-signing.html[AWS Signature Version 4^] and returns its headers with the signing headers `+"`Authorization`"+` and `+"`X-Amz-Date`"+` (and `+"`X-Amz-Security-Token`"+` when a session token is provided) added. This makes it possible to call AWS compatible APIs with a generic HTTP client such as the `+"`http`"+` processor.
+
+The target must be an object describing the request with the fields `+"`url`"+` (required), `+"`method`"+` (defaults to `+"`GET`"+`), `+"`headers`"+` (an object of header keys to either a string or an array of strings) and `+"`body`"+` (a string or byte array). The request is signed at the current time, and header keys within the result are canonicalized (`+"`content-type`"+` becomes `+"`Content-Type`"+`). The request must be sent with exactly the same method, URL, signed headers and body, otherwise the signature is rejected.`).
+			Param(bloblang.NewStringParam("access_key").Description("The access key ID of the credentials to sign with.")).
+			Param(bloblang.NewStringParam("secret_key").Description("The secret access key of the credentials to sign with.")).
+			Param(bloblang.NewStringParam("region").Description("The region of the service, such as `us-east-1`.")).
+			Param(bloblang.NewStringParam("service").Description("The signing name of the service, such as `s3` or `execute-api`.")).
+			Param(bloblang.NewStringParam("session_token").Description("An optional session token for temporary credentials.").Default("")).
+			ExampleNotTested("The signature depends on the time of signing, the output shown is for a request signed at `2025-01-01T12:00:00Z` with the AWS example secret key `wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY`.", `root = this
+root.headers = this.aws_sigv4("AKIDEXAMPLE", env("AWS_SECRET_ACCESS_KEY"), "us-east-1", "execute-api")`,
+				[2]string{
+					`{"method":"POST","url":"https://abc123.execute-api.us-east-1.amazonaws.com/prod/items","headers":{"Content-Type":"application/json"},"body":"{\"id\":1}"}`,
+					`{"body":"{\"id\":1}","headers":{"Authorization":"AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20250101/us-east-1/execute-api/aws4_request, SignedHeaders=content-length;content-type;host;x-amz-date, Signature=918b174c8f5758604bc347fc71661633b5a02685c80eaf0b5443ef9b440e01b5","Content-Type":"application/json","X-Amz-Date":"20250101T120000Z"},"method":"POST","url":"https://abc123.execute-api.us-east-1.amazonaws.com/prod/items"}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			var creds aws.Credentials
+			var err error
+			if creds.AccessKeyID, err = args.GetString("access_key"); err != nil {
+				return nil, err
+			}
+			if creds.SecretAccessKey, err = args.GetString("secret_key"); err != nil {
+				return nil, err
+			}
+			if creds.SessionToken, err = args.GetString("session_token"); err != nil {
+				return nil, err
+			}
+			region, err := args.GetString("region")
+			if err != nil {
+				return nil, err
+			}
+			service, err := args.GetString("service")
+			if err != nil {
+				return nil, err
+			}
+			return bloblang.ObjectMethod(func(m map[string]any) (any, error) {
+				return awsSigV4SignRequest(m, creds, region, service, time.Now())
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+}
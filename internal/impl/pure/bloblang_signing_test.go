@@ -0,0 +1,255 @@
+// Copyright 2025 Redpanda Data, Inc.
+
+package pure
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/redpanda-data/benthos/v4/public/bloblang"
+)
+
+func TestSignURL(t *testing.T) {
+	secret := []byte("shh")
+	expires := time.Unix(1735693200, 0)
+
+	signed, err := signURL("https://example.com/a/b?z=1&a=2&signature=old#frag", secret, expires)
+	require.NoError(t, err)
+
+	u, err := url.Parse(signed)
+	require.NoError(t, err)
+	assert.Equal(t, "1735693200", u.Query().Get("expires"))
+	assert.Len(t, u.Query().Get("signature"), 64)
+	assert.Equal(t, "frag", u.Fragment)
+
+	for name, test := range map[string]struct {
+		url   string
+		now   time.Time
+		valid bool
+	}{
+		"valid": {
+			url:   signed,
+			now:   expires.Add(-time.Second),
+			valid: true,
+		},
+		"expired": {
+			url: signed,
+			now: expires,
+		},
+		"param tampered": {
+			url: replaceQueryParam(t, signed, "a", "3"),
+			now: expires.Add(-time.Second),
+		},
+		"expiry tampered": {
+			url: replaceQueryParam(t, signed, "expires", "1835693200"),
+			now: expires.Add(-time.Second),
+		},
+		"param added": {
+			url: replaceQueryParam(t, signed, "b", "1"),
+			now: expires.Add(-time.Second),
+		},
+		"missing signature": {
+			url: replaceQueryParam(t, signed, "signature", ""),
+			now: expires.Add(-time.Second),
+		},
+		"no params": {
+			url: "https://example.com/a/b",
+			now: expires.Add(-time.Second),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			valid, err := verifySignedURL(test.url, secret, test.now)
+			require.NoError(t, err)
+			assert.Equal(t, test.valid, valid)
+		})
+	}
+
+	valid, err := verifySignedURL(signed, []byte("wrong"), expires.Add(-time.Second))
+	require.NoError(t, err)
+	assert.False(t, valid)
+}
+
+func TestSignURLRepeatedKeys(t *testing.T) {
+	u, err := url.Parse("https://example.com/a?b=1&a=2&a=10&a=1&signature=foo#frag")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/a?a=1&a=10&a=2&b=1", canonicalSignedURL(u))
+
+	secret := []byte("shh")
+	expires := time.Unix(1735693200, 0)
+
+	signed, err := signURL("https://example.com/a?a=2&a=1", secret, expires)
+	require.NoError(t, err)
+
+	valid, err := verifySignedURL(signed, secret, expires.Add(-time.Second))
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	// The order of repeated values does not affect the signature.
+	signedU, err := url.Parse(signed)
+	require.NoError(t, err)
+	q := signedU.Query()
+	q["a"] = []string{"1", "2"}
+	signedU.RawQuery = q.Encode()
+
+	valid, err = verifySignedURL(signedU.String(), secret, expires.Add(-time.Second))
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	// Whereas changing a repeated value does.
+	q["a"] = []string{"1", "3"}
+	signedU.RawQuery = q.Encode()
+
+	valid, err = verifySignedURL(signedU.String(), secret, expires.Add(-time.Second))
+	require.NoError(t, err)
+	assert.False(t, valid)
+}
+
+func TestSignURLDocsExample(t *testing.T) {
+	// Matches the output documented in the examples of the sign_url and
+	// verify_signed_url methods.
+	const exampleURL = "https://example.com/files/report.pdf?expires=1735693200&signature=184398491b287a800cc9b426f84fd38a8a96ba5665944730f703177b822314c3&user=ash"
+
+	signed, err := signURL("https://example.com/files/report.pdf?user=ash", []byte("example-secret"), time.Unix(1735693200, 0))
+	require.NoError(t, err)
+	assert.Equal(t, exampleURL, signed)
+
+	valid, err := verifySignedURL(exampleURL, []byte("example-secret"), time.Unix(1735693200-1, 0))
+	require.NoError(t, err)
+	assert.True(t, valid)
+}
+
+func replaceQueryParam(t *testing.T, s, key, value string) string {
+	t.Helper()
+	u, err := url.Parse(s)
+	require.NoError(t, err)
+	q := u.Query()
+	q.Set(key, value)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+func TestSignURLMapping(t *testing.T) {
+	exec, err := bloblang.Parse(`root.signed = this.url.sign_url("shh", "1h")
+root.valid = root.signed.verify_signed_url("shh")
+root.invalid = root.signed.verify_signed_url("nope")`)
+	require.NoError(t, err)
+
+	res, err := exec.Query(map[string]any{"url": "https://example.com/foo"})
+	require.NoError(t, err)
+
+	resMap := res.(map[string]any)
+	assert.Equal(t, true, resMap["valid"])
+	assert.Equal(t, false, resMap["invalid"])
+
+	_, err = bloblang.Parse(`root = this.sign_url("shh", "nope")`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse ttl")
+}
+
+func TestAWSSigV4SignRequest(t *testing.T) {
+	signTime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	creds := aws.Credentials{AccessKeyID: "AKID", SecretAccessKey: "SECRET"}
+
+	res, err := awsSigV4SignRequest(map[string]any{
+		"method": "PUT",
+		"url":    "https://bucket.s3.us-east-1.amazonaws.com/foo.txt",
+		"headers": map[string]any{
+			"content-type": "text/plain",
+			"x-custom":     []any{"a", "b"},
+		},
+		"body": "hello world",
+	}, creds, "us-east-1", "s3", signTime)
+	require.NoError(t, err)
+
+	auth, _ := res["Authorization"].(string)
+	assert.Contains(t, auth, "AWS4-HMAC-SHA256 Credential=AKID/20250101/us-east-1/s3/aws4_request, ")
+	assert.Contains(t, auth, "Signature=")
+	assert.Equal(t, "20250101T000000Z", res["X-Amz-Date"])
+	assert.Equal(t, "text/plain", res["Content-Type"])
+	assert.Equal(t, []any{"a", "b"}, res["X-Custom"])
+	assert.NotContains(t, res, "X-Amz-Security-Token")
+
+	again, err := awsSigV4SignRequest(map[string]any{
+		"method": "PUT",
+		"url":    "https://bucket.s3.us-east-1.amazonaws.com/foo.txt",
+		"headers": map[string]any{
+			"content-type": "text/plain",
+			"x-custom":     []any{"a", "b"},
+		},
+		"body": "hello world",
+	}, creds, "us-east-1", "s3", signTime)
+	require.NoError(t, err)
+	assert.Equal(t, res["Authorization"], again["Authorization"])
+
+	tampered, err := awsSigV4SignRequest(map[string]any{
+		"method": "PUT",
+		"url":    "https://bucket.s3.us-east-1.amazonaws.com/foo.txt",
+		"headers": map[string]any{
+			"content-type": "text/plain",
+			"x-custom":     []any{"a", "b"},
+		},
+		"body": "hello world!",
+	}, creds, "us-east-1", "s3", signTime)
+	require.NoError(t, err)
+	assert.NotEqual(t, res["Authorization"], tampered["Authorization"])
+
+	creds.SessionToken = "TOKEN"
+	res, err = awsSigV4SignRequest(map[string]any{
+		"url": "https://sqs.us-east-1.amazonaws.com/",
+	}, creds, "us-east-1", "sqs", signTime)
+	require.NoError(t, err)
+	assert.Equal(t, "TOKEN", res["X-Amz-Security-Token"])
+}
+
+func TestAWSSigV4SignRequestDocsExample(t *testing.T) {
+	// Matches the output documented in the example of the aws_sigv4 method.
+	res, err := awsSigV4SignRequest(map[string]any{
+		"method":  "POST",
+		"url":     "https://abc123.execute-api.us-east-1.amazonaws.com/prod/items",
+		"headers": map[string]any{"Content-Type": "application/json"},
+		"body":    `{"id":1}`,
+	}, aws.Credentials{
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+	}, "us-east-1", "execute-api", time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]any{
+		"Authorization": "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20250101/us-east-1/execute-api/aws4_request, SignedHeaders=content-length;content-type;host;x-amz-date, Signature=918b174c8f5758604bc347fc71661633b5a02685c80eaf0b5443ef9b440e01b5",
+		"Content-Type":  "application/json",
+		"X-Amz-Date":    "20250101T120000Z",
+	}, res)
+}
+
+func TestAWSSigV4SignRequestErrors(t *testing.T) {
+	creds := aws.Credentials{AccessKeyID: "AKID", SecretAccessKey: "SECRET"}
+
+	for name, test := range map[string]struct {
+		input       map[string]any
+		errContains string
+	}{
+		"missing url": {
+			input:       map[string]any{"method": "GET"},
+			errContains: "field url",
+		},
+		"bad headers": {
+			input:       map[string]any{"url": "https://example.com", "headers": "nope"},
+			errContains: "field headers",
+		},
+		"bad method": {
+			input:       map[string]any{"url": "https://example.com", "method": 10},
+			errContains: "field method",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := awsSigV4SignRequest(test.input, creds, "us-east-1", "s3", time.Now())
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.errContains)
+		})
+	}
+}
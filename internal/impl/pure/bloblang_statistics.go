@@ -0,0 +1,240 @@
+// Copyright 2025 Redpanda Data, Inc.
+
+package pure
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/redpanda-data/benthos/v4/internal/bloblang/query"
+	"github.com/redpanda-data/benthos/v4/internal/value"
+	"github.com/redpanda-data/benthos/v4/public/bloblang"
+)
+
+// numericArray converts an array of numbers into a slice of float64 values,
+// returning an error containing the index of the first non-numeric element.
+func numericArray(arr []any) ([]float64, error) {
+	nums := make([]float64, len(arr))
+	for i, v := range arr {
+		n, err := value.IGetNumber(v)
+		if err != nil {
+			return nil, fmt.Errorf("index %v: %w", i, err)
+		}
+		nums[i] = n
+	}
+	return nums, nil
+}
+
+// sortedMedian returns the median of a sorted, non-empty slice of numbers.
+func sortedMedian(sorted []float64) float64 {
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+func arrayStats(arr []any) (map[string]any, error) {
+	nums, err := numericArray(arr)
+	if err != nil {
+		return nil, err
+	}
+	if len(nums) == 0 {
+		return nil, errors.New("cannot compute statistics of an empty array")
+	}
+
+	sorted := append([]float64(nil), nums...)
+	sort.Float64s(sorted)
+
+	var sum float64
+	for _, n := range nums {
+		sum += n
+	}
+	mean := sum / float64(len(nums))
+
+	var variance float64
+	for _, n := range nums {
+		variance += (n - mean) * (n - mean)
+	}
+	variance /= float64(len(nums))
+
+	return map[string]any{
+		"count":    int64(len(nums)),
+		"sum":      sum,
+		"min":      sorted[0],
+		"max":      sorted[len(sorted)-1],
+		"mean":     mean,
+		"median":   sortedMedian(sorted),
+		"stddev":   math.Sqrt(variance),
+		"variance": variance,
+	}, nil
+}
+
+// percentile returns the p-th percentile of a sorted, non-empty slice of
+// numbers, using linear interpolation between the closest ranks.
+func percentile(sorted []float64, p float64) float64 {
+	rank := p * float64(len(sorted)-1) / 100
+	lower := int(math.Floor(rank))
+	if lower >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[lower] + (rank-float64(lower))*(sorted[lower+1]-sorted[lower])
+}
+
+func getPercentileArg(v any) (float64, error) {
+	p, err := value.IGetNumber(v)
+	if err != nil {
+		return 0, err
+	}
+	if p < 0 || p > 100 || math.IsNaN(p) {
+		return 0, fmt.Errorf("percentile must be between 0 and 100, got %v", p)
+	}
+	return p, nil
+}
+
+func pearsonCorrelation(xs, ys []float64) (float64, error) {
+	if len(xs) != len(ys) {
+		return 0, fmt.Errorf("arrays must be the same length, got %v and %v", len(xs), len(ys))
+	}
+	if len(xs) < 2 {
+		return 0, fmt.Errorf("arrays must contain at least two elements, got %v", len(xs))
+	}
+
+	var meanX, meanY float64
+	for i := range xs {
+		meanX += xs[i]
+		meanY += ys[i]
+	}
+	meanX /= float64(len(xs))
+	meanY /= float64(len(ys))
+
+	var cov, varX, varY float64
+	for i := range xs {
+		dx, dy := xs[i]-meanX, ys[i]-meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+	if varX == 0 || varY == 0 {
+		return 0, errors.New("correlation is undefined when an array has no variance")
+	}
+	return cov / math.Sqrt(varX*varY), nil
+}
+
+func init() {
+	if err := bloblang.RegisterMethodV2("stats",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryObjectAndArray).
+			Description(`Computes summary statistics of an array of numbers in a single call, returning an object containing the fields `+"`count`, `sum`, `min`, `max`, `mean`, `median`, `stddev` and `variance`"+`. The variance and standard deviation are of the population rather than a sample. An error is returned if the array is empty or contains a non-numeric element.`).
+			Example("", `root.latency = this.requests.map_each(r -> r.latency_ms).stats()`,
+				[2]string{
+					`{"requests":[{"latency_ms":2},{"latency_ms":4},{"latency_ms":4},{"latency_ms":4},{"latency_ms":5},{"latency_ms":5},{"latency_ms":7},{"latency_ms":9}]}`,
+					`{"latency":{"count":8,"max":9,"mean":5,"median":4.5,"min":2,"stddev":2,"sum":40,"variance":4}}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return bloblang.ArrayMethod(func(arr []any) (any, error) {
+				return arrayStats(arr)
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("percentile",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryObjectAndArray).
+			Description(`Returns the p-th percentile of an array of numbers, where p is between 0 and 100, using linear interpolation between the closest ranks. The array does not need to be sorted. When multiple percentiles are provided, either as separate arguments or as a single array, an object is returned with a field for each percentile, where the field name is the percentile prefixed with `+"`p`"+`, such as `+"`p50`"+` or `+"`p99.9`"+`. An error is returned if the array is empty or contains a non-numeric element.`).
+			Variadic().
+			Example("", `root.median = this.latencies.percentile(50)`,
+				[2]string{`{"latencies":[15,20,35,40,50]}`, `{"median":35}`},
+				[2]string{`{"latencies":[40,15,20,35]}`, `{"median":27.5}`}).
+			Example("", `root.latency = this.latencies.percentile(50, 95, 99)`,
+				[2]string{`{"latencies":[0,10,20,30,40,50,60,70,80,90,100]}`, `{"latency":{"p50":50,"p95":95,"p99":99}}`}).
+			Example("An array of percentiles can be provided when they are determined at runtime.", `root.latency = this.latencies.percentile(this.percentiles)`,
+				[2]string{`{"latencies":[0,10,20,30,40,50,60,70,80,90,100],"percentiles":[25,75]}`, `{"latency":{"p25":25,"p75":75}}`}),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			pArgs := args.AsSlice()
+			if len(pArgs) == 0 {
+				return nil, errors.New("expected at least one percentile argument")
+			}
+
+			isArr := len(pArgs) > 1
+			if len(pArgs) == 1 {
+				if pArr, ok := pArgs[0].([]any); ok {
+					pArgs, isArr = pArr, true
+				}
+			}
+
+			var ps []float64
+			for i, v := range pArgs {
+				p, err := getPercentileArg(v)
+				if err != nil {
+					if isArr {
+						return nil, fmt.Errorf("index %v: %w", i, err)
+					}
+					return nil, err
+				}
+				ps = append(ps, p)
+			}
+			if len(ps) == 0 {
+				return nil, errors.New("expected at least one percentile argument")
+			}
+
+			return bloblang.ArrayMethod(func(arr []any) (any, error) {
+				nums, err := numericArray(arr)
+				if err != nil {
+					return nil, err
+				}
+				if len(nums) == 0 {
+					return nil, errors.New("cannot compute the percentile of an empty array")
+				}
+				sort.Float64s(nums)
+				if !isArr {
+					return percentile(nums, ps[0]), nil
+				}
+				res := make(map[string]any, len(ps))
+				for _, p := range ps {
+					res["p"+strconv.FormatFloat(p, 'f', -1, 64)] = percentile(nums, p)
+				}
+				return res, nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("correlation",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryObjectAndArray).
+			Description(`Returns the https://en.wikipedia.org/wiki/Pearson_correlation_coefficient[Pearson correlation coefficient^] between an array of numbers and another array of numbers, which is a value between -1 and 1. An error is returned if the arrays are of different lengths, contain fewer than two elements, contain a non-numeric element, or if either array contains only identical values.`).
+			Param(bloblang.NewAnyParam("other").Description("An array of numbers of the same length as the target array.")).
+			Example("", `root.correlation = this.temperatures.correlation(this.ice_cream_sales)`,
+				[2]string{`{"temperatures":[1,2,3,4],"ice_cream_sales":[2,4,6,8]}`, `{"correlation":1}`},
+				[2]string{`{"temperatures":[1,2,3,4],"ice_cream_sales":[8,6,4,2]}`, `{"correlation":-1}`},
+				[2]string{`{"temperatures":[1,2,3,4],"ice_cream_sales":[1,3,3,1]}`, `{"correlation":0}`}),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			otherArg, err := args.Get("other")
+			if err != nil {
+				return nil, err
+			}
+			otherArr, ok := otherArg.([]any)
+			if !ok {
+				return nil, value.NewTypeError(otherArg, value.TArray)
+			}
+			others, err := numericArray(otherArr)
+			if err != nil {
+				return nil, fmt.Errorf("other: %w", err)
+			}
+			return bloblang.ArrayMethod(func(arr []any) (any, error) {
+				nums, err := numericArray(arr)
+				if err != nil {
+					return nil, err
+				}
+				return pearsonCorrelation(nums, others)
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+}
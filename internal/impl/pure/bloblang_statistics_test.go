@@ -0,0 +1,122 @@
+// Copyright 2025 Redpanda Data, Inc.
+
+package pure
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/redpanda-data/benthos/v4/public/bloblang"
+)
+
+func TestArrayStats(t *testing.T) {
+	res, err := arrayStats([]any{int64(3), 1.5, uint64(6), int64(-1)})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"count":    int64(4),
+		"sum":      9.5,
+		"min":      -1.0,
+		"max":      6.0,
+		"mean":     2.375,
+		"median":   2.25,
+		"stddev":   math.Sqrt(6.421875),
+		"variance": 6.421875,
+	}, res)
+
+	res, err = arrayStats([]any{int64(7)})
+	require.NoError(t, err)
+	assert.Equal(t, 7.0, res["median"])
+	assert.Equal(t, 0.0, res["stddev"])
+
+	_, err = arrayStats([]any{})
+	require.EqualError(t, err, "cannot compute statistics of an empty array")
+
+	_, err = arrayStats([]any{int64(1), "two"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "index 1")
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := []float64{1, 2, 3, 4}
+	for p, exp := range map[float64]float64{
+		0:   1,
+		25:  1.75,
+		50:  2.5,
+		90:  3.7,
+		100: 4,
+	} {
+		assert.InDelta(t, exp, percentile(sorted, p), 1e-9, p)
+	}
+	assert.Equal(t, 5.0, percentile([]float64{5}, 99))
+
+	exec, err := bloblang.Parse(`root = this.percentile([0, 99.9, 100])`)
+	require.NoError(t, err)
+
+	res, err := exec.Query([]any{int64(3), int64(1), 2.0})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"p0": 1.0, "p99.9": 2.998, "p100": 3.0}, res)
+
+	_, err = exec.Query([]any{})
+	require.Error(t, err)
+
+	exec, err = bloblang.Parse(`root = this.percentile(0, 99.9, 100)`)
+	require.NoError(t, err)
+
+	res, err = exec.Query([]any{int64(3), int64(1), 2.0})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"p0": 1.0, "p99.9": 2.998, "p100": 3.0}, res)
+
+	_, err = exec.Query([]any{int64(1), "nope"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "index 1")
+
+	for _, mapping := range []string{
+		`root = this.percentile(101)`,
+		`root = this.percentile(-1)`,
+		`root = this.percentile([50, 200])`,
+		`root = this.percentile("nope")`,
+		`root = this.percentile(50, 200)`,
+		`root = this.percentile()`,
+		`root = this.percentile([])`,
+	} {
+		exec, err := bloblang.Parse(mapping)
+		if err == nil {
+			_, err = exec.Query([]any{int64(1)})
+		}
+		assert.Error(t, err, mapping)
+	}
+}
+
+func TestPearsonCorrelation(t *testing.T) {
+	for _, test := range []struct {
+		xs, ys []float64
+		exp    float64
+	}{
+		{xs: []float64{1, 2}, ys: []float64{5, 10}, exp: 1},
+		{xs: []float64{1, 2, 3}, ys: []float64{3, 2, 1}, exp: -1},
+		{xs: []float64{1, 2, 3, 4, 5}, ys: []float64{2, 4, 5, 4, 5}, exp: 0.7745966692414834},
+	} {
+		res, err := pearsonCorrelation(test.xs, test.ys)
+		require.NoError(t, err)
+		assert.InDelta(t, test.exp, res, 1e-9)
+	}
+
+	for _, test := range []struct {
+		xs, ys []float64
+		err    string
+	}{
+		{xs: []float64{1, 2}, ys: []float64{1, 2, 3}, err: "arrays must be the same length, got 2 and 3"},
+		{xs: []float64{1}, ys: []float64{1}, err: "arrays must contain at least two elements, got 1"},
+		{xs: []float64{1, 1}, ys: []float64{1, 2}, err: "correlation is undefined when an array has no variance"},
+	} {
+		_, err := pearsonCorrelation(test.xs, test.ys)
+		require.EqualError(t, err, test.err)
+	}
+
+	_, err := bloblang.Parse(`root = this.correlation([1, "nope"])`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "other: index 1")
+}
@@ -3,10 +3,20 @@
 package pure
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"math"
 	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/redpanda-data/benthos/v4/internal/bloblang/query"
+	"github.com/redpanda-data/benthos/v4/internal/value"
 	"github.com/redpanda-data/benthos/v4/public/bloblang"
 )
 
@@ -34,6 +44,1052 @@ func init() {
 		}); err != nil {
 		panic(err)
 	}
+
+	if err := bloblang.RegisterMethodV2("parse_kv",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryParsing).
+			Description(`Parses a string of key/value pairs into an object, where the string is split into fields by a field separator and each field is split into a key and value by the first occurrence of a pair separator. Empty fields are ignored, fields without a pair separator are given the value `+"`true`"+`, and when a key occurs more than once the last value wins.
+
+When `+"`quotes`"+` is enabled values may be wrapped in double quotes, in which case field separators within them are ignored and escape sequences such as `+"`\\\"`"+` are decoded. Quoted values are always strings, even when `+"`cast`"+` is enabled.`).
+			Param(bloblang.NewStringParam("field_sep").Description("The separator between fields.").Default(" ")).
+			Param(bloblang.NewStringParam("pair_sep").Description("The separator between the key and value of a field.").Default("=")).
+			Param(bloblang.NewBoolParam("trim").Description("Whether to trim whitespace from the start and end of keys and values.").Default(true)).
+			Param(bloblang.NewBoolParam("cast").Description("Whether to convert unquoted values that are integers, floats or booleans into their respective types, otherwise all values are strings.").Default(false)).
+			Param(bloblang.NewBoolParam("quotes").Description("Whether values may be wrapped in double quotes.").Default(true)).
+			Example("", `root = this.line.parse_kv(cast: true)`,
+				[2]string{
+					`{"line":"level=info msg=\"user logged in\" status=200 cached=true"}`,
+					`{"cached":true,"level":"info","msg":"user logged in","status":200}`,
+				},
+			).
+			Example("", `root = this.header.parse_kv(field_sep: ";", pair_sep: ":")`,
+				[2]string{
+					`{"header":"id: 10; name: foo bar ;region:eu"}`,
+					`{"id":"10","name":"foo bar","region":"eu"}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			var p kvParser
+			var err error
+			if p.fieldSep, err = args.GetString("field_sep"); err != nil {
+				return nil, err
+			}
+			if p.pairSep, err = args.GetString("pair_sep"); err != nil {
+				return nil, err
+			}
+			if p.fieldSep == "" || p.pairSep == "" {
+				return nil, errors.New("separators must not be empty")
+			}
+			if p.trim, err = args.GetBool("trim"); err != nil {
+				return nil, err
+			}
+			if p.cast, err = args.GetBool("cast"); err != nil {
+				return nil, err
+			}
+			if p.quotes, err = args.GetBool("quotes"); err != nil {
+				return nil, err
+			}
+			return bloblang.StringMethod(p.parse), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("parse_logfmt",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryParsing).
+			Description(`Parses a https://brandur.org/logfmt[logfmt^] string into an object. Pairs are separated by whitespace, values containing whitespace, quotes or equals signs are wrapped in double quotes with escape sequences such as `+"`\\\"`"+` decoded, and keys without a value are given the value `+"`true`"+`. All other values are strings, and when a key occurs more than once the last value wins.`).
+			Example("", `root = this.line.parse_logfmt()`,
+				[2]string{
+					`{"line":"level=info msg=\"user \\\"foo\\\" logged in\" status=200 cached"}`,
+					`{"cached":true,"level":"info","msg":"user \"foo\" logged in","status":"200"}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return bloblang.StringMethod(func(s string) (any, error) {
+				return parseLogfmt(s)
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("format_logfmt",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryParsing).
+			Description(`Serializes an object into a https://brandur.org/logfmt[logfmt^] byte array, with pairs sorted by key. Values containing whitespace, quotes, equals signs or control characters are wrapped in double quotes and escaped, null values are written as `+"`null`"+` and structured values are written as JSON. An error is returned if a key is empty or contains characters that cannot be represented in logfmt.`).
+			Example("Use the `.string()` method in order to coerce the result into a string.", `root.line = this.format_logfmt().string()`,
+				[2]string{
+					`{"level":"info","msg":"user \"foo\" logged in","status":200,"cached":true}`,
+					`{"line":"cached=true level=info msg=\"user \\\"foo\\\" logged in\" status=200"}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return bloblang.ObjectMethod(func(obj map[string]any) (any, error) {
+				return formatLogfmt(obj)
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("sql_quote",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryStrings).
+			Description(`Wraps a string in single quotes so that it can be used as a SQL string literal, any single quotes within the string are escaped by doubling them. This follows the ANSI SQL standard, which is honoured by most databases, but MySQL in its default mode also treats backslashes as escape characters and therefore input containing backslashes should be treated with care.
+
+Parameterized queries should always be preferred over constructing SQL statements from strings, this method is intended only for situations where string construction is unavoidable.`).
+			Example("", `root.query = "SELECT * FROM users WHERE name = " + this.name.sql_quote()`,
+				[2]string{
+					`{"name":"O'Brien"}`,
+					`{"query":"SELECT * FROM users WHERE name = 'O''Brien'"}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return bloblang.StringMethod(func(s string) (any, error) {
+				return "'" + strings.ReplaceAll(s, "'", "''") + "'", nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("sql_identifier_quote",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryStrings).
+			Description(`Quotes a string so that it can be used as a SQL identifier (such as a table or column name) in the given dialect, any quote characters within the string are escaped by doubling them. The `+"`ansi`"+` and `+"`postgres`"+` dialects wrap identifiers in double quotes, and the `+"`mysql`"+` dialect wraps identifiers in backticks.
+
+Parameterized queries should always be preferred over constructing SQL statements from strings, this method is intended only for situations where string construction is unavoidable.`).
+			Param(bloblang.NewStringParam("dialect").Description("The SQL dialect to quote the identifier for, one of `ansi`, `mysql` or `postgres`.").Default("ansi")).
+			Example("", `root.query = "SELECT * FROM " + this.table.sql_identifier_quote()`,
+				[2]string{
+					`{"table":"my \"table\""}`,
+					`{"query":"SELECT * FROM \"my \"\"table\"\"\""}`,
+				},
+			).
+			Example("", `root.query = "SELECT * FROM " + this.table.sql_identifier_quote("mysql")`,
+				[2]string{
+					`{"table":"order"}`,
+					"{\"query\":\"SELECT * FROM `order`\"}",
+				},
+			).
+			Example("", `root.query = "SELECT * FROM " + this.table.sql_identifier_quote("postgres")`,
+				[2]string{
+					`{"table":"User"}`,
+					`{"query":"SELECT * FROM \"User\""}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			dialect, err := args.GetString("dialect")
+			if err != nil {
+				return nil, err
+			}
+			var quote string
+			switch dialect {
+			case "ansi", "postgres":
+				quote = `"`
+			case "mysql":
+				quote = "`"
+			default:
+				return nil, fmt.Errorf("unrecognised sql dialect: %v", dialect)
+			}
+			return bloblang.StringMethod(func(s string) (any, error) {
+				return quote + strings.ReplaceAll(s, quote, quote+quote) + quote, nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("shell_escape",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryStrings).
+			Description(`Quotes a string so that it is interpreted as a single argument by a shell, preventing any characters within it from being interpreted as shell syntax. By default the string is quoted for POSIX shells by wrapping it in single quotes, where any single quotes within the string are replaced with `+"`'\\''`"+`.
+
+When `+"`windows`"+` is set to `+"`true`"+` the string is instead quoted for `+"`cmd.exe`"+`: it is first wrapped in double quotes following the conventions used by most Windows programs for parsing their command line (escaping embedded double quotes and any backslashes that precede them), and then every character with special meaning to `+"`cmd.exe`"+` (including the wrapping quotes) is escaped with a caret.`).
+			Param(bloblang.NewBoolParam("windows").Description("Whether to quote the string for `cmd.exe` rather than a POSIX shell.").Default(false)).
+			Example("", `root.cmd = "echo " + this.text.shell_escape()`,
+				[2]string{
+					`{"text":"it's $HOME; rm -rf /"}`,
+					`{"cmd":"echo 'it'\\''s $HOME; rm -rf /'"}`,
+				},
+			).
+			Example("", `root.cmd = "echo " + this.text.shell_escape(windows: true)`,
+				[2]string{
+					`{"text":"50% & \"more\""}`,
+					`{"cmd":"echo ^\"50^% ^& \\^\"more\\^\"^\""}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			windows, err := args.GetBool("windows")
+			if err != nil {
+				return nil, err
+			}
+			if windows {
+				return bloblang.StringMethod(func(s string) (any, error) {
+					return shellEscapeWindows(s), nil
+				}), nil
+			}
+			return bloblang.StringMethod(func(s string) (any, error) {
+				return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'", nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("word_count",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryStrings).
+			Description(`Counts the number of words within a string. Words are sequences of characters separated by any amount of Unicode whitespace, and sequences consisting solely of punctuation or symbols (such as a standalone dash) are not counted.`).
+			Example("", `root.words = this.text.word_count()`,
+				[2]string{
+					`{"text":"  The quick brown fox -- it jumped!\n\nOver the lazy dog.  "}`,
+					`{"words":10}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return bloblang.StringMethod(func(s string) (any, error) {
+				return wordCount(s), nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("reading_time",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryStrings).
+			Description(`Estimates the number of minutes it would take to read a string at a given reading speed, rounded up to the nearest whole minute. Words are counted following the same rules as `+"<<word_count, `word_count`>>"+`, and a string containing no words results in `+"`0`"+`.`).
+			Param(bloblang.NewInt64Param("wpm").Description("The reading speed in words per minute.").Default(200)).
+			Example("", `root.minutes = this.text.reading_time()`,
+				[2]string{
+					`{"text":"Short and sweet."}`,
+					`{"minutes":1}`,
+				},
+			).
+			Example("", `root.minutes = this.text.reading_time(wpm: 2)`,
+				[2]string{
+					`{"text":"One two three four five."}`,
+					`{"minutes":3}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			wpm, err := args.GetInt64("wpm")
+			if err != nil {
+				return nil, err
+			}
+			if wpm <= 0 {
+				return nil, fmt.Errorf("wpm must be greater than zero, got %v", wpm)
+			}
+			return bloblang.StringMethod(func(s string) (any, error) {
+				words := wordCount(s)
+				return (words + wpm - 1) / wpm, nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("contains_profanity",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryStrings).
+			Description(`Checks whether a string contains any words from a list of profanities, returning a boolean. Matching is case-insensitive and only applies to whole words, so a listed word appearing within a longer word is not matched. A small built-in list of common English profanities is used unless a custom list is provided.
+
+This is a simple word list filter intended as a first pass, it is easily bypassed (with misspellings, substituted characters, etc) and is not a substitute for proper content moderation.`).
+			Param(bloblang.NewAnyParam("wordlist").Description("An optional array of words to match instead of the built-in list.").Optional()).
+			Example("", `root.flagged = this.comment.contains_profanity()`,
+				[2]string{
+					`{"comment":"What the HELL is this?"}`,
+					`{"flagged":true}`,
+				},
+				[2]string{
+					`{"comment":"Hello shell"}`,
+					`{"flagged":false}`,
+				},
+			).
+			Example("", `root.flagged = this.comment.contains_profanity(["darn","heck"])`,
+				[2]string{
+					`{"comment":"Oh heck."}`,
+					`{"flagged":true}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			re, err := profanityRegexpFromArgs(args)
+			if err != nil {
+				return nil, err
+			}
+			return bloblang.StringMethod(func(s string) (any, error) {
+				return re.MatchString(s), nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("mask_profanity",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryStrings).
+			Description(`Replaces each character of any words within a string that match a list of profanities with a mask. Matching follows the same rules as `+"<<contains_profanity, `contains_profanity`>>"+`, and a small built-in list of common English profanities is used unless a custom list is provided.
+
+This is a simple word list filter intended as a first pass, it is easily bypassed (with misspellings, substituted characters, etc) and is not a substitute for proper content moderation.`).
+			Param(bloblang.NewAnyParam("wordlist").Description("An optional array of words to match instead of the built-in list.").Optional()).
+			Param(bloblang.NewStringParam("mask").Description("A string to replace each character of a matched word with.").Default("*")).
+			Example("", `root.comment = this.comment.mask_profanity()`,
+				[2]string{
+					`{"comment":"What the Hell is this crap?"}`,
+					`{"comment":"What the **** is this ****?"}`,
+				},
+			).
+			Example("", `root.comment = this.comment.mask_profanity(wordlist: ["darn","heck"], mask: "#")`,
+				[2]string{
+					`{"comment":"Oh heck, darn it."}`,
+					`{"comment":"Oh ####, #### it."}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			re, err := profanityRegexpFromArgs(args)
+			if err != nil {
+				return nil, err
+			}
+			mask, err := args.GetString("mask")
+			if err != nil {
+				return nil, err
+			}
+			return bloblang.StringMethod(func(s string) (any, error) {
+				return re.ReplaceAllStringFunc(s, func(match string) string {
+					return strings.Repeat(mask, utf8.RuneCountInString(match))
+				}), nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("translate",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryStrings).
+			Description(`Replaces each character of a string that appears within `+"`from`"+` with the character at the same position within `+"`to`"+`, similar to the Unix `+"`tr`"+` command. Characters are Unicode code points and if a character appears more than once within `+"`from`"+` then its first position is used. Characters of `+"`from`"+` that have no counterpart because `+"`to`"+` is shorter are deleted from the string, unless `+"`strict`"+` is set, in which case `+"`from` and `to`"+` must have the same length.`).
+			Param(bloblang.NewStringParam("from").Description("The characters to replace.")).
+			Param(bloblang.NewStringParam("to").Description("The characters to replace with, where each character replaces the character of `from` at the same position.")).
+			Param(bloblang.NewBoolParam("strict").Description("Whether to return an error when `from` and `to` are of different lengths rather than deleting characters without a counterpart.").Default(false)).
+			Example("", `root.result = this.value.translate("el", "ip")`,
+				[2]string{
+					`{"value":"hello"}`,
+					`{"result":"hippo"}`,
+				},
+			).
+			Example("Characters without a counterpart are deleted:", `root.digits = this.phone.translate("()- ", "")`,
+				[2]string{
+					`{"phone":"(555) 123-4567"}`,
+					`{"digits":"5551234567"}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			from, err := args.GetString("from")
+			if err != nil {
+				return nil, err
+			}
+			to, err := args.GetString("to")
+			if err != nil {
+				return nil, err
+			}
+			strict, err := args.GetBool("strict")
+			if err != nil {
+				return nil, err
+			}
+			mapping, err := translateMapping(from, to, strict)
+			if err != nil {
+				return nil, err
+			}
+			return bloblang.StringMethod(func(s string) (any, error) {
+				return strings.Map(func(r rune) rune {
+					if to, exists := mapping[r]; exists {
+						return to
+					}
+					return r
+				}, s), nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("squeeze",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryStrings).
+			Description(`Collapses each run of a repeated character within a string into a single occurrence of that character, similar to `+"`tr -s`"+`. Only characters within `+"`chars`"+` are squeezed, and when `+"`chars`"+` is not provided all Unicode whitespace characters are squeezed. Runs consist of the same character repeated, and therefore adjacent characters that differ (such as a space followed by a tab) are each kept.`).
+			Param(bloblang.NewStringParam("chars").Description("An optional string of characters to squeeze, defaults to all whitespace.").Optional()).
+			Example("", `root.result = this.value.squeeze(",")`,
+				[2]string{
+					`{"value":"a,,,b,,c"}`,
+					`{"result":"a,b,c"}`,
+				},
+			).
+			Example("", `root.result = this.value.squeeze()`,
+				[2]string{
+					`{"value":"hello    world\n\n\nfoo"}`,
+					`{"result":"hello world\nfoo"}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			chars, err := args.GetOptionalString("chars")
+			if err != nil {
+				return nil, err
+			}
+			shouldSqueeze := unicode.IsSpace
+			if chars != nil {
+				set := *chars
+				shouldSqueeze = func(r rune) bool {
+					return strings.ContainsRune(set, r)
+				}
+			}
+			return bloblang.StringMethod(func(s string) (any, error) {
+				return squeeze(s, shouldSqueeze), nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	alignSpec := func(description string) *bloblang.PluginSpec {
+		return bloblang.NewPluginSpec().
+			Category(query.MethodCategoryStrings).
+			Description(description + ` The width is measured in Unicode code points, and strings that are already at least as wide as ` + "`width`" + ` are returned unchanged rather than truncated.`).
+			Param(bloblang.NewInt64Param("width").Description("The minimum width of the resulting string.")).
+			Param(bloblang.NewStringParam("char").Description("The character to pad the string with, which must be a single character.").Default(" "))
+	}
+
+	alignCtor := func(align func(s string, padding int, char string) string) bloblang.MethodConstructorV2 {
+		return func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			width, err := args.GetInt64("width")
+			if err != nil {
+				return nil, err
+			}
+			char, err := args.GetString("char")
+			if err != nil {
+				return nil, err
+			}
+			if utf8.RuneCountInString(char) != 1 {
+				return nil, fmt.Errorf("char must be a single character, got %q", char)
+			}
+			return bloblang.StringMethod(func(s string) (any, error) {
+				padding := int(width) - utf8.RuneCountInString(s)
+				if padding <= 0 {
+					return s, nil
+				}
+				return align(s, padding, char), nil
+			}), nil
+		}
+	}
+
+	if err := bloblang.RegisterMethodV2("ljust",
+		alignSpec(`Aligns a string to the left by padding its end with a character until it is at least a given width.`).
+			Example("", `root.row = this.name.ljust(8, ".") + this.score.string().rjust(4)`,
+				[2]string{
+					`{"name":"ash","score":42}`,
+					`{"row":"ash.....  42"}`,
+				},
+			),
+		alignCtor(func(s string, padding int, char string) string {
+			return s + strings.Repeat(char, padding)
+		})); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("rjust",
+		alignSpec(`Aligns a string to the right by padding its start with a character until it is at least a given width.`).
+			Example("", `root.id = this.id.string().rjust(6, "0")`,
+				[2]string{
+					`{"id":42}`,
+					`{"id":"000042"}`,
+				},
+				[2]string{
+					`{"id":1234567}`,
+					`{"id":"1234567"}`,
+				},
+			),
+		alignCtor(func(s string, padding int, char string) string {
+			return strings.Repeat(char, padding) + s
+		})); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("center",
+		alignSpec(`Centers a string by padding both its start and end with a character until it is at least a given width. When the padding cannot be split evenly the extra character is added to the end.`).
+			Example("", `root.title = this.title.center(11, "*")`,
+				[2]string{
+					`{"title":"hello"}`,
+					`{"title":"***hello***"}`,
+				},
+				[2]string{
+					`{"title":"hi"}`,
+					`{"title":"****hi*****"}`,
+				},
+			),
+		alignCtor(func(s string, padding int, char string) string {
+			left := padding / 2
+			return strings.Repeat(char, left) + s + strings.Repeat(char, padding-left)
+		})); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("title_case",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryStrings).
+			Description(`Converts a string to title case following the rules of a style guide, where small words such as articles, conjunctions and prepositions remain lowercase unless they are the first or last word of the title, or follow a colon. The first letter of every other word is capitalized and the remaining letters are left unchanged in order to preserve acronyms and names such as `+"`NASA` or `iPhone`"+`. The parts of hyphenated words are treated as separate words.
+
+The `+"`ap`"+` style (the default) keeps articles, coordinating conjunctions and prepositions of three letters or fewer lowercase, whereas the `+"`chicago`"+` style keeps articles, coordinating conjunctions (except for "so" and "yet") and all prepositions lowercase.`).
+			Param(bloblang.NewStringParam("style").Description("The style guide to follow, either `ap` or `chicago`.").Default("ap")).
+			Param(bloblang.NewAnyParam("small_words").Description("An optional array of small words to keep lowercase, overriding the list of the chosen style.").Optional()).
+			Example("", `root.title = this.title.title_case()`,
+				[2]string{
+					`{"title":"the lord of the rings: the return of the king"}`,
+					`{"title":"The Lord of the Rings: The Return of the King"}`,
+				},
+				[2]string{
+					`{"title":"a guide to NASA missions through the years"}`,
+					`{"title":"A Guide to NASA Missions Through the Years"}`,
+				},
+			).
+			Example("", `root.title = this.title.title_case(style: "chicago")`,
+				[2]string{
+					`{"title":"a guide to NASA missions through the years"}`,
+					`{"title":"A Guide to NASA Missions through the Years"}`,
+				},
+			).
+			Example("", `root.title = this.title.title_case(small_words: ["and", "or", "vs"])`,
+				[2]string{
+					`{"title":"cats vs dogs and other rivalries"}`,
+					`{"title":"Cats vs Dogs and Other Rivalries"}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			style, err := args.GetString("style")
+			if err != nil {
+				return nil, err
+			}
+			smallWords, exists := titleCaseSmallWords[style]
+			if !exists {
+				return nil, fmt.Errorf("unrecognised title case style: %v", style)
+			}
+			smallWordsV, err := args.Get("small_words")
+			if err != nil {
+				return nil, err
+			}
+			if smallWordsV != nil {
+				wordsArr, ok := smallWordsV.([]any)
+				if !ok {
+					return nil, fmt.Errorf("small_words: %w", value.NewTypeError(smallWordsV, value.TArray))
+				}
+				smallWords = make(map[string]struct{}, len(wordsArr))
+				for i, w := range wordsArr {
+					wStr, err := value.IGetString(w)
+					if err != nil {
+						return nil, fmt.Errorf("small_words index %v: %w", i, err)
+					}
+					smallWords[strings.ToLower(wStr)] = struct{}{}
+				}
+			}
+			return bloblang.StringMethod(func(s string) (any, error) {
+				return titleCase(s, smallWords), nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+	if err := bloblang.RegisterMethodV2("is_valid_utf8",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryStrings).
+			Description(`Checks whether a string or byte array consists entirely of valid UTF-8 encoded characters, returning a boolean. Raw binary data that is not valid UTF-8 cannot be faithfully represented within JSON documents, see `+"<<sanitize_utf8, `sanitize_utf8`>>"+` for cleaning such data.`).
+			Example("", `root.valid = this.hex.decode("hex").is_valid_utf8()`,
+				[2]string{`{"hex":"68656c6c6f"}`, `{"valid":true}`},
+				[2]string{`{"hex":"68656cff6f"}`, `{"valid":false}`},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return bloblang.BytesMethod(func(b []byte) (any, error) {
+				return utf8.Valid(b), nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("sanitize_utf8",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryStrings).
+			Description(`Converts a string or byte array into a valid UTF-8 string by replacing each run of invalid bytes with a replacement string, which defaults to the Unicode replacement character (U+FFFD). This prevents failures when serializing raw binary data as JSON.`).
+			Param(bloblang.NewStringParam("replacement").Description("The string to replace each run of invalid bytes with, which may be empty in order to remove them.").Default("\uFFFD")).
+			Example("", `root.clean = this.hex.decode("hex").sanitize_utf8()`,
+				[2]string{`{"hex":"68656cff6f"}`, `{"clean":"hel` + "\uFFFD" + `o"}`},
+			).
+			Example("", `root.clean = this.hex.decode("hex").sanitize_utf8("")`,
+				[2]string{`{"hex":"68656cfffe6f"}`, `{"clean":"helo"}`},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			replacement, err := args.GetString("replacement")
+			if err != nil {
+				return nil, err
+			}
+			return bloblang.BytesMethod(func(b []byte) (any, error) {
+				return string(bytes.ToValidUTF8(b, []byte(replacement))), nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("byte_at",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryStrings).
+			Description(`Returns the value of the byte at an index of a string or byte array as an integer. A negative index counts backwards from the end, where `+"`-1`"+` is the last byte. An error is returned if the index is out of bounds.`).
+			Param(bloblang.NewInt64Param("index").Description("The index of the byte.")).
+			Example("", `root.first = this.value.byte_at(0)
+root.last = this.value.byte_at(-1)`,
+				[2]string{`{"value":"hello"}`, `{"first":104,"last":111}`},
+			).
+			Example("", `root.version = this.packet.decode("hex").byte_at(0)`,
+				[2]string{`{"packet":"02ff00"}`, `{"version":2}`},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			index, err := args.GetInt64("index")
+			if err != nil {
+				return nil, err
+			}
+			return bloblang.BytesMethod(func(b []byte) (any, error) {
+				i, err := resolveIndex(index, len(b))
+				if err != nil {
+					return nil, err
+				}
+				return int64(b[i]), nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("rune_at",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryStrings).
+			Description(`Returns the Unicode code point of the character at an index of a string as an integer, where the index counts characters rather than bytes so that multi-byte characters are a single element. A negative index counts backwards from the end, where `+"`-1`"+` is the last character. An error is returned if the index is out of bounds.`).
+			Param(bloblang.NewInt64Param("index").Description("The index of the character.")).
+			Example("", `root.first = this.value.rune_at(0)
+root.last = this.value.rune_at(-1)`,
+				[2]string{`{"value":"héllo wörld €"}`, `{"first":104,"last":8364}`},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			index, err := args.GetInt64("index")
+			if err != nil {
+				return nil, err
+			}
+			return bloblang.StringMethod(func(s string) (any, error) {
+				runes := []rune(s)
+				i, err := resolveIndex(index, len(runes))
+				if err != nil {
+					return nil, err
+				}
+				return int64(runes[i]), nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("chars",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryStrings).
+			Description(`Splits a string into an array of its characters, where each element is a string containing a single Unicode code point, so that multi-byte characters are a single element.`).
+			Example("", `root.chars = this.value.chars()`,
+				[2]string{`{"value":"héllo"}`, `{"chars":["h","é","l","l","o"]}`},
+			).
+			Example("Count the vowels of a string.", `root.vowels = this.value.lowercase().chars().filter(c -> "aeiou".contains(c)).length()`,
+				[2]string{`{"value":"Hello World"}`, `{"vowels":3}`},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return bloblang.StringMethod(func(s string) (any, error) {
+				chars := make([]any, 0, utf8.RuneCountInString(s))
+				for _, r := range s {
+					chars = append(chars, string(r))
+				}
+				return chars, nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("bytes_array",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryStrings).
+			Description(`Converts a string or byte array into an array of the integer values of its bytes.`).
+			Example("", `root.bytes = this.value.bytes_array()`,
+				[2]string{`{"value":"hé"}`, `{"bytes":[104,195,169]}`},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return bloblang.BytesMethod(func(b []byte) (any, error) {
+				arr := make([]any, len(b))
+				for i, c := range b {
+					arr[i] = int64(c)
+				}
+				return arr, nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("indent",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryStrings).
+			Description(`Prepends a prefix to each line of a string, which is useful for embedding documents such as YAML or code within other documents. Lines are terminated by either LF or CRLF and their endings are preserved, a line ending at the end of the string does not begin an extra line. Blank lines, which are empty or contain only whitespace, are left unchanged unless `+"`blank_lines`"+` is `+"`true`"+`.`).
+			Param(bloblang.NewStringParam("prefix").Description("The prefix to add to each line.")).
+			Param(bloblang.NewBoolParam("blank_lines").Description("Whether to also prefix blank lines.").Default(false)).
+			Example("", `root.doc = "config:\n" + this.body.indent("  ")`,
+				[2]string{`{"body":"foo: 1\n\nbar: 2\n"}`, `{"doc":"config:\n  foo: 1\n\n  bar: 2\n"}`},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			prefix, err := args.GetString("prefix")
+			if err != nil {
+				return nil, err
+			}
+			blankLines, err := args.GetBool("blank_lines")
+			if err != nil {
+				return nil, err
+			}
+			return bloblang.StringMethod(func(s string) (any, error) {
+				return indentLines(s, prefix, blankLines), nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("dedent",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryStrings).
+			Description(`Removes the longest common leading whitespace (spaces and tabs) from each line of a string, ignoring blank lines, which are empty or contain only whitespace. Blank lines are emptied whilst their line endings, which may be either LF or CRLF, are preserved. Spaces and tabs are not considered equal, and so lines indented with a mixture of both may not share any common whitespace.`).
+			Example("", `root.script = this.script.dedent()`,
+				[2]string{`{"script":"    if true; then\n      echo hi\n    fi\n"}`, `{"script":"if true; then\n  echo hi\nfi\n"}`},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return bloblang.StringMethod(func(s string) (any, error) {
+				return dedent(s), nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("prefix_lines",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryStrings).
+			Description(`Adds a prefix to the start of every line of a string, including blank lines, which is useful for commenting out blocks of text. This is equivalent to `+"<<indent, `indent`>>"+` with `+"`blank_lines`"+` set to `+"`true`"+`.`).
+			Param(bloblang.NewStringParam("prefix").Description("The prefix to add to each line.")).
+			Example("", `root.script = this.script.prefix_lines("# ")`,
+				[2]string{`{"script":"echo foo\n\necho bar\n"}`, `{"script":"# echo foo\n# \n# echo bar\n"}`},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			prefix, err := args.GetString("prefix")
+			if err != nil {
+				return nil, err
+			}
+			return bloblang.StringMethod(func(s string) (any, error) {
+				return indentLines(s, prefix, true), nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("strip_empty_lines",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryStrings).
+			Description(`Removes all blank lines, which are empty or contain only whitespace, from a string. Lines are terminated by either LF or CRLF and their endings are preserved. The result only ends with a line ending when the original string does, regardless of whether its final line was removed.`).
+			Example("", `root.text = this.text.strip_empty_lines()`,
+				[2]string{`{"text":"foo\n\n  \nbar\n\n"}`, `{"text":"foo\nbar\n"}`},
+				[2]string{`{"text":"foo\n\t"}`, `{"text":"foo"}`},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return bloblang.StringMethod(func(s string) (any, error) {
+				return stripEmptyLines(s), nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+}
+
+// resolveIndex converts an index that may be negative, in which case it counts
+// backwards from the end, into a position within a sequence of a given length.
+func resolveIndex(index int64, length int) (int, error) {
+	i := index
+	if i < 0 {
+		i += int64(length)
+	}
+	if i < 0 || i >= int64(length) {
+		return 0, fmt.Errorf("index %v is out of bounds for length %v", index, length)
+	}
+	return int(i), nil
+}
+
+type textLine struct {
+	content string
+	ending  string
+}
+
+// textLines splits a string into its lines, where each line is terminated by
+// either LF or CRLF, or by the end of the string. A line ending at the end of
+// the string does not begin an additional empty line.
+func textLines(s string) []textLine {
+	var lines []textLine
+	for len(s) > 0 {
+		i := strings.IndexByte(s, '\n')
+		if i == -1 {
+			lines = append(lines, textLine{content: s})
+			break
+		}
+		l := textLine{content: s[:i], ending: "\n"}
+		if strings.HasSuffix(l.content, "\r") {
+			l.content, l.ending = l.content[:len(l.content)-1], "\r\n"
+		}
+		lines = append(lines, l)
+		s = s[i+1:]
+	}
+	return lines
+}
+
+func isBlankLine(s string) bool {
+	return strings.TrimSpace(s) == ""
+}
+
+// indentLines adds a prefix to each line of a string, blank lines are only
+// prefixed when blankLines is true.
+func indentLines(s, prefix string, blankLines bool) string {
+	var buf strings.Builder
+	for _, l := range textLines(s) {
+		if blankLines || !isBlankLine(l.content) {
+			buf.WriteString(prefix)
+		}
+		buf.WriteString(l.content)
+		buf.WriteString(l.ending)
+	}
+	return buf.String()
+}
+
+func dedent(s string) string {
+	lines := textLines(s)
+
+	var margin string
+	marginSet := false
+	for _, l := range lines {
+		if isBlankLine(l.content) {
+			continue
+		}
+		indent := l.content[:len(l.content)-len(strings.TrimLeft(l.content, " \t"))]
+		if !marginSet {
+			margin, marginSet = indent, true
+			continue
+		}
+		i := 0
+		for i < len(margin) && i < len(indent) && margin[i] == indent[i] {
+			i++
+		}
+		margin = margin[:i]
+	}
+
+	var buf strings.Builder
+	for _, l := range lines {
+		if !isBlankLine(l.content) {
+			buf.WriteString(l.content[len(margin):])
+		}
+		buf.WriteString(l.ending)
+	}
+	return buf.String()
+}
+
+func stripEmptyLines(s string) string {
+	lines := textLines(s)
+	kept := make([]textLine, 0, len(lines))
+	for _, l := range lines {
+		if !isBlankLine(l.content) {
+			kept = append(kept, l)
+		}
+	}
+	if len(kept) == 0 {
+		return ""
+	}
+
+	// The final line ending is determined by the original string rather than
+	// by whichever line happens to be kept last.
+	kept[len(kept)-1].ending = lines[len(lines)-1].ending
+
+	var buf strings.Builder
+	for _, l := range kept {
+		buf.WriteString(l.content)
+		buf.WriteString(l.ending)
+	}
+	return buf.String()
+}
+
+type kvParser struct {
+	fieldSep string
+	pairSep  string
+	trim     bool
+	cast     bool
+	quotes   bool
+}
+
+// fields splits a string by the field separator, ignoring separators that are
+// within double quotes when quotes are enabled.
+func (p kvParser) fields(s string) []string {
+	if !p.quotes {
+		return strings.Split(s, p.fieldSep)
+	}
+	var fields []string
+	inQuotes, start := false, 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case inQuotes && s[i] == '\\':
+			i++
+		case s[i] == '"':
+			inQuotes = !inQuotes
+		case !inQuotes && strings.HasPrefix(s[i:], p.fieldSep):
+			fields = append(fields, s[start:i])
+			i += len(p.fieldSep) - 1
+			start = i + 1
+		}
+	}
+	return append(fields, s[start:])
+}
+
+func (p kvParser) parse(s string) (any, error) {
+	res := map[string]any{}
+	for _, field := range p.fields(s) {
+		if strings.TrimSpace(field) == "" {
+			continue
+		}
+		k, v, hasValue := strings.Cut(field, p.pairSep)
+		if p.trim {
+			k, v = strings.TrimSpace(k), strings.TrimSpace(v)
+		}
+		if !hasValue {
+			res[k] = true
+			continue
+		}
+		if p.quotes && len(v) >= 2 && v[0] == '"' && v[len(v)-1] == '"' {
+			unquoted, err := strconv.Unquote(v)
+			if err != nil {
+				return nil, fmt.Errorf("key %v: failed to unquote value: %w", k, err)
+			}
+			res[k] = unquoted
+			continue
+		}
+		res[k] = v
+		if p.cast {
+			res[k] = castKVValue(v)
+		}
+	}
+	return res, nil
+}
+
+func castKVValue(v string) any {
+	if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(v, 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) {
+		return f
+	}
+	switch v {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	return v
+}
+
+func isLogfmtSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r'
+}
+
+func parseLogfmt(s string) (map[string]any, error) {
+	res := map[string]any{}
+	i := 0
+	for {
+		for i < len(s) && isLogfmtSpace(s[i]) {
+			i++
+		}
+		if i >= len(s) {
+			return res, nil
+		}
+
+		start := i
+		for i < len(s) && s[i] != '=' && !isLogfmtSpace(s[i]) {
+			if s[i] == '"' {
+				return nil, fmt.Errorf("unexpected quote in key at offset %v", i)
+			}
+			i++
+		}
+		key := s[start:i]
+		if key == "" {
+			return nil, fmt.Errorf("missing key at offset %v", i)
+		}
+		if i >= len(s) || s[i] != '=' {
+			res[key] = true
+			continue
+		}
+		i++
+
+		if i < len(s) && s[i] == '"' {
+			start = i
+			for i++; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' {
+					i++
+				}
+			}
+			if i >= len(s) {
+				return nil, fmt.Errorf("key %v: unterminated quoted value", key)
+			}
+			i++
+			unquoted, err := strconv.Unquote(s[start:i])
+			if err != nil {
+				return nil, fmt.Errorf("key %v: failed to unquote value: %w", key, err)
+			}
+			if i < len(s) && !isLogfmtSpace(s[i]) {
+				return nil, fmt.Errorf("key %v: unexpected character after quoted value at offset %v", key, i)
+			}
+			res[key] = unquoted
+			continue
+		}
+
+		start = i
+		for i < len(s) && !isLogfmtSpace(s[i]) {
+			if s[i] == '"' {
+				return nil, fmt.Errorf("key %v: unexpected quote in unquoted value at offset %v", key, i)
+			}
+			i++
+		}
+		res[key] = s[start:i]
+	}
+}
+
+// logfmtNeedsQuotes returns whether a value must be quoted in order to be
+// represented in logfmt.
+func logfmtNeedsQuotes(s string) bool {
+	if !utf8.ValidString(s) {
+		return true
+	}
+	for _, r := range s {
+		if r <= ' ' || r == '=' || r == '"' || r == '\\' || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+	return false
+}
+
+func formatLogfmt(obj map[string]any) ([]byte, error) {
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		if k == "" || logfmtNeedsQuotes(k) {
+			return nil, fmt.Errorf("key %q cannot be represented in logfmt", k)
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	for i, k := range keys {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(k)
+		buf.WriteByte('=')
+
+		v := value.IToString(obj[k])
+		if logfmtNeedsQuotes(v) {
+			v = strconv.Quote(v)
+		}
+		buf.WriteString(v)
+	}
+	return buf.Bytes(), nil
 }
 
 func urlValuesToMap(values url.Values) map[string]any {
@@ -53,3 +1109,184 @@ func urlValuesToMap(values url.Values) map[string]any {
 
 	return root
 }
+
+func shellEscapeWindows(s string) string {
+	var quoted strings.Builder
+	quoted.WriteByte('"')
+	slashes := 0
+	for _, c := range s {
+		switch c {
+		case '\\':
+			slashes++
+			continue
+		case '"':
+			// Backslashes preceding a double quote must be escaped, as well as
+			// the quote itself.
+			quoted.WriteString(strings.Repeat(`\`, slashes*2+1))
+			slashes = 0
+		default:
+			quoted.WriteString(strings.Repeat(`\`, slashes))
+			slashes = 0
+		}
+		quoted.WriteRune(c)
+	}
+	// Trailing backslashes would otherwise escape the closing quote.
+	quoted.WriteString(strings.Repeat(`\`, slashes*2))
+	quoted.WriteByte('"')
+
+	var escaped strings.Builder
+	for _, c := range quoted.String() {
+		if strings.ContainsRune(`()%!^"<>&|`, c) {
+			escaped.WriteByte('^')
+		}
+		escaped.WriteRune(c)
+	}
+	return escaped.String()
+}
+
+func wordCount(s string) int64 {
+	var count int64
+	for _, field := range strings.Fields(s) {
+		if strings.IndexFunc(field, func(r rune) bool {
+			return unicode.IsLetter(r) || unicode.IsNumber(r)
+		}) >= 0 {
+			count++
+		}
+	}
+	return count
+}
+
+var defaultProfanities = []string{
+	"arse", "arsehole", "ass", "asshole", "bastard", "bitch", "bollocks",
+	"bullshit", "crap", "cunt", "damn", "dick", "dickhead", "fuck", "fucked",
+	"fucker", "fucking", "goddamn", "hell", "motherfucker", "piss", "pissed",
+	"prick", "shit", "shitty", "slut", "twat", "wanker", "whore",
+}
+
+var defaultProfanityRegexp = profanityRegexp(defaultProfanities)
+
+func profanityRegexp(words []string) *regexp.Regexp {
+	quoted := make([]string, len(words))
+	for i, w := range words {
+		quoted[i] = regexp.QuoteMeta(w)
+	}
+	return regexp.MustCompile(`(?i)\b(?:` + strings.Join(quoted, "|") + `)\b`)
+}
+
+func profanityRegexpFromArgs(args *bloblang.ParsedParams) (*regexp.Regexp, error) {
+	wordsV, err := args.Get("wordlist")
+	if err != nil {
+		return nil, err
+	}
+	if wordsV == nil {
+		return defaultProfanityRegexp, nil
+	}
+	wordsArr, ok := wordsV.([]any)
+	if !ok {
+		return nil, value.NewTypeError(wordsV, value.TArray)
+	}
+	if len(wordsArr) == 0 {
+		return nil, errors.New("wordlist must contain at least one word")
+	}
+	words := make([]string, len(wordsArr))
+	for i, w := range wordsArr {
+		if words[i], err = value.IGetString(w); err != nil {
+			return nil, fmt.Errorf("wordlist element %v: %w", i, err)
+		}
+	}
+	return profanityRegexp(words), nil
+}
+
+// translateMapping returns a map of runes from one string to the rune at the
+// same position within another, where runes without a counterpart map to -1 in
+// order to be dropped by strings.Map.
+func translateMapping(from, to string, strict bool) (map[rune]rune, error) {
+	fromRunes, toRunes := []rune(from), []rune(to)
+	if strict && len(fromRunes) != len(toRunes) {
+		return nil, fmt.Errorf("from and to must be the same length in strict mode, got %v and %v characters", len(fromRunes), len(toRunes))
+	}
+	mapping := make(map[rune]rune, len(fromRunes))
+	for i, r := range fromRunes {
+		if _, exists := mapping[r]; exists {
+			continue
+		}
+		if i < len(toRunes) {
+			mapping[r] = toRunes[i]
+		} else {
+			mapping[r] = -1
+		}
+	}
+	return mapping, nil
+}
+
+func squeeze(s string, shouldSqueeze func(r rune) bool) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	last := rune(-1)
+	for _, r := range s {
+		if r == last && shouldSqueeze(r) {
+			continue
+		}
+		b.WriteRune(r)
+		last = r
+	}
+	return b.String()
+}
+
+func wordSet(words ...string) map[string]struct{} {
+	set := make(map[string]struct{}, len(words))
+	for _, w := range words {
+		set[w] = struct{}{}
+	}
+	return set
+}
+
+var titleCaseSmallWords = map[string]map[string]struct{}{
+	"ap": wordSet(
+		"a", "an", "the",
+		"and", "but", "for", "nor", "or", "so", "yet",
+		"as", "at", "by", "in", "of", "off", "on", "per", "to", "up", "via",
+	),
+	"chicago": wordSet(
+		"a", "an", "the",
+		"and", "but", "for", "nor", "or",
+		"about", "above", "across", "after", "against", "along", "among", "around",
+		"as", "at", "before", "behind", "below", "beneath", "beside", "between",
+		"beyond", "by", "despite", "down", "during", "except", "from", "in",
+		"inside", "into", "like", "near", "of", "off", "on", "onto", "out",
+		"outside", "over", "past", "per", "since", "through", "throughout", "till",
+		"to", "toward", "towards", "under", "underneath", "until", "up", "upon",
+		"via", "with", "within", "without",
+	),
+}
+
+var titleCaseWordRegexp = regexp.MustCompile(`[\p{L}\p{N}]+(?:['’][\p{L}\p{N}]+)*`)
+
+func titleCase(s string, smallWords map[string]struct{}) string {
+	matches := titleCaseWordRegexp.FindAllStringIndex(s, -1)
+
+	var b strings.Builder
+	b.Grow(len(s))
+
+	prev := 0
+	for i, m := range matches {
+		between := s[prev:m[0]]
+		b.WriteString(between)
+
+		word := s[m[0]:m[1]]
+		lowerWord := strings.ToLower(word)
+
+		_, isSmall := smallWords[lowerWord]
+		isBoundary := i == 0 || i == len(matches)-1 || strings.ContainsAny(between, ":!?.")
+		if isSmall && !isBoundary {
+			b.WriteString(lowerWord)
+		} else {
+			r, size := utf8.DecodeRuneInString(word)
+			b.WriteRune(unicode.ToTitle(r))
+			b.WriteString(word[size:])
+		}
+		prev = m[1]
+	}
+	b.WriteString(s[prev:])
+	return b.String()
+}
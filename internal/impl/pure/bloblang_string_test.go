@@ -10,6 +10,7 @@ import (
 
 	"github.com/redpanda-data/benthos/v4/internal/bloblang/query"
 	"github.com/redpanda-data/benthos/v4/internal/value"
+	"github.com/redpanda-data/benthos/v4/public/bloblang"
 )
 
 func TestParseUrlencoded(t *testing.T) {
@@ -65,3 +66,778 @@ func TestParseUrlencoded(t *testing.T) {
 		})
 	}
 }
+
+func TestSQLQuoting(t *testing.T) {
+	testCases := []struct {
+		name   string
+		method string
+		target any
+		args   []any
+		exp    any
+	}{
+		{
+			name:   "sql quote no quotes",
+			method: "sql_quote",
+			target: "foo bar",
+			exp:    "'foo bar'",
+		},
+		{
+			name:   "sql quote multiple quotes",
+			method: "sql_quote",
+			target: "'; DROP TABLE users; --'",
+			exp:    "'''; DROP TABLE users; --'''",
+		},
+		{
+			name:   "mysql identifier with backticks",
+			method: "sql_identifier_quote",
+			target: "a`b",
+			args:   []any{"mysql"},
+			exp:    "`a``b`",
+		},
+		{
+			name:   "mysql identifier with double quotes",
+			method: "sql_identifier_quote",
+			target: `a"b`,
+			args:   []any{"mysql"},
+			exp:    "`a\"b`",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			fn, err := query.InitMethodHelper(test.method, query.NewLiteralFunction("", test.target), test.args...)
+			require.NoError(t, err)
+
+			res, err := fn.Exec(query.FunctionContext{})
+			require.NoError(t, err)
+			assert.Equal(t, test.exp, res)
+		})
+	}
+
+	_, err := query.InitMethodHelper("sql_identifier_quote", query.NewLiteralFunction("", "foo"), "oracle")
+	require.EqualError(t, err, "unrecognised sql dialect: oracle")
+}
+
+func TestShellEscape(t *testing.T) {
+	testCases := []struct {
+		name   string
+		target any
+		args   []any
+		exp    any
+	}{
+		{
+			name:   "posix empty",
+			target: "",
+			exp:    "''",
+		},
+		{
+			name:   "posix only quotes",
+			target: "''",
+			exp:    `''\'''\'''`,
+		},
+		{
+			name:   "windows empty",
+			target: "",
+			args:   []any{true},
+			exp:    `^"^"`,
+		},
+		{
+			name:   "windows trailing backslashes",
+			target: `C:\Program Files\`,
+			args:   []any{true},
+			exp:    `^"C:\Program Files\\^"`,
+		},
+		{
+			name:   "windows backslashes before quote",
+			target: `a\\"b`,
+			args:   []any{true},
+			exp:    `^"a\\\\\^"b^"`,
+		},
+		{
+			name:   "windows metacharacters",
+			target: `(a|b)<c>!d^`,
+			args:   []any{true},
+			exp:    `^"^(a^|b^)^<c^>^!d^^^"`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			fn, err := query.InitMethodHelper("shell_escape", query.NewLiteralFunction("", test.target), test.args...)
+			require.NoError(t, err)
+
+			res, err := fn.Exec(query.FunctionContext{})
+			require.NoError(t, err)
+			assert.Equal(t, test.exp, res)
+		})
+	}
+}
+
+func TestWordCountAndReadingTime(t *testing.T) {
+	testCases := []struct {
+		name   string
+		method string
+		target any
+		args   []any
+		exp    any
+	}{
+		{
+			name:   "empty",
+			method: "word_count",
+			target: "",
+			exp:    int64(0),
+		},
+		{
+			name:   "unicode",
+			method: "word_count",
+			target: "naïve café résumé 東京 123 … —",
+			exp:    int64(5),
+		},
+		{
+			name:   "reading time empty",
+			method: "reading_time",
+			target: "   ",
+			exp:    int64(0),
+		},
+		{
+			name:   "reading time exact",
+			method: "reading_time",
+			target: "a b c d",
+			args:   []any{int64(2)},
+			exp:    int64(2),
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			fn, err := query.InitMethodHelper(test.method, query.NewLiteralFunction("", test.target), test.args...)
+			require.NoError(t, err)
+
+			res, err := fn.Exec(query.FunctionContext{})
+			require.NoError(t, err)
+			assert.Equal(t, test.exp, res)
+		})
+	}
+
+	_, err := query.InitMethodHelper("reading_time", query.NewLiteralFunction("", "foo"), int64(0))
+	require.EqualError(t, err, "wpm must be greater than zero, got 0")
+}
+
+func TestProfanityMethods(t *testing.T) {
+	testCases := []struct {
+		name   string
+		method string
+		target any
+		args   []any
+		exp    any
+	}{
+		{
+			name:   "no match within words",
+			method: "contains_profanity",
+			target: "Scunthorpe classic assessment",
+			exp:    false,
+		},
+		{
+			name:   "match with punctuation",
+			method: "contains_profanity",
+			target: "oh...damn!",
+			exp:    true,
+		},
+		{
+			name:   "custom list escapes regexp syntax",
+			method: "contains_profanity",
+			target: "foo axb bar",
+			args:   []any{[]any{"a.b"}},
+			exp:    false,
+		},
+		{
+			name:   "mask multibyte",
+			method: "mask_profanity",
+			target: "Ärger und Mist",
+			args:   []any{[]any{"mist"}, "•"},
+			exp:    "Ärger und ••••",
+		},
+		{
+			name:   "mask multiple",
+			method: "mask_profanity",
+			target: "SHIT shit ShIt",
+			exp:    "**** **** ****",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			fn, err := query.InitMethodHelper(test.method, query.NewLiteralFunction("", test.target), test.args...)
+			require.NoError(t, err)
+
+			res, err := fn.Exec(query.FunctionContext{})
+			require.NoError(t, err)
+			assert.Equal(t, test.exp, res)
+		})
+	}
+
+	_, err := query.InitMethodHelper("contains_profanity", query.NewLiteralFunction("", "foo"), []any{})
+	require.EqualError(t, err, "wordlist must contain at least one word")
+}
+
+func TestTranslate(t *testing.T) {
+	testCases := []struct {
+		name   string
+		target any
+		args   []any
+		exp    any
+	}{
+		{
+			name:   "same length",
+			target: "hello world",
+			args:   []any{"lo", "01"},
+			exp:    "he001 w1r0d",
+		},
+		{
+			name:   "delete unmatched",
+			target: "a-b_c d",
+			args:   []any{"-_ ", "."},
+			exp:    "a.bcd",
+		},
+		{
+			name:   "first position wins",
+			target: "aaa",
+			args:   []any{"aa", "xy"},
+			exp:    "xxx",
+		},
+		{
+			name:   "unicode",
+			target: "naïve café",
+			args:   []any{"ïé", "ie"},
+			exp:    "naive cafe",
+		},
+		{
+			name:   "bytes",
+			target: []byte("abc"),
+			args:   []any{"abc", "ABC", true},
+			exp:    "ABC",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			fn, err := query.InitMethodHelper("translate", query.NewLiteralFunction("", test.target), test.args...)
+			require.NoError(t, err)
+
+			res, err := fn.Exec(query.FunctionContext{})
+			require.NoError(t, err)
+			assert.Equal(t, test.exp, res)
+		})
+	}
+
+	_, err := query.InitMethodHelper("translate", query.NewLiteralFunction("", "foo"), "abc", "a", true)
+	require.EqualError(t, err, "from and to must be the same length in strict mode, got 3 and 1 characters")
+}
+
+func TestSqueeze(t *testing.T) {
+	testCases := []struct {
+		name   string
+		target any
+		args   []any
+		exp    any
+	}{
+		{
+			name:   "default whitespace",
+			target: "a  \t\tb \t c",
+			exp:    "a \tb \t c",
+		},
+		{
+			name:   "custom chars",
+			target: "a,,;;b  c",
+			args:   []any{",;"},
+			exp:    "a,;b  c",
+		},
+		{
+			name:   "unicode",
+			target: "ééé--ü",
+			args:   []any{"é"},
+			exp:    "é--ü",
+		},
+		{
+			name:   "empty chars",
+			target: "aa  bb",
+			args:   []any{""},
+			exp:    "aa  bb",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			fn, err := query.InitMethodHelper("squeeze", query.NewLiteralFunction("", test.target), test.args...)
+			require.NoError(t, err)
+
+			res, err := fn.Exec(query.FunctionContext{})
+			require.NoError(t, err)
+			assert.Equal(t, test.exp, res)
+		})
+	}
+}
+
+func TestAlignment(t *testing.T) {
+	testCases := []struct {
+		name   string
+		method string
+		target any
+		args   []any
+		exp    any
+	}{
+		{
+			name:   "ljust default char",
+			method: "ljust",
+			target: "ab",
+			args:   []any{int64(5)},
+			exp:    "ab   ",
+		},
+		{
+			name:   "rjust unicode",
+			method: "rjust",
+			target: "café",
+			args:   []any{int64(6), "·"},
+			exp:    "··café",
+		},
+		{
+			name:   "center even",
+			method: "center",
+			target: "ab",
+			args:   []any{int64(6), "-"},
+			exp:    "--ab--",
+		},
+		{
+			name:   "center no truncation",
+			method: "center",
+			target: "abcdef",
+			args:   []any{int64(3)},
+			exp:    "abcdef",
+		},
+		{
+			name:   "negative width",
+			method: "ljust",
+			target: "abc",
+			args:   []any{int64(-1)},
+			exp:    "abc",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			fn, err := query.InitMethodHelper(test.method, query.NewLiteralFunction("", test.target), test.args...)
+			require.NoError(t, err)
+
+			res, err := fn.Exec(query.FunctionContext{})
+			require.NoError(t, err)
+			assert.Equal(t, test.exp, res)
+		})
+	}
+
+	_, err := query.InitMethodHelper("center", query.NewLiteralFunction("", "foo"), int64(5), "ab")
+	require.EqualError(t, err, `char must be a single character, got "ab"`)
+}
+
+func TestTitleCase(t *testing.T) {
+	testCases := []struct {
+		name   string
+		target any
+		args   []any
+		exp    any
+	}{
+		{
+			name:   "small word last",
+			target: "what are you looking at",
+			exp:    "What Are You Looking At",
+		},
+		{
+			name:   "mixed case small words",
+			target: "War AND Peace",
+			exp:    "War and Peace",
+		},
+		{
+			name:   "hyphens and apostrophes",
+			target: "the well-known story of a dog's life",
+			exp:    "The Well-Known Story of a Dog's Life",
+		},
+		{
+			name:   "whitespace preserved",
+			target: "  gone  with the\twind ",
+			exp:    "  Gone  With the\tWind ",
+		},
+		{
+			name:   "unicode",
+			target: "élan of the ßig",
+			exp:    "Élan of the ßig",
+		},
+		{
+			name:   "empty",
+			target: "",
+			exp:    "",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			fn, err := query.InitMethodHelper("title_case", query.NewLiteralFunction("", test.target), test.args...)
+			require.NoError(t, err)
+
+			res, err := fn.Exec(query.FunctionContext{})
+			require.NoError(t, err)
+			assert.Equal(t, test.exp, res)
+		})
+	}
+
+	_, err := query.InitMethodHelper("title_case", query.NewLiteralFunction("", "foo"), "nope")
+	require.EqualError(t, err, "unrecognised title case style: nope")
+
+	_, err = query.InitMethodHelper("title_case", query.NewLiteralFunction("", "foo"), "ap", "nope")
+	require.EqualError(t, err, "small_words: expected array value, got string (\"nope\")")
+}
+
+func TestUTF8Methods(t *testing.T) {
+	testCases := []struct {
+		name      string
+		target    any
+		valid     bool
+		sanitized string
+	}{
+		{
+			name:      "valid string",
+			target:    "héllo wörld",
+			valid:     true,
+			sanitized: "héllo wörld",
+		},
+		{
+			name:      "valid bytes",
+			target:    []byte("héllo"),
+			valid:     true,
+			sanitized: "héllo",
+		},
+		{
+			name:      "invalid byte",
+			target:    []byte("hel\xffo"),
+			sanitized: "hel\uFFFDo",
+		},
+		{
+			name:      "invalid run",
+			target:    []byte("\xff\xfehello\xc3"),
+			sanitized: "\uFFFDhello\uFFFD",
+		},
+		{
+			name:      "empty",
+			target:    "",
+			valid:     true,
+			sanitized: "",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			fn, err := query.InitMethodHelper("is_valid_utf8", query.NewLiteralFunction("", test.target))
+			require.NoError(t, err)
+
+			res, err := fn.Exec(query.FunctionContext{})
+			require.NoError(t, err)
+			assert.Equal(t, test.valid, res)
+
+			fn, err = query.InitMethodHelper("sanitize_utf8", query.NewLiteralFunction("", test.target))
+			require.NoError(t, err)
+
+			res, err = fn.Exec(query.FunctionContext{})
+			require.NoError(t, err)
+			assert.Equal(t, test.sanitized, res)
+		})
+	}
+
+	fn, err := query.InitMethodHelper("sanitize_utf8", query.NewLiteralFunction("", []byte("a\xffb")), "?")
+	require.NoError(t, err)
+
+	res, err := fn.Exec(query.FunctionContext{})
+	require.NoError(t, err)
+	assert.Equal(t, "a?b", res)
+}
+
+func TestByteAndRuneAt(t *testing.T) {
+	testCases := []struct {
+		name   string
+		method string
+		target any
+		index  int64
+		exp    any
+		err    string
+	}{
+		{name: "byte first", method: "byte_at", target: "hello", index: 0, exp: int64('h')},
+		{name: "byte last", method: "byte_at", target: "hello", index: -1, exp: int64('o')},
+		{name: "byte of multibyte", method: "byte_at", target: "é", index: 1, exp: int64(0xa9)},
+		{name: "byte of bytes", method: "byte_at", target: []byte{0x00, 0xff}, index: 1, exp: int64(255)},
+		{name: "byte out of bounds", method: "byte_at", target: "hello", index: 5, err: "index 5 is out of bounds for length 5"},
+		{name: "byte negative out of bounds", method: "byte_at", target: "hello", index: -6, err: "index -6 is out of bounds for length 5"},
+		{name: "byte empty", method: "byte_at", target: "", index: 0, err: "index 0 is out of bounds for length 0"},
+		{name: "rune first", method: "rune_at", target: "héllo", index: 1, exp: int64('é')},
+		{name: "rune last", method: "rune_at", target: "hé€", index: -1, exp: int64('€')},
+		{name: "rune out of bounds", method: "rune_at", target: "hé€", index: 3, err: "index 3 is out of bounds for length 3"},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			fn, err := query.InitMethodHelper(test.method, query.NewLiteralFunction("", test.target), test.index)
+			require.NoError(t, err)
+
+			res, err := fn.Exec(query.FunctionContext{})
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.exp, res)
+		})
+	}
+}
+
+func TestCharsAndBytesArray(t *testing.T) {
+	testCases := []struct {
+		name   string
+		method string
+		target any
+		exp    any
+	}{
+		{name: "chars ascii", method: "chars", target: "abc", exp: []any{"a", "b", "c"}},
+		{name: "chars multibyte", method: "chars", target: "a€😀", exp: []any{"a", "€", "😀"}},
+		{name: "chars empty", method: "chars", target: "", exp: []any{}},
+		{name: "chars bytes", method: "chars", target: []byte("hé"), exp: []any{"h", "é"}},
+		{name: "bytes array", method: "bytes_array", target: "a€", exp: []any{int64(97), int64(0xe2), int64(0x82), int64(0xac)}},
+		{name: "bytes array empty", method: "bytes_array", target: []byte{}, exp: []any{}},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			fn, err := query.InitMethodHelper(test.method, query.NewLiteralFunction("", test.target))
+			require.NoError(t, err)
+
+			res, err := fn.Exec(query.FunctionContext{})
+			require.NoError(t, err)
+			assert.Equal(t, test.exp, res)
+		})
+	}
+}
+
+func TestLineMethods(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mapping string
+		target  string
+		exp     string
+	}{
+		{name: "indent", mapping: `root = this.indent("  ")`, target: "a\nb", exp: "  a\n  b"},
+		{name: "indent final newline", mapping: `root = this.indent("> ")`, target: "a\nb\n", exp: "> a\n> b\n"},
+		{name: "indent skips blank", mapping: `root = this.indent("  ")`, target: "a\n\n \nb", exp: "  a\n\n \n  b"},
+		{name: "indent blank lines", mapping: `root = this.indent("  ", true)`, target: "a\n\nb", exp: "  a\n  \n  b"},
+		{name: "indent crlf", mapping: `root = this.indent("\t")`, target: "a\r\nb\r\n", exp: "\ta\r\n\tb\r\n"},
+		{name: "indent empty", mapping: `root = this.indent("  ")`, target: "", exp: ""},
+		{name: "dedent", mapping: `root = this.dedent()`, target: "    a\n      b\n    c", exp: "a\n  b\nc"},
+		{name: "dedent blank lines", mapping: `root = this.dedent()`, target: "  a\n\n \n    b\n", exp: "a\n\n\n  b\n"},
+		{name: "dedent crlf", mapping: `root = this.dedent()`, target: "\t\ta\r\n\tb\r\n", exp: "\ta\r\nb\r\n"},
+		{name: "dedent mixed whitespace", mapping: `root = this.dedent()`, target: "  a\n\tb", exp: "  a\n\tb"},
+		{name: "dedent no common", mapping: `root = this.dedent()`, target: "a\n  b", exp: "a\n  b"},
+		{name: "prefix lines", mapping: `root = this.prefix_lines("# ")`, target: "a\n\nb", exp: "# a\n# \n# b"},
+		{name: "prefix lines final newline", mapping: `root = this.prefix_lines("# ")`, target: "a\r\nb\r\n", exp: "# a\r\n# b\r\n"},
+		{name: "prefix lines empty", mapping: `root = this.prefix_lines("# ")`, target: "", exp: ""},
+		{name: "strip empty lines", mapping: `root = this.strip_empty_lines()`, target: "\na\n \n\tb", exp: "a\n\tb"},
+		{name: "strip empty lines final newline", mapping: `root = this.strip_empty_lines()`, target: "a\r\n\r\nb\r\n\r\n", exp: "a\r\nb\r\n"},
+		{name: "strip empty lines removed last", mapping: `root = this.strip_empty_lines()`, target: "a\n  ", exp: "a"},
+		{name: "strip empty lines all blank", mapping: `root = this.strip_empty_lines()`, target: "\n  \n", exp: ""},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			exec, err := bloblang.Parse(test.mapping)
+			require.NoError(t, err)
+
+			res, err := exec.Query(test.target)
+			require.NoError(t, err)
+			assert.Equal(t, test.exp, res)
+		})
+	}
+}
+
+func TestParseKV(t *testing.T) {
+	testCases := []struct {
+		name        string
+		mapping     string
+		target      string
+		exp         any
+		errContains string
+	}{
+		{
+			name:    "defaults",
+			mapping: `root = this.parse_kv()`,
+			target:  `a=1  b="foo bar" c flag d=`,
+			exp:     map[string]any{"a": "1", "b": "foo bar", "c": true, "flag": true, "d": ""},
+		},
+		{
+			name:    "escaped quotes",
+			mapping: `root = this.parse_kv()`,
+			target:  `msg="say \"hi there\"" x=y`,
+			exp:     map[string]any{"msg": `say "hi there"`, "x": "y"},
+		},
+		{
+			name:    "quotes disabled",
+			mapping: `root = this.parse_kv(quotes: false)`,
+			target:  `msg="foo bar"`,
+			exp:     map[string]any{"msg": `"foo`, `bar"`: true},
+		},
+		{
+			name:    "cast",
+			mapping: `root = this.parse_kv(cast: true)`,
+			target:  `a=1 b=-2.5 c=true d=false e=nan f="10" g=yes`,
+			exp:     map[string]any{"a": int64(1), "b": -2.5, "c": true, "d": false, "e": "nan", "f": "10", "g": "yes"},
+		},
+		{
+			name:    "custom separators",
+			mapping: `root = this.parse_kv(field_sep: "&&", pair_sep: "=>")`,
+			target:  `a => 1&&b=>x=>y&&&&c=>"&&"`,
+			exp:     map[string]any{"a": "1", "b": "x=>y", "c": "&&"},
+		},
+		{
+			name:    "no trim",
+			mapping: `root = this.parse_kv(field_sep: ",", trim: false)`,
+			target:  `a = 1, b=2`,
+			exp:     map[string]any{"a ": " 1", " b": "2"},
+		},
+		{
+			name:    "last wins",
+			mapping: `root = this.parse_kv()`,
+			target:  `a=1 a=2`,
+			exp:     map[string]any{"a": "2"},
+		},
+		{
+			name:        "bad quoted value",
+			mapping:     `root = this.parse_kv()`,
+			target:      `a="foo\x"`,
+			errContains: "key a: failed to unquote value",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			exec, err := bloblang.Parse(test.mapping)
+			require.NoError(t, err)
+
+			res, err := exec.Query(test.target)
+			if test.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.exp, res)
+		})
+	}
+
+	_, err := bloblang.Parse(`root = this.parse_kv(field_sep: "")`)
+	require.Error(t, err)
+}
+func TestLogfmt(t *testing.T) {
+	testCases := []struct {
+		name        string
+		mapping     string
+		target      any
+		exp         any
+		errContains string
+	}{
+		{
+			name:    "parse",
+			mapping: `root = this.parse_logfmt()`,
+			target:  "a=1\tb=\"foo bar\"  flag c= d=\"tab\\there\" e=\"\"",
+			exp:     map[string]any{"a": "1", "b": "foo bar", "flag": true, "c": "", "d": "tab\there", "e": ""},
+		},
+		{
+			name:    "parse unicode and equals",
+			mapping: `root = this.parse_logfmt()`,
+			target:  `msg="a=b" name=zoë`,
+			exp:     map[string]any{"msg": "a=b", "name": "zoë"},
+		},
+		{
+			name:        "parse unterminated quote",
+			mapping:     `root = this.parse_logfmt()`,
+			target:      `a="foo bar`,
+			errContains: "key a: unterminated quoted value",
+		},
+		{
+			name:        "parse quote in key",
+			mapping:     `root = this.parse_logfmt()`,
+			target:      `"a"=b`,
+			errContains: "unexpected quote in key at offset 0",
+		},
+		{
+			name:        "parse missing key",
+			mapping:     `root = this.parse_logfmt()`,
+			target:      `a=b =c`,
+			errContains: "missing key at offset 4",
+		},
+		{
+			name:        "parse trailing characters after quote",
+			mapping:     `root = this.parse_logfmt()`,
+			target:      `a="b"c`,
+			errContains: "key a: unexpected character after quoted value at offset 5",
+		},
+		{
+			name:        "parse quote in unquoted value",
+			mapping:     `root = this.parse_logfmt()`,
+			target:      `a=b"c`,
+			errContains: "key a: unexpected quote in unquoted value at offset 3",
+		},
+		{
+			name:    "format",
+			mapping: `root = this.format_logfmt().string()`,
+			target: map[string]any{
+				"b": "foo bar", "a": int64(1), "c": nil, "d": "", "e": map[string]any{"f": "g"},
+				"h": "x=y", "i": "back\\slash", "j": "new\nline",
+			},
+			exp: `a=1 b="foo bar" c=null d= e="{\"f\":\"g\"}" h="x=y" i="back\\slash" j="new\nline"`,
+		},
+		{
+			name:        "format bad key",
+			mapping:     `root = this.format_logfmt()`,
+			target:      map[string]any{"a b": "c"},
+			errContains: `key "a b" cannot be represented in logfmt`,
+		},
+		{
+			name:    "round trip",
+			mapping: `root = this.format_logfmt().parse_logfmt()`,
+			target:  map[string]any{"a": "foo \"bar\" baz", "b": "zoë", "c": "", "d": "\t\\"},
+			exp:     map[string]any{"a": "foo \"bar\" baz", "b": "zoë", "c": "", "d": "\t\\"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			exec, err := bloblang.Parse(test.mapping)
+			require.NoError(t, err)
+
+			res, err := exec.Query(test.target)
+			if test.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.exp, res)
+		})
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2025 Redpanda Data, Inc.
+
+package pure
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+
+	"github.com/redpanda-data/benthos/v4/internal/bloblang/query"
+	"github.com/redpanda-data/benthos/v4/public/bloblang"
+)
+
+func totpOpts(skew int64) totp.ValidateOpts {
+	return totp.ValidateOpts{
+		Period:    30,
+		Skew:      uint(skew),
+		Digits:    otp.DigitsSix,
+		Algorithm: otp.AlgorithmSHA1,
+	}
+}
+
+func init() {
+	if err := bloblang.RegisterFunctionV2("totp_now",
+		bloblang.NewPluginSpec().
+			Category(query.FunctionCategoryGeneral).
+			Description("Generates the current https://datatracker.ietf.org/doc/html/rfc6238[TOTP^] code for a base32 encoded secret, using the parameters common to most authenticator apps (a 30 second period, six digits and the SHA1 algorithm). The code is returned as a string in order to preserve leading zeros.").
+			Param(bloblang.NewStringParam("secret").Description("A base32 encoded secret.")).
+			ExampleNotTested("", `root.code = totp_now(env("TOTP_SECRET"))`,
+				[2]string{
+					`{}`,
+					`{"code":"482913"}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Function, error) {
+			secret, err := args.GetString("secret")
+			if err != nil {
+				return nil, err
+			}
+			return func() (any, error) {
+				code, err := totp.GenerateCodeCustom(secret, time.Now(), totpOpts(0))
+				if err != nil {
+					return nil, fmt.Errorf("failed to generate totp code: %w", err)
+				}
+				return code, nil
+			}, nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterFunctionV2("totp_validate",
+		bloblang.NewPluginSpec().
+			Category(query.FunctionCategoryGeneral).
+			Description("Checks whether a code is a valid https://datatracker.ietf.org/doc/html/rfc6238[TOTP^] code for a base32 encoded secret at the current time, returning a boolean. The parameters common to most authenticator apps are used (a 30 second period, six digits and the SHA1 algorithm).").
+			Param(bloblang.NewStringParam("secret").Description("A base32 encoded secret.")).
+			Param(bloblang.NewStringParam("code").Description("The code to validate.")).
+			Param(bloblang.NewInt64Param("window").Description("The number of periods before and after the current period for which codes are also accepted, allowing for clock skew between systems.").Default(1)).
+			ExampleNotTested("", `root.authenticated = totp_validate(env("TOTP_SECRET"), this.code)`,
+				[2]string{
+					`{"code":"482913"}`,
+					`{"authenticated":true}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Function, error) {
+			secret, err := args.GetString("secret")
+			if err != nil {
+				return nil, err
+			}
+			code, err := args.GetString("code")
+			if err != nil {
+				return nil, err
+			}
+			window, err := args.GetInt64("window")
+			if err != nil {
+				return nil, err
+			}
+			if window < 0 {
+				return nil, fmt.Errorf("window must not be negative, got %v", window)
+			}
+			return func() (any, error) {
+				valid, err := totp.ValidateCustom(code, secret, time.Now().UTC(), totpOpts(window))
+				if err != nil && !errors.Is(err, otp.ErrValidateInputInvalidLength) {
+					return nil, fmt.Errorf("failed to validate totp code: %w", err)
+				}
+				return valid, nil
+			}, nil
+		}); err != nil {
+		panic(err)
+	}
+}
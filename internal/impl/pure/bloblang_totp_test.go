@@ -0,0 +1,56 @@
+// Copyright 2025 Redpanda Data, Inc.
+
+package pure
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/redpanda-data/benthos/v4/public/bloblang"
+)
+
+func TestTOTPFunctions(t *testing.T) {
+	secret := "JBSWY3DPEHPK3PXP"
+
+	exec, err := bloblang.Parse(`root.code = totp_now(this.secret)
+root.valid = totp_validate(this.secret, root.code)
+root.wrong_length = totp_validate(this.secret, "12345")`)
+	require.NoError(t, err)
+
+	res, err := exec.Query(map[string]any{"secret": secret})
+	require.NoError(t, err)
+
+	resMap := res.(map[string]any)
+	code := resMap["code"].(string)
+	assert.Len(t, code, 6)
+	assert.Equal(t, true, resMap["valid"])
+	assert.Equal(t, false, resMap["wrong_length"])
+
+	// Codes from a different period are accepted only within the window.
+	oldCode, err := totp.GenerateCodeCustom(secret, time.Now().Add(-5*time.Minute), totpOpts(0))
+	require.NoError(t, err)
+
+	exec, err = bloblang.Parse(`root = [ totp_validate(this.secret, this.code, 0), totp_validate(this.secret, this.code, 20) ]`)
+	require.NoError(t, err)
+
+	res, err = exec.Query(map[string]any{"secret": secret, "code": oldCode})
+	require.NoError(t, err)
+	assert.Equal(t, []any{false, true}, res)
+}
+
+func TestTOTPErrors(t *testing.T) {
+	exec, err := bloblang.Parse(`root = totp_now("not base32!")`)
+	require.NoError(t, err)
+
+	_, err = exec.Query(nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to generate totp code")
+
+	_, err = bloblang.Parse(`root = totp_validate("JBSWY3DPEHPK3PXP", "123456", -1)`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "window must not be negative, got -1")
+}
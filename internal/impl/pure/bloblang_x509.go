@@ -0,0 +1,187 @@
+// Copyright 2025 Redpanda Data, Inc.
+
+package pure
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/redpanda-data/benthos/v4/internal/bloblang/query"
+	"github.com/redpanda-data/benthos/v4/public/bloblang"
+)
+
+// certificateDER returns the DER bytes of a certificate that is either PEM
+// encoded, in which case the first CERTIFICATE block is used, or already DER.
+func certificateDER(b []byte) ([]byte, error) {
+	if !bytes.Contains(b, []byte("-----BEGIN")) {
+		return b, nil
+	}
+	for {
+		var block *pem.Block
+		if block, b = pem.Decode(b); block == nil {
+			return nil, errors.New("no CERTIFICATE block found within PEM data")
+		}
+		if block.Type == "CERTIFICATE" {
+			return block.Bytes, nil
+		}
+	}
+}
+
+func colonHex(b []byte) string {
+	var sb strings.Builder
+	for i, c := range b {
+		if i > 0 {
+			sb.WriteByte(':')
+		}
+		fmt.Fprintf(&sb, "%02X", c)
+	}
+	return sb.String()
+}
+
+func certificateToMap(cert *x509.Certificate) map[string]any {
+	san := []any{}
+	for _, n := range cert.DNSNames {
+		san = append(san, n)
+	}
+	for _, ip := range cert.IPAddresses {
+		san = append(san, ip.String())
+	}
+	for _, e := range cert.EmailAddresses {
+		san = append(san, e)
+	}
+	for _, u := range cert.URIs {
+		san = append(san, u.String())
+	}
+	return map[string]any{
+		"subject":    cert.Subject.String(),
+		"issuer":     cert.Issuer.String(),
+		"not_before": cert.NotBefore,
+		"not_after":  cert.NotAfter,
+		"san":        san,
+		"serial":     colonHex(cert.SerialNumber.Bytes()),
+	}
+}
+
+func init() {
+	if err := bloblang.RegisterMethodV2("pem_decode",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryEncoding).
+			Description(`Decodes all PEM blocks within a string or byte array into an array of objects containing the fields `+"`type`"+` (such as `+"`CERTIFICATE`"+`), `+"`headers`"+` (an object of any headers of the block) and `+"`bytes`"+` (the decoded contents of the block). Any data outside of PEM blocks is ignored, and an error is returned if no PEM blocks are found.`).
+			Example("", `root = this.data.pem_decode().map_each(block -> {"type": block.type, "headers": block.headers, "bytes": block.bytes.string()})`,
+				[2]string{
+					`{"data":"-----BEGIN MESSAGE-----\nProc-Type: 4,TEST\n\naGVsbG8gd29ybGQ=\n-----END MESSAGE-----\n"}`,
+					`[{"bytes":"hello world","headers":{"Proc-Type":"4,TEST"},"type":"MESSAGE"}]`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return bloblang.BytesMethod(func(b []byte) (any, error) {
+				blocks := []any{}
+				for {
+					var block *pem.Block
+					if block, b = pem.Decode(b); block == nil {
+						break
+					}
+					headers := make(map[string]any, len(block.Headers))
+					for k, v := range block.Headers {
+						headers[k] = v
+					}
+					blocks = append(blocks, map[string]any{
+						"type":    block.Type,
+						"headers": headers,
+						"bytes":   block.Bytes,
+					})
+				}
+				if len(blocks) == 0 {
+					return nil, errors.New("no PEM data found")
+				}
+				return blocks, nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("parse_certificate",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryParsing).
+			Description(`Parses an X.509 certificate, either PEM encoded (in which case the first `+"`CERTIFICATE`"+` block is used) or as raw DER bytes, into an object containing the fields `+"`subject`"+` and `+"`issuer`"+` (distinguished names in RFC 2253 form), `+"`not_before`"+` and `+"`not_after`"+` (timestamps), `+"`san`"+` (an array of all subject alternative names, including DNS names, IP addresses, email addresses and URIs) and `+"`serial`"+` (a colon separated hex string).`).
+			Example("", `root = this.cert.parse_certificate()`,
+				[2]string{
+					`{"cert":"-----BEGIN CERTIFICATE-----\nMIIBMDCB46ADAgECAgIQADAFBgMrZXAwKDEQMA4GA1UEChMHRXhhbXBsZTEUMBIG\nA1UEAxMLZXhhbXBsZS5jb20wHhcNMjUwMTAxMDAwMDAwWhcNMzUwMTAxMDAwMDAw\nWjAoMRAwDgYDVQQKEwdFeGFtcGxlMRQwEgYDVQQDEwtleGFtcGxlLmNvbTAqMAUG\nAytlcAMhAAOhB7/zzhC+HXDdGOdLwJln5NYwm6UNXx3chmQSVTG4ozEwLzAtBgNV\nHREEJjAkggtleGFtcGxlLmNvbYIPd3d3LmV4YW1wbGUuY29thwTAAAIBMAUGAytl\ncANBAIcOznOT1nLXZnkJ4/7YRb+y08KE5OpRbk3UvNjmxBANn79VjWZ5aNtiWnnB\nS3NhH0yfM000UEsycnVARQd73w8=\n-----END CERTIFICATE-----\n"}`,
+					`{"issuer":"CN=example.com,O=Example","not_after":"2035-01-01T00:00:00Z","not_before":"2025-01-01T00:00:00Z","san":["example.com","www.example.com","192.0.2.1"],"serial":"10:00","subject":"CN=example.com,O=Example"}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			return bloblang.BytesMethod(func(b []byte) (any, error) {
+				der, err := certificateDER(b)
+				if err != nil {
+					return nil, err
+				}
+				cert, err := x509.ParseCertificate(der)
+				if err != nil {
+					return nil, fmt.Errorf("failed to parse certificate: %w", err)
+				}
+				return certificateToMap(cert), nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+
+	if err := bloblang.RegisterMethodV2("x509_fingerprint",
+		bloblang.NewPluginSpec().
+			Category(query.MethodCategoryEncoding).
+			Description(`Computes the fingerprint of an X.509 certificate, either PEM encoded (in which case the first `+"`CERTIFICATE`"+` block is used) or as raw DER bytes, and returns it as an uppercase colon separated hex string, which is the form commonly displayed by tools such as OpenSSL. The fingerprint is a hash of the DER encoding of the certificate.`).
+			Param(bloblang.NewStringParam("algorithm").Description("The hash algorithm to use, one of `sha256` or `sha1`.").Default("sha256")).
+			Example("", `root.fingerprint = this.cert.x509_fingerprint()`,
+				[2]string{
+					`{"cert":"-----BEGIN CERTIFICATE-----\nMIIBMDCB46ADAgECAgIQADAFBgMrZXAwKDEQMA4GA1UEChMHRXhhbXBsZTEUMBIG\nA1UEAxMLZXhhbXBsZS5jb20wHhcNMjUwMTAxMDAwMDAwWhcNMzUwMTAxMDAwMDAw\nWjAoMRAwDgYDVQQKEwdFeGFtcGxlMRQwEgYDVQQDEwtleGFtcGxlLmNvbTAqMAUG\nAytlcAMhAAOhB7/zzhC+HXDdGOdLwJln5NYwm6UNXx3chmQSVTG4ozEwLzAtBgNV\nHREEJjAkggtleGFtcGxlLmNvbYIPd3d3LmV4YW1wbGUuY29thwTAAAIBMAUGAytl\ncANBAIcOznOT1nLXZnkJ4/7YRb+y08KE5OpRbk3UvNjmxBANn79VjWZ5aNtiWnnB\nS3NhH0yfM000UEsycnVARQd73w8=\n-----END CERTIFICATE-----\n"}`,
+					`{"fingerprint":"91:95:BD:06:8B:05:1C:16:55:41:87:47:08:D3:B7:4F:5F:2E:22:73:AB:85:D6:71:C4:45:6B:DB:28:67:10:1C"}`,
+				},
+			).
+			Example("", `root.fingerprint = this.cert.x509_fingerprint("sha1")`,
+				[2]string{
+					`{"cert":"-----BEGIN CERTIFICATE-----\nMIIBMDCB46ADAgECAgIQADAFBgMrZXAwKDEQMA4GA1UEChMHRXhhbXBsZTEUMBIG\nA1UEAxMLZXhhbXBsZS5jb20wHhcNMjUwMTAxMDAwMDAwWhcNMzUwMTAxMDAwMDAw\nWjAoMRAwDgYDVQQKEwdFeGFtcGxlMRQwEgYDVQQDEwtleGFtcGxlLmNvbTAqMAUG\nAytlcAMhAAOhB7/zzhC+HXDdGOdLwJln5NYwm6UNXx3chmQSVTG4ozEwLzAtBgNV\nHREEJjAkggtleGFtcGxlLmNvbYIPd3d3LmV4YW1wbGUuY29thwTAAAIBMAUGAytl\ncANBAIcOznOT1nLXZnkJ4/7YRb+y08KE5OpRbk3UvNjmxBANn79VjWZ5aNtiWnnB\nS3NhH0yfM000UEsycnVARQd73w8=\n-----END CERTIFICATE-----\n"}`,
+					`{"fingerprint":"74:F2:AF:22:A9:18:AD:AB:C7:5E:6D:15:34:F9:9B:9A:4A:B0:DD:E0"}`,
+				},
+			),
+		func(args *bloblang.ParsedParams) (bloblang.Method, error) {
+			algStr, err := args.GetString("algorithm")
+			if err != nil {
+				return nil, err
+			}
+			var hashFn func([]byte) []byte
+			switch algStr {
+			case "sha256":
+				hashFn = func(b []byte) []byte {
+					sum := sha256.Sum256(b)
+					return sum[:]
+				}
+			case "sha1":
+				hashFn = func(b []byte) []byte {
+					sum := sha1.Sum(b)
+					return sum[:]
+				}
+			default:
+				return nil, fmt.Errorf("unrecognised fingerprint algorithm: %v", algStr)
+			}
+			return bloblang.BytesMethod(func(b []byte) (any, error) {
+				der, err := certificateDER(b)
+				if err != nil {
+					return nil, err
+				}
+				// Parse the certificate in order to reject data that isn't a
+				// certificate rather than silently hashing it.
+				if _, err := x509.ParseCertificate(der); err != nil {
+					return nil, fmt.Errorf("failed to parse certificate: %w", err)
+				}
+				return colonHex(hashFn(der)), nil
+			}), nil
+		}); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2025 Redpanda Data, Inc.
+
+package pure
+
+import (
+	"encoding/pem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/redpanda-data/benthos/v4/public/bloblang"
+)
+
+const testCertPEM = `-----BEGIN CERTIFICATE-----
+MIIBMDCB46ADAgECAgIQADAFBgMrZXAwKDEQMA4GA1UEChMHRXhhbXBsZTEUMBIG
+A1UEAxMLZXhhbXBsZS5jb20wHhcNMjUwMTAxMDAwMDAwWhcNMzUwMTAxMDAwMDAw
+WjAoMRAwDgYDVQQKEwdFeGFtcGxlMRQwEgYDVQQDEwtleGFtcGxlLmNvbTAqMAUG
+AytlcAMhAAOhB7/zzhC+HXDdGOdLwJln5NYwm6UNXx3chmQSVTG4ozEwLzAtBgNV
+HREEJjAkggtleGFtcGxlLmNvbYIPd3d3LmV4YW1wbGUuY29thwTAAAIBMAUGAytl
+cANBAIcOznOT1nLXZnkJ4/7YRb+y08KE5OpRbk3UvNjmxBANn79VjWZ5aNtiWnnB
+S3NhH0yfM000UEsycnVARQd73w8=
+-----END CERTIFICATE-----
+`
+
+func TestParseCertificate(t *testing.T) {
+	block, _ := pem.Decode([]byte(testCertPEM))
+	require.NotNil(t, block)
+
+	exec, err := bloblang.Parse(`root = this.parse_certificate().without("not_before","not_after")`)
+	require.NoError(t, err)
+
+	exp := map[string]any{
+		"subject": "CN=example.com,O=Example",
+		"issuer":  "CN=example.com,O=Example",
+		"san":     []any{"example.com", "www.example.com", "192.0.2.1"},
+		"serial":  "10:00",
+	}
+
+	for name, input := range map[string]any{
+		"pem":                testCertPEM,
+		"der":                block.Bytes,
+		"pem with key first": "-----BEGIN PUBLIC KEY-----\naGVsbG8=\n-----END PUBLIC KEY-----\n" + testCertPEM,
+	} {
+		t.Run(name, func(t *testing.T) {
+			res, err := exec.Query(input)
+			require.NoError(t, err)
+			assert.Equal(t, exp, res)
+		})
+	}
+
+	_, err = exec.Query("-----BEGIN PUBLIC KEY-----\naGVsbG8=\n-----END PUBLIC KEY-----\n")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no CERTIFICATE block found within PEM data")
+
+	_, err = exec.Query([]byte("not a cert"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse certificate")
+}
+
+func TestPEMDecode(t *testing.T) {
+	exec, err := bloblang.Parse(`root = this.pem_decode().map_each(b -> b.type)`)
+	require.NoError(t, err)
+
+	res, err := exec.Query("garbage\n" + testCertPEM + "more garbage\n" + testCertPEM)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"CERTIFICATE", "CERTIFICATE"}, res)
+
+	_, err = exec.Query("no pem here")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no PEM data found")
+}
+
+func TestX509Fingerprint(t *testing.T) {
+	block, _ := pem.Decode([]byte(testCertPEM))
+	require.NotNil(t, block)
+
+	exec, err := bloblang.Parse(`root = this.x509_fingerprint()`)
+	require.NoError(t, err)
+
+	res, err := exec.Query(block.Bytes)
+	require.NoError(t, err)
+	assert.Equal(t, "91:95:BD:06:8B:05:1C:16:55:41:87:47:08:D3:B7:4F:5F:2E:22:73:AB:85:D6:71:C4:45:6B:DB:28:67:10:1C", res)
+
+	_, err = exec.Query("not a cert")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse certificate")
+
+	_, err = bloblang.Parse(`root = this.x509_fingerprint("md5")`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unrecognised fingerprint algorithm: md5")
+}
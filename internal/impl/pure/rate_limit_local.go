@@ -15,6 +15,7 @@ func localRatelimitConfig() *service.ConfigSpec {
 	spec := service.NewConfigSpec().
 		Stable().
 		Summary(`The local rate limit is a simple X every Y type rate limit that can be shared across any number of components within the pipeline but does not support distributed rate limits across multiple running instances of Benthos.`).
+		Description(`When accessed with a key, such as with the ` + "`rate_limit_check`" + ` bloblang function, each key is given its own budget of ` + "`count`" + ` requests per ` + "`interval`" + `, independent of other keys and of accesses without a key. Keys are forgotten once they have not been accessed for an interval.`).
 		Field(service.NewIntField("count").
 			Description("The maximum number of requests to allow for a given period of time.").
 			Default(1000)).
@@ -50,10 +51,37 @@ func newLocalRatelimitFromConfig(conf *service.ParsedConfig) (*localRatelimit, e
 
 //------------------------------------------------------------------------------
 
-type localRatelimit struct {
-	mut         sync.Mutex
-	bucket      int
+type localBucket struct {
+	remaining   int
 	lastRefresh time.Time
+}
+
+// access consumes from the bucket, returning the duration to wait until the
+// bucket is refreshed when it is empty.
+func (b *localBucket) access(size int, period time.Duration) time.Duration {
+	b.remaining--
+
+	if b.remaining < 0 {
+		b.remaining = 0
+		remaining := period - time.Since(b.lastRefresh)
+
+		if remaining > 0 {
+			return remaining
+		}
+		b.remaining = size - 1
+		b.lastRefresh = time.Now()
+	}
+	return 0
+}
+
+type localRatelimit struct {
+	mut    sync.Mutex
+	bucket localBucket
+
+	// Buckets of keyed accesses, which are created on demand and forgotten
+	// once they have been idle for a full period.
+	keyed     map[string]*localBucket
+	lastSweep time.Time
 
 	size   int
 	period time.Duration
@@ -64,30 +92,44 @@ func newLocalRatelimit(count int, interval time.Duration) (*localRatelimit, erro
 		return nil, errors.New("count must be larger than zero")
 	}
 	return &localRatelimit{
-		bucket:      count,
-		lastRefresh: time.Now(),
-		size:        count,
-		period:      interval,
+		bucket: localBucket{
+			remaining:   count,
+			lastRefresh: time.Now(),
+		},
+		keyed:     map[string]*localBucket{},
+		lastSweep: time.Now(),
+		size:      count,
+		period:    interval,
 	}, nil
 }
 
 func (r *localRatelimit) Access(ctx context.Context) (time.Duration, error) {
 	r.mut.Lock()
-	r.bucket--
+	defer r.mut.Unlock()
+	return r.bucket.access(r.size, r.period), nil
+}
 
-	if r.bucket < 0 {
-		r.bucket = 0
-		remaining := r.period - time.Since(r.lastRefresh)
+// AccessKey consumes from a budget of count per interval that is independent
+// for each key.
+func (r *localRatelimit) AccessKey(ctx context.Context, key string) (time.Duration, error) {
+	r.mut.Lock()
+	defer r.mut.Unlock()
 
-		if remaining > 0 {
-			r.mut.Unlock()
-			return remaining, nil
+	if time.Since(r.lastSweep) >= r.period {
+		for k, b := range r.keyed {
+			if time.Since(b.lastRefresh) >= r.period {
+				delete(r.keyed, k)
+			}
 		}
-		r.bucket = r.size - 1
-		r.lastRefresh = time.Now()
+		r.lastSweep = time.Now()
+	}
+
+	b, exists := r.keyed[key]
+	if !exists {
+		b = &localBucket{remaining: r.size, lastRefresh: time.Now()}
+		r.keyed[key] = b
 	}
-	r.mut.Unlock()
-	return 0, nil
+	return b.access(r.size, r.period), nil
 }
 
 func (r *localRatelimit) Close(ctx context.Context) error {
@@ -140,3 +140,40 @@ interval: 1ns
 	close(startChan)
 	wg.Wait()
 }
+
+func TestLocalRateLimitKeyed(t *testing.T) {
+	rl, err := newLocalRatelimit(2, time.Millisecond*50)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	for _, key := range []string{"foo", "bar"} {
+		for i := 0; i < 2; i++ {
+			period, err := rl.AccessKey(ctx, key)
+			require.NoError(t, err)
+			assert.Equal(t, time.Duration(0), period, key)
+		}
+		period, err := rl.AccessKey(ctx, key)
+		require.NoError(t, err)
+		assert.Greater(t, period, time.Duration(0), key)
+	}
+
+	// Keyed accesses do not consume from the unkeyed budget.
+	period, err := rl.Access(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(0), period)
+
+	<-time.After(time.Millisecond * 60)
+
+	period, err = rl.AccessKey(ctx, "baz")
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(0), period)
+
+	rl.mut.Lock()
+	assert.Len(t, rl.keyed, 1, "idle keys should have been forgotten")
+	rl.mut.Unlock()
+
+	period, err = rl.AccessKey(ctx, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(0), period)
+}
@@ -66,6 +66,23 @@ func (l *liveResources[T]) Probe(name string) bool {
 	return exists
 }
 
+// Counts returns the number of resource names that are known, and the number
+// of those that currently have an underlying resource. Neither the resources
+// nor their locks are held beyond the call and therefore the counts are only a
+// snapshot.
+func (l *liveResources[T]) Counts() (c ResourceCounts) {
+	l.m.RLock()
+	defer l.m.RUnlock()
+
+	c.Registered = len(l.resources)
+	for _, v := range l.resources {
+		if v.RAccess(func(T) {}) {
+			c.Initialized++
+		}
+	}
+	return
+}
+
 // Add a resource with a given name.
 func (l *liveResources[T]) Add(name string, t *T) {
 	l.m.Lock()
@@ -0,0 +1,28 @@
+// Copyright 2025 Redpanda Data, Inc.
+
+package manager
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLiveResourcesCounts(t *testing.T) {
+	l := newLiveResources[string]()
+
+	assert.Equal(t, ResourceCounts{}, l.Counts())
+
+	foo := "foo"
+	l.Add("foo", &foo)
+	l.Add("bar", nil)
+
+	assert.Equal(t, ResourceCounts{Registered: 2, Initialized: 1}, l.Counts())
+
+	bar := "bar"
+	assert.NoError(t, l.Access("bar", false, func(_ *string, set func(*string)) {
+		set(&bar)
+	}))
+
+	assert.Equal(t, ResourceCounts{Registered: 2, Initialized: 2}, l.Counts())
+}
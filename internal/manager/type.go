@@ -4,10 +4,13 @@ package manager
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"path"
+	"sort"
 	"sync"
+	"time"
 
 	"go.opentelemetry.io/otel/trace"
 	"go.opentelemetry.io/otel/trace/noop"
@@ -472,7 +475,80 @@ func (t *Type) Environment() *bundle.Environment {
 // BloblEnvironment returns a Bloblang environment used by the manager. This is
 // for internal use only.
 func (t *Type) BloblEnvironment() *bloblang.Environment {
-	return t.bloblEnv
+	return t.bloblEnv.WithResources(bloblResources{t: t})
+}
+
+// bloblResources exposes the resources of a manager to the functions and
+// methods of mappings parsed from its Bloblang environment.
+type bloblResources struct {
+	t *Type
+}
+
+func (b bloblResources) AccessRateLimit(ctx context.Context, name string) (wait time.Duration, err error) {
+	if aerr := b.t.AccessRateLimit(ctx, name, func(rl ratelimit.V1) {
+		wait, err = rl.Access(ctx)
+	}); aerr != nil {
+		err = aerr
+	}
+	return
+}
+
+func (b bloblResources) AccessRateLimitKey(ctx context.Context, name, key string) (wait time.Duration, err error) {
+	if aerr := b.t.AccessRateLimit(ctx, name, func(rl ratelimit.V1) {
+		if wait, err = ratelimit.AccessKey(ctx, rl, key); errors.Is(err, ratelimit.ErrKeysNotSupported) {
+			err = fmt.Errorf("rate limit resource %v: %w", name, err)
+		}
+	}); aerr != nil {
+		err = aerr
+	}
+	return
+}
+
+func (b bloblResources) StreamID() string {
+	return b.t.stream
+}
+
+func (b bloblResources) Log(level, message string, keyValues ...any) {
+	l := b.t.logger
+	if len(keyValues) > 0 {
+		l = l.With(keyValues...)
+	}
+	switch level {
+	case "TRACE":
+		l.Trace("%v", message)
+	case "DEBUG":
+		l.Debug("%v", message)
+	case "INFO":
+		l.Info("%v", message)
+	case "WARN":
+		l.Warn("%v", message)
+	case "ERROR":
+		l.Error("%v", message)
+	}
+}
+
+// sortedLabels returns the names and values of a set of labels sorted by name.
+func sortedLabels(labels map[string]string) (names, values []string) {
+	names = make([]string, 0, len(labels))
+	for k := range labels {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	values = make([]string, len(names))
+	for i, k := range names {
+		values[i] = labels[k]
+	}
+	return
+}
+
+func (b bloblResources) IncrCounter(name string, count float64, labels map[string]string) {
+	names, values := sortedLabels(labels)
+	b.t.stats.GetCounterVec(name, names...).With(values...).IncrFloat64(count)
+}
+
+func (b bloblResources) SetGauge(name string, value float64, labels map[string]string) {
+	names, values := sortedLabels(labels)
+	b.t.stats.GetGaugeVec(name, names...).With(values...).SetFloat64(value)
 }
 
 //------------------------------------------------------------------------------
@@ -492,6 +568,37 @@ func (t *Type) NewBuffer(conf buffer.Config) (buffer.Streamed, error) {
 
 //------------------------------------------------------------------------------
 
+// ResourceCounts describes the number of resources of a kind that are known to
+// a manager, and how many of those have been initialized.
+type ResourceCounts struct {
+	// Registered is the number of resource names that are known, which is the
+	// set of names for which a probe returns true.
+	Registered int
+
+	// Initialized is the number of registered resources that have been
+	// successfully constructed and are available for access.
+	Initialized int
+}
+
+// ResourceCounts returns, for each kind of resource (input, cache, processor,
+// output and rate limit), the number of resources that are registered versus
+// those that have been initialized. A resource is registered before it is
+// initialized, and therefore a resource that exists (probes return true) but is
+// not initialized is either still under construction or failed to construct.
+//
+// Obtaining the counts does not force the initialization of any resource.
+func (t *Type) ResourceCounts() map[docs.Type]ResourceCounts {
+	return map[docs.Type]ResourceCounts{
+		docs.TypeInput:     t.inputs.Counts(),
+		docs.TypeCache:     t.caches.Counts(),
+		docs.TypeProcessor: t.processors.Counts(),
+		docs.TypeOutput:    t.outputs.Counts(),
+		docs.TypeRateLimit: t.rateLimits.Counts(),
+	}
+}
+
+//------------------------------------------------------------------------------
+
 // ProbeCache returns true if a cache resource exists under the provided name.
 func (t *Type) ProbeCache(name string) bool {
 	return t.caches.Probe(name)
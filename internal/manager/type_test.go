@@ -3,6 +3,7 @@
 package manager_test
 
 import (
+	"bytes"
 	"context"
 	"testing"
 	"time"
@@ -14,11 +15,14 @@ import (
 	"github.com/redpanda-data/benthos/v4/internal/component"
 	"github.com/redpanda-data/benthos/v4/internal/component/cache"
 	"github.com/redpanda-data/benthos/v4/internal/component/input"
+	"github.com/redpanda-data/benthos/v4/internal/component/metrics"
 	"github.com/redpanda-data/benthos/v4/internal/component/output"
 	"github.com/redpanda-data/benthos/v4/internal/component/processor"
 	"github.com/redpanda-data/benthos/v4/internal/component/ratelimit"
 	"github.com/redpanda-data/benthos/v4/internal/component/testutil"
 	"github.com/redpanda-data/benthos/v4/internal/docs"
+	"github.com/redpanda-data/benthos/v4/internal/filepath/ifs"
+	"github.com/redpanda-data/benthos/v4/internal/log"
 	"github.com/redpanda-data/benthos/v4/internal/manager"
 	"github.com/redpanda-data/benthos/v4/internal/message"
 
@@ -166,6 +170,47 @@ func TestManagerResourceCRUD(t *testing.T) {
 	require.False(t, mgr.ProbeRateLimit("foo"))
 }
 
+func TestManagerResourceCounts(t *testing.T) {
+	conf := manager.NewResourceConfig()
+
+	cacheConf := cache.NewConfig()
+	cacheConf.Label = "foo"
+	cacheConf.Type = "memory"
+	conf.ResourceCaches = append(conf.ResourceCaches, cacheConf)
+
+	mgr, err := manager.New(conf)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[docs.Type]manager.ResourceCounts{
+		docs.TypeInput:     {},
+		docs.TypeCache:     {Registered: 1, Initialized: 1},
+		docs.TypeProcessor: {},
+		docs.TypeOutput:    {},
+		docs.TypeRateLimit: {},
+	}, mgr.ResourceCounts())
+
+	tCtx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	require.NoError(t, mgr.StoreProcessor(tCtx, "bar", processor.NewConfig()))
+	require.NoError(t, mgr.StoreRateLimit(tCtx, "baz", ratelimit.NewConfig()))
+
+	badConf := cache.NewConfig()
+	badConf.Type = "notexist"
+	require.Error(t, mgr.StoreCache(tCtx, "buz", badConf))
+
+	assert.Equal(t, map[docs.Type]manager.ResourceCounts{
+		docs.TypeInput:     {},
+		docs.TypeCache:     {Registered: 1, Initialized: 1},
+		docs.TypeProcessor: {Registered: 1, Initialized: 1},
+		docs.TypeOutput:    {},
+		docs.TypeRateLimit: {Registered: 1, Initialized: 1},
+	}, mgr.ResourceCounts())
+
+	require.NoError(t, mgr.RemoveCache(tCtx, "foo"))
+	assert.Equal(t, manager.ResourceCounts{}, mgr.ResourceCounts()[docs.TypeCache])
+}
+
 func TestManagerCacheList(t *testing.T) {
 	cacheFoo := cache.NewConfig()
 	cacheFoo.Label = "foo"
@@ -267,6 +312,186 @@ func TestManagerRateLimitList(t *testing.T) {
 	assert.EqualError(t, err, "unable to locate resource: baz")
 }
 
+func TestManagerBloblangRateLimitCheck(t *testing.T) {
+	conf, err := testutil.ManagerFromYAML(`
+rate_limit_resources:
+  - label: foo
+    local:
+      count: 1
+      interval: 1h
+`)
+	require.NoError(t, err)
+
+	mgr, err := manager.New(conf)
+	require.NoError(t, err)
+
+	exec, err := mgr.BloblEnvironment().NewMapping(`root = rate_limit_check("foo")`)
+	require.NoError(t, err)
+
+	part, err := exec.MapPart(0, message.QuickBatch([][]byte{[]byte(`{}`)}))
+	require.NoError(t, err)
+	assert.Equal(t, "0", string(part.AsBytes()))
+
+	part, err = exec.MapPart(0, message.QuickBatch([][]byte{[]byte(`{}`)}))
+	require.NoError(t, err)
+
+	v, err := part.AsStructured()
+	require.NoError(t, err)
+	assert.Greater(t, v.(int64), int64(0))
+
+	exec, err = mgr.BloblEnvironment().NewMapping(`root = rate_limit_check("bar")`)
+	require.NoError(t, err)
+
+	_, err = exec.MapPart(0, message.QuickBatch([][]byte{[]byte(`{}`)}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unable to locate resource: bar")
+}
+
+func TestManagerBloblangRateLimitCheckKeyed(t *testing.T) {
+	conf, err := testutil.ManagerFromYAML(`
+rate_limit_resources:
+  - label: foo
+    local:
+      count: 1
+      interval: 1h
+`)
+	require.NoError(t, err)
+
+	mgr, err := manager.New(conf)
+	require.NoError(t, err)
+
+	exec, err := mgr.BloblEnvironment().NewMapping(`root = rate_limit_check("foo", this.tenant) > 0`)
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		tenant    string
+		throttled string
+	}{
+		{tenant: "a", throttled: "false"},
+		{tenant: "a", throttled: "true"},
+		{tenant: "b", throttled: "false"},
+		{tenant: "b", throttled: "true"},
+		{tenant: "a", throttled: "true"},
+	} {
+		part, err := exec.MapPart(0, message.QuickBatch([][]byte{[]byte(`{"tenant":"` + test.tenant + `"}`)}))
+		require.NoError(t, err)
+		assert.Equal(t, test.throttled, string(part.AsBytes()), test.tenant)
+	}
+
+	// The budget of the resource without a key is independent of the keys.
+	exec, err = mgr.BloblEnvironment().NewMapping(`root = rate_limit_check("foo")`)
+	require.NoError(t, err)
+
+	part, err := exec.MapPart(0, message.QuickBatch([][]byte{[]byte(`{}`)}))
+	require.NoError(t, err)
+	assert.Equal(t, "0", string(part.AsBytes()))
+}
+
+func TestManagerBloblangStreamID(t *testing.T) {
+	mgr, err := manager.New(manager.NewResourceConfig())
+	require.NoError(t, err)
+
+	exec, err := mgr.BloblEnvironment().NewMapping(`root = stream_id()`)
+	require.NoError(t, err)
+
+	part, err := exec.MapPart(0, message.QuickBatch([][]byte{[]byte(`{}`)}))
+	require.NoError(t, err)
+	assert.Equal(t, "", string(part.AsBytes()))
+
+	streamMgr := mgr.ForStream("foo").(*manager.Type)
+	exec, err = streamMgr.BloblEnvironment().NewMapping(`root = stream_id()`)
+	require.NoError(t, err)
+
+	part, err = exec.MapPart(0, message.QuickBatch([][]byte{[]byte(`{}`)}))
+	require.NoError(t, err)
+	assert.Equal(t, "foo", string(part.AsBytes()))
+}
+
+func TestManagerBloblangLogValue(t *testing.T) {
+	logBuf := &bytes.Buffer{}
+	logConf := log.NewConfig()
+	logConf.LogLevel = "DEBUG"
+	logConf.Format = "logfmt"
+	logger, err := log.New(logBuf, ifs.OS(), logConf)
+	require.NoError(t, err)
+
+	mgr, err := manager.New(manager.NewResourceConfig(), manager.OptSetLogger(logger))
+	require.NoError(t, err)
+
+	exec, err := mgr.BloblEnvironment().NewMapping(`root = this.foo.log_value("INFO", "hello world")`)
+	require.NoError(t, err)
+
+	part, err := exec.MapPart(0, message.QuickBatch([][]byte{[]byte(`{"foo":"bar"}`)}))
+	require.NoError(t, err)
+	assert.Equal(t, "bar", string(part.AsBytes()))
+
+	assert.Contains(t, logBuf.String(), `msg="hello world"`)
+	assert.Contains(t, logBuf.String(), `value=bar`)
+}
+
+func TestManagerBloblangMetrics(t *testing.T) {
+	stats := metrics.NewLocal()
+	mgr, err := manager.New(manager.NewResourceConfig(), manager.OptSetMetrics(metrics.NewNamespaced(stats)))
+	require.NoError(t, err)
+
+	exec, err := mgr.BloblEnvironment().NewMapping(`root = this.metric_counter("events", 1, {"type": this.type}).metric_gauge("last_count", this.count)`)
+	require.NoError(t, err)
+
+	for _, doc := range []string{`{"count":3,"type":"click"}`, `{"count":5,"type":"click"}`} {
+		part, err := exec.MapPart(0, message.QuickBatch([][]byte{[]byte(doc)}))
+		require.NoError(t, err)
+		assert.Equal(t, doc, string(part.AsBytes()))
+	}
+
+	assert.Equal(t, map[string]int64{
+		`events{type="click"}`: 2,
+		`last_count`:           5,
+	}, stats.GetCounters())
+}
+
+func TestManagerBloblangInterpolationResources(t *testing.T) {
+	conf, err := testutil.ManagerFromYAML(`
+rate_limit_resources:
+  - label: foo
+    local:
+      count: 1
+      interval: 1h
+`)
+	require.NoError(t, err)
+
+	logBuf := &bytes.Buffer{}
+	logConf := log.NewConfig()
+	logConf.LogLevel = "DEBUG"
+	logConf.Format = "logfmt"
+	logger, err := log.New(logBuf, ifs.OS(), logConf)
+	require.NoError(t, err)
+
+	stats := metrics.NewLocal()
+
+	mgr, err := manager.New(conf, manager.OptSetLogger(logger), manager.OptSetMetrics(metrics.NewNamespaced(stats)))
+	require.NoError(t, err)
+
+	streamMgr := mgr.ForStream("foo").(*manager.Type)
+
+	field, err := streamMgr.BloblEnvironment().NewField(`${! stream_id() } ${! rate_limit_check("foo") } ${! this.foo.log_value("INFO", "interpolated").metric_counter("interpolations") }`)
+	require.NoError(t, err)
+
+	str, err := field.String(0, message.QuickBatch([][]byte{[]byte(`{"foo":"bar"}`)}))
+	require.NoError(t, err)
+	assert.Equal(t, "foo 0 bar", str)
+
+	assert.Contains(t, logBuf.String(), `msg=interpolated`)
+	assert.Contains(t, logBuf.String(), `value=bar`)
+	assert.Equal(t, int64(1), stats.GetCounters()[`interpolations{stream="foo"}`])
+
+	field, err = mgr.BloblEnvironment().NewField(`${! rate_limit_check("bar") }`)
+	require.NoError(t, err)
+
+	_, err = field.String(0, message.QuickBatch([][]byte{[]byte(`{}`)}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unable to locate resource: bar")
+}
+
 func TestManagerRateLimitListErrors(t *testing.T) {
 	cFoo := ratelimit.NewConfig()
 	cFoo.Label = "foo"
@@ -14,6 +14,14 @@ type messageData struct {
 	// Mutable when readOnlyMeta = false
 	readOnlyMeta bool
 	metadata     map[string]any
+
+	// Mutable when readOnlyCtxValues = false
+	readOnlyCtxValues bool
+	ctxValues         map[string]any
+
+	// Mutable when readOnlyTags = false
+	readOnlyTags bool
+	tags         map[string]struct{}
 }
 
 func newMessageBytes(content []byte) *messageData {
@@ -106,6 +114,12 @@ func (m *messageData) ShallowCopy() *messageData {
 
 		readOnlyMeta: true,
 		metadata:     m.metadata,
+
+		readOnlyCtxValues: true,
+		ctxValues:         m.ctxValues,
+
+		readOnlyTags: true,
+		tags:         m.tags,
 	}
 }
 
@@ -136,11 +150,23 @@ func (m *messageData) DeepCopy() *messageData {
 		structuredCopy = cloneGeneric(m.structured)
 	}
 
+	// NOTE: Context values are opaque and therefore cannot be deep cloned, the
+	// map is copied so that the values can be set independently.
+	var clonedCtxValues map[string]any
+	if m.ctxValues != nil {
+		clonedCtxValues = make(map[string]any, len(m.ctxValues))
+		for k, v := range m.ctxValues {
+			clonedCtxValues[k] = v
+		}
+	}
+
 	return &messageData{
 		rawBytes:   bytesCopy,
 		err:        m.err,
 		structured: structuredCopy,
 		metadata:   clonedMeta,
+		ctxValues:  clonedCtxValues,
+		tags:       cloneTags(m.tags),
 	}
 }
 
@@ -202,6 +228,85 @@ func (m *messageData) MetaIterMut(f func(k string, v any) error) error {
 	return nil
 }
 
+func (m *messageData) writeableCtxValues() {
+	if !m.readOnlyCtxValues {
+		return
+	}
+
+	var clonedCtxValues map[string]any
+	if m.ctxValues != nil {
+		clonedCtxValues = make(map[string]any, len(m.ctxValues))
+		for k, v := range m.ctxValues {
+			clonedCtxValues[k] = v
+		}
+	}
+
+	m.ctxValues = clonedCtxValues
+	m.readOnlyCtxValues = false
+}
+
+func (m *messageData) CtxValueGet(key string) (any, bool) {
+	if m.ctxValues == nil {
+		return nil, false
+	}
+	v, exists := m.ctxValues[key]
+	return v, exists
+}
+
+func (m *messageData) CtxValueSet(key string, value any) {
+	m.writeableCtxValues()
+	if m.ctxValues == nil {
+		m.ctxValues = map[string]any{
+			key: value,
+		}
+		return
+	}
+	m.ctxValues[key] = value
+}
+
+func (m *messageData) CtxValueDelete(key string) {
+	m.writeableCtxValues()
+	delete(m.ctxValues, key)
+}
+
+func cloneTags(tags map[string]struct{}) map[string]struct{} {
+	if tags == nil {
+		return nil
+	}
+	clonedTags := make(map[string]struct{}, len(tags))
+	for k := range tags {
+		clonedTags[k] = struct{}{}
+	}
+	return clonedTags
+}
+
+func (m *messageData) TagHas(name string) bool {
+	_, exists := m.tags[name]
+	return exists
+}
+
+func (m *messageData) TagSet(name string) {
+	if m.readOnlyTags {
+		m.tags = cloneTags(m.tags)
+		m.readOnlyTags = false
+	}
+	if m.tags == nil {
+		m.tags = map[string]struct{}{}
+	}
+	m.tags[name] = struct{}{}
+}
+
+func (m *messageData) TagDelete(name string) {
+	if _, exists := m.tags[name]; !exists {
+		return
+	}
+	if m.readOnlyTags {
+		m.tags = cloneTags(m.tags)
+		m.readOnlyTags = false
+	}
+	delete(m.tags, name)
+}
+
 func (m *messageData) ErrorGet() error {
 	return m.err
 }
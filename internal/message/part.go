@@ -177,6 +177,41 @@ func (p *Part) MetaIterMut(f func(string, any) error) error {
 
 //------------------------------------------------------------------------------
 
+// CtxValueGet returns a context value if a key exists. Context values are
+// stored separately from metadata and are never serialised.
+func (p *Part) CtxValueGet(key string) (any, bool) {
+	return p.data.CtxValueGet(key)
+}
+
+// CtxValueSet sets the value of a context key to any value.
+func (p *Part) CtxValueSet(key string, value any) {
+	p.data.CtxValueSet(key, value)
+}
+
+// CtxValueDelete removes the value of a context key.
+func (p *Part) CtxValueDelete(key string) {
+	p.data.CtxValueDelete(key)
+}
+
+//------------------------------------------------------------------------------
+
+// TagHas returns true if the message part has been marked with a tag.
+func (p *Part) TagHas(name string) bool {
+	return p.data.TagHas(name)
+}
+
+// TagSet marks the message part with a tag.
+func (p *Part) TagSet(name string) {
+	p.data.TagSet(name)
+}
+
+// TagDelete removes a tag from the message part.
+func (p *Part) TagDelete(name string) {
+	p.data.TagDelete(name)
+}
+
+//------------------------------------------------------------------------------
+
 // IsEmpty returns true if the message part is empty.
 func (p *Part) IsEmpty() bool {
 	return p.data.IsEmpty()
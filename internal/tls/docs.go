@@ -21,6 +21,14 @@ func FieldSpec() docs.FieldSpec {
 			"enable_renegotiation", "Whether to allow the remote server to repeatedly request renegotiation. Enable this option if you're seeing the error message `local error: tls: no renegotiation`.",
 		).AtVersion("3.45.0").Advanced().HasDefault(false),
 
+		docs.FieldString(
+			"server_name", "An optional server name to use for SNI and certificate verification, overriding the host of the address being connected to. This is useful when connecting via an IP address or a shared endpoint where the certificate is keyed by hostname.", "example.com",
+		).AtVersion("4.44.0").Advanced().HasDefault(""),
+
+		docs.FieldString(
+			"alpn_protocols", "An optional list of application protocols to offer during the TLS handshake via ALPN, in order of preference.", []string{"h2", "http/1.1"},
+		).Array().AtVersion("4.44.0").Advanced().HasDefault([]any{}),
+
 		docs.FieldString(
 			"root_cas", "An optional root certificate authority to use. This is a string, representing a certificate chain from the parent trusted root certificate, to possible intermediate signing certificates, to the host certificate.", "-----BEGIN CERTIFICATE-----\n...\n-----END CERTIFICATE-----",
 		).HasDefault("").Secret(),
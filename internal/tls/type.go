@@ -32,6 +32,8 @@ type Config struct {
 	InsecureSkipVerify  bool               `json:"skip_cert_verify" yaml:"skip_cert_verify"`
 	ClientCertificates  []ClientCertConfig `json:"client_certs" yaml:"client_certs"`
 	EnableRenegotiation bool               `json:"enable_renegotiation" yaml:"enable_renegotiation"`
+	ServerName          string             `json:"server_name" yaml:"server_name"`
+	ALPNProtocols       []string           `json:"alpn_protocols" yaml:"alpn_protocols"`
 }
 
 // NewConfig creates a new Config with default values.
@@ -43,6 +45,8 @@ func NewConfig() Config {
 		InsecureSkipVerify:  false,
 		ClientCertificates:  []ClientCertConfig{},
 		EnableRenegotiation: false,
+		ServerName:          "",
+		ALPNProtocols:       []string{},
 	}
 }
 
@@ -105,6 +109,16 @@ func (c *Config) GetNonToggled(f ifs.FS) (*tls.Config, error) {
 		tlsConf.InsecureSkipVerify = true
 	}
 
+	if c.ServerName != "" {
+		initConf()
+		tlsConf.ServerName = c.ServerName
+	}
+
+	if len(c.ALPNProtocols) > 0 {
+		initConf()
+		tlsConf.NextProtos = append([]string{}, c.ALPNProtocols...)
+	}
+
 	return tlsConf, nil
 }
 
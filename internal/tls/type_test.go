@@ -262,3 +262,20 @@ func TestCertificateWithNoEncryption(t *testing.T) {
 		t.Errorf("Failed to load certificate %s", err)
 	}
 }
+
+func TestServerNameAndALPN(t *testing.T) {
+	c := NewConfig()
+	c.Enabled = true
+	c.ServerName = "example.com"
+	c.ALPNProtocols = []string{"h2", "http/1.1"}
+
+	tConf, err := c.Get(ifs.OS())
+	require.NoError(t, err)
+	require.Equal(t, "example.com", tConf.ServerName)
+	require.Equal(t, []string{"h2", "http/1.1"}, tConf.NextProtos)
+
+	empty := NewConfig()
+	tConf, err = empty.GetNonToggled(ifs.OS())
+	require.NoError(t, err)
+	require.Nil(t, tConf)
+}
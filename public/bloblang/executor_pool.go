@@ -0,0 +1,100 @@
+// Copyright 2025 Redpanda Data, Inc.
+
+package bloblang
+
+import (
+	"runtime"
+)
+
+// ExecutorPool vends executors of a single Bloblang mapping for use by
+// concurrent goroutines, allowing high-throughput plugins to execute the same
+// mapping in parallel without sharing an executor.
+//
+// An Executor is safe to use from multiple goroutines in parallel, but any
+// stateful functions and methods within the mapping (such as `random_int`) are
+// instantiated once per executor and guard their state with a mutex, which can
+// become a point of contention when the executor is shared across many
+// goroutines. Each executor of a pool is parsed separately and therefore has
+// its own instances of these functions. All executors are parsed when the pool
+// is created, and when every executor is in use Get blocks until one is
+// returned with Put.
+//
+// Since state is not shared between executors the results of stateful
+// functions can differ from those of a single shared executor. For example, two
+// executors of a mapping that calls `random_int` with a fixed seed each produce
+// the same sequence of numbers. Functions with state that is global to the
+// process (such as `count`) are unaffected and remain shared.
+//
+// An ExecutorPool is safe for concurrent use, whereas an executor obtained with
+// Get should only be used by a single goroutine until it is returned with Put.
+type ExecutorPool struct {
+	idle chan *Executor
+}
+
+// NewExecutorPool parses a Bloblang mapping using the Environment to determine
+// the features (functions and methods) available to the mapping, and returns a
+// pool of size executors of the mapping. When size is zero or less the pool
+// contains an executor for each of runtime.GOMAXPROCS.
+//
+// When a parsing error occurs the error will be the type *ParseError, which
+// gives access to the line and column where the error occurred, as well as a
+// method for creating a well formatted error message.
+func (e *Environment) NewExecutorPool(blobl string, size int) (*ExecutorPool, error) {
+	if size <= 0 {
+		size = runtime.GOMAXPROCS(0)
+	}
+	p := &ExecutorPool{
+		idle: make(chan *Executor, size),
+	}
+	for i := 0; i < size; i++ {
+		exec, err := e.Parse(blobl)
+		if err != nil {
+			return nil, err
+		}
+		p.idle <- exec
+	}
+	return p, nil
+}
+
+// NewExecutorPool parses a Bloblang mapping using the global environment and
+// returns a pool of size executors of the mapping.
+func NewExecutorPool(blobl string, size int) (*ExecutorPool, error) {
+	return GlobalEnvironment().NewExecutorPool(blobl, size)
+}
+
+// Get returns an idle executor from the pool, blocking until one is available.
+// The executor must be returned to the pool with Put once it is no longer being
+// used.
+func (p *ExecutorPool) Get() *Executor {
+	return <-p.idle
+}
+
+// Put returns an executor obtained with Get to the pool so that it can be
+// reused. The executor must not be used after it has been returned.
+func (p *ExecutorPool) Put(exec *Executor) {
+	if exec == nil {
+		return
+	}
+	select {
+	case p.idle <- exec:
+	default:
+		// The executor did not originate from this pool.
+	}
+}
+
+// Query executes the mapping against a value with an executor from the pool
+// and returns the result, see (*Executor).Query for more details.
+func (p *ExecutorPool) Query(val any) (any, error) {
+	exec := p.Get()
+	defer p.Put(exec)
+	return exec.Query(val)
+}
+
+// Overlay executes the mapping against a value with an executor from the pool,
+// where assignments are overlayed onto an existing structure, see
+// (*Executor).Overlay for more details.
+func (p *ExecutorPool) Overlay(val any, onto *any) error {
+	exec := p.Get()
+	defer p.Put(exec)
+	return exec.Overlay(val, onto)
+}
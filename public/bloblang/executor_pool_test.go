@@ -0,0 +1,113 @@
+// Copyright 2025 Redpanda Data, Inc.
+
+package bloblang
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutorPoolQuery(t *testing.T) {
+	pool, err := NewEnvironment().NewExecutorPool(`root.doubled = this.value * 2`, 4)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				res, err := pool.Query(map[string]any{"value": int64(i * j)})
+				require.NoError(t, err)
+				assert.Equal(t, map[string]any{"doubled": int64(i * j * 2)}, res)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	var onto any = map[string]any{"original": true}
+	require.NoError(t, pool.Overlay(map[string]any{"value": int64(5)}, &onto))
+	assert.Equal(t, map[string]any{"original": true, "doubled": int64(10)}, onto)
+}
+
+func TestExecutorPoolIndependentState(t *testing.T) {
+	pool, err := NewExecutorPool(`root = random_int(seed: 10)`, 2)
+	require.NoError(t, err)
+
+	execA := pool.Get()
+	execB := pool.Get()
+	require.NotSame(t, execA, execB)
+
+	resA, err := execA.Query(nil)
+	require.NoError(t, err)
+
+	resB, err := execB.Query(nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, resA, resB)
+
+	pool.Put(execA)
+	pool.Put(execB)
+	pool.Put(nil)
+}
+
+func TestExecutorPoolBlocksWhenExhausted(t *testing.T) {
+	pool, err := NewExecutorPool(`root = this`, 1)
+	require.NoError(t, err)
+
+	exec := pool.Get()
+
+	gotChan := make(chan *Executor)
+	go func() {
+		gotChan <- pool.Get()
+	}()
+
+	select {
+	case <-gotChan:
+		t.Fatal("expected Get to block whilst the only executor is in use")
+	case <-time.After(time.Millisecond * 50):
+	}
+
+	pool.Put(exec)
+
+	select {
+	case got := <-gotChan:
+		assert.Same(t, exec, got)
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out")
+	}
+}
+
+func TestExecutorPoolParseError(t *testing.T) {
+	_, err := NewExecutorPool(`root = this.nope(`, 1)
+	require.Error(t, err)
+
+	var pErr *ParseError
+	require.ErrorAs(t, err, &pErr)
+}
+
+func BenchmarkExecutorPoolParallel(b *testing.B) {
+	pool, err := NewExecutorPool(`root.id = random_int(max: 1000)
+root.content = this.content.uppercase()`, 0)
+	require.NoError(b, err)
+
+	input := map[string]any{"content": "hello world"}
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	b.RunParallel(func(pb *testing.PB) {
+		exec := pool.Get()
+		defer pool.Put(exec)
+
+		for pb.Next() {
+			if _, err := exec.Query(input); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
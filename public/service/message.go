@@ -3,8 +3,12 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"unicode/utf8"
 
 	"github.com/redpanda-data/benthos/v4/internal/bloblang/mapping"
 	"github.com/redpanda-data/benthos/v4/internal/bloblang/query"
@@ -359,6 +363,65 @@ func (m *Message) MetaWalkMut(fn func(key string, value any) error) error {
 
 //------------------------------------------------------------------------------
 
+// SetContextValue sets a value against a key that is carried alongside the
+// message and can be read by downstream components with GetContextValue. This
+// is useful for coordination between plugins, such as a processor passing a
+// resource to an output, without polluting the metadata of the message.
+//
+// Context values are stored separately from both the payload and metadata of
+// the message, they are never serialised, cannot be accessed from Bloblang and
+// are therefore not emitted by outputs. Values are carried over to copies of
+// the message created with Copy and DeepCopy, and setting a value on a copy
+// does not change the value of the original. However, the values themselves
+// are not cloned by DeepCopy and should therefore be treated as immutable.
+//
+// Since context values are not serialised they should not be expected to
+// survive output boundaries, and are not restored when a message is consumed
+// again by an input.
+func (m *Message) SetContextValue(key string, value any) {
+	m.part.CtxValueSet(key, value)
+}
+
+// GetContextValue attempts to find a context value previously set with
+// SetContextValue, returning the value and a boolean indicating whether it was
+// found.
+func (m *Message) GetContextValue(key string) (any, bool) {
+	return m.part.CtxValueGet(key)
+}
+
+// DeleteContextValue removes a context value previously set with
+// SetContextValue.
+func (m *Message) DeleteContextValue(key string) {
+	m.part.CtxValueDelete(key)
+}
+
+// SetTag marks the message with a named tag, which is a cheap boolean flag that
+// downstream components can check with HasTag, such as marking a message as
+// "already_enriched". Like context values, tags are stored separately from the
+// payload and metadata of the message and are never serialised or emitted by
+// outputs.
+//
+// Tags are carried over to copies of the message created with Copy and
+// DeepCopy. The tags of a copy are shared with the original until either is
+// modified, at which point the modified message receives its own set of tags,
+// and therefore setting or clearing a tag on a copy does not change the tags of
+// the original.
+func (m *Message) SetTag(name string) {
+	m.part.TagSet(name)
+}
+
+// HasTag returns true if the message has been marked with a named tag via
+// SetTag.
+func (m *Message) HasTag(name string) bool {
+	return m.part.TagHas(name)
+}
+
+// ClearTag removes a named tag from the message, this is a no-op if the message
+// does not have the tag.
+func (m *Message) ClearTag(name string) {
+	m.part.TagDelete(name)
+}
+
 // BloblangQuery executes a parsed Bloblang mapping on a message and returns a
 // message back or an error if the mapping fails. If the mapping results in the
 // root being deleted the returned message will be nil, which indicates it has
@@ -702,3 +765,52 @@ func (b MessageBatch) AddSyncResponse() error {
 	}
 	return transaction.SetAsResponse(parts)
 }
+
+// AsNDJSON serializes the batch as newline delimited JSON, where each message
+// is written on its own line followed by a newline character. Messages with
+// structured contents are marshalled as JSON, and the raw bytes of all other
+// messages are written as they are, unless they are a JSON document spanning
+// multiple lines, in which case the document is compacted onto a single line.
+//
+// An error is returned if the raw contents of a message are not valid UTF-8, or
+// span multiple lines and are not a valid JSON document.
+func (b MessageBatch) AsNDJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	for i, m := range b {
+		mBytes, err := m.AsBytes()
+		if err != nil {
+			return nil, fmt.Errorf("message %v: %w", i, err)
+		}
+		if !utf8.Valid(mBytes) {
+			return nil, fmt.Errorf("message %v: contents are not valid UTF-8", i)
+		}
+		if bytes.ContainsAny(mBytes, "\r\n") {
+			if err := json.Compact(&buf, mBytes); err != nil {
+				return nil, fmt.Errorf("message %v: contents span multiple lines and are not a valid JSON document: %w", i, err)
+			}
+		} else {
+			_, _ = buf.Write(mBytes)
+		}
+		_ = buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// GroupBy partitions the batch into groups of messages by a key returned by a
+// closure function, which is called once for each message. The messages of
+// each group retain the order in which they appear within the original batch.
+//
+// This is useful for outputs that deliver messages to distinct destinations,
+// such as topics or tables, determined by the contents of each message. If the
+// closure returns an error then grouping is abandoned and the error returned.
+func (b MessageBatch) GroupBy(fn func(*Message) (string, error)) (map[string]MessageBatch, error) {
+	groups := map[string]MessageBatch{}
+	for i, m := range b {
+		key, err := fn(m)
+		if err != nil {
+			return nil, fmt.Errorf("message %v: %w", i, err)
+		}
+		groups[key] = append(groups[key], m)
+	}
+	return groups, nil
+}
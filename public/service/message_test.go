@@ -3,6 +3,7 @@
 package service
 
 import (
+	"bytes"
 	"errors"
 	"testing"
 
@@ -10,6 +11,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	ibloblang "github.com/redpanda-data/benthos/v4/internal/bloblang"
+	"github.com/redpanda-data/benthos/v4/internal/manager"
 	"github.com/redpanda-data/benthos/v4/internal/message"
 	"github.com/redpanda-data/benthos/v4/public/bloblang"
 )
@@ -78,6 +80,93 @@ func TestMessageCopyAirGap(t *testing.T) {
 	assert.Equal(t, "baz", v)
 }
 
+func TestMessageContextValues(t *testing.T) {
+	type resource struct{ name string }
+	res := &resource{name: "foo"}
+
+	m := NewMessage([]byte("hello world"))
+	_, exists := m.GetContextValue("res")
+	assert.False(t, exists)
+
+	m.SetContextValue("res", res)
+	m.SetContextValue("count", 1)
+
+	v, exists := m.GetContextValue("res")
+	require.True(t, exists)
+	assert.Same(t, res, v)
+
+	_, exists = m.MetaGetMut("res")
+	assert.False(t, exists)
+
+	shallow := m.Copy()
+	deep := m.DeepCopy()
+
+	shallow.SetContextValue("count", 2)
+	deep.SetContextValue("count", 3)
+	deep.DeleteContextValue("res")
+
+	v, _ = m.GetContextValue("count")
+	assert.Equal(t, 1, v)
+	v, _ = shallow.GetContextValue("count")
+	assert.Equal(t, 2, v)
+	v, _ = deep.GetContextValue("count")
+	assert.Equal(t, 3, v)
+
+	v, exists = shallow.GetContextValue("res")
+	require.True(t, exists)
+	assert.Same(t, res, v)
+
+	_, exists = deep.GetContextValue("res")
+	assert.False(t, exists)
+	_, exists = m.GetContextValue("res")
+	assert.True(t, exists)
+
+	blobl, err := bloblang.Parse(`root = content().uppercase()`)
+	require.NoError(t, err)
+
+	mapped, err := m.BloblangQuery(blobl)
+	require.NoError(t, err)
+	v, _ = mapped.GetContextValue("count")
+	assert.Equal(t, 1, v)
+
+	b, err := mapped.AsBytes()
+	require.NoError(t, err)
+	assert.Equal(t, "HELLO WORLD", string(b))
+}
+
+func TestMessageTags(t *testing.T) {
+	m := NewMessage([]byte("hello world"))
+	assert.False(t, m.HasTag("enriched"))
+
+	m.ClearTag("enriched")
+	m.SetTag("enriched")
+	m.SetTag("validated")
+	assert.True(t, m.HasTag("enriched"))
+	assert.True(t, m.HasTag("validated"))
+
+	_, exists := m.MetaGetMut("enriched")
+	assert.False(t, exists)
+
+	shallow := m.Copy()
+	deep := m.DeepCopy()
+
+	shallow.ClearTag("enriched")
+	shallow.SetTag("routed")
+	deep.SetTag("buffered")
+
+	assert.True(t, m.HasTag("enriched"))
+	assert.False(t, m.HasTag("routed"))
+	assert.False(t, m.HasTag("buffered"))
+
+	assert.False(t, shallow.HasTag("enriched"))
+	assert.True(t, shallow.HasTag("validated"))
+	assert.True(t, shallow.HasTag("routed"))
+
+	assert.True(t, deep.HasTag("enriched"))
+	assert.True(t, deep.HasTag("buffered"))
+	assert.False(t, deep.HasTag("routed"))
+}
+
 func TestMessageQuery(t *testing.T) {
 	p := message.NewPart([]byte(`{"foo":"bar"}`))
 	p.MetaSetMut("foo", "bar")
@@ -323,6 +412,35 @@ func TestMessageMapping(t *testing.T) {
 	}, resI)
 }
 
+func TestMessageMappingResources(t *testing.T) {
+	mgr, err := manager.New(manager.NewResourceConfig())
+	require.NoError(t, err)
+
+	env := bloblang.XWrapEnvironment(mgr.ForStream("foo").(*manager.Type).BloblEnvironment())
+
+	blobl, err := env.Parse(`root.stream = stream_id()`)
+	require.NoError(t, err)
+
+	res, err := NewMessage(nil).BloblangQuery(blobl)
+	require.NoError(t, err)
+
+	resI, err := res.AsStructured()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"stream": "foo"}, resI)
+
+	resV, err := NewMessage(nil).BloblangQueryValue(blobl)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"stream": "foo"}, resV)
+
+	resV, err = MessageBatch{NewMessage(nil)}.BloblangExecutor(blobl).QueryValue(0)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"stream": "foo"}, resV)
+
+	resV, err = blobl.Query(nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"stream": "foo"}, resV)
+}
+
 func TestMessageBatchMapping(t *testing.T) {
 	partOne := NewMessage(nil)
 	partOne.SetStructured(map[string]any{
@@ -519,3 +637,101 @@ func TestSyncResponse(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "hello world c", string(data))
 }
+
+func TestMessageBatchAsNDJSON(t *testing.T) {
+	structured := NewMessage(nil)
+	structured.SetStructured(map[string]any{"id": 1, "tags": []any{"a", "b"}})
+
+	batch := MessageBatch{
+		structured,
+		NewMessage([]byte("{\n  \"id\": 2,\n  \"name\": \"foo\"\n}")),
+		NewMessage([]byte(`{"id":3}`)),
+		NewMessage([]byte(`"héllo"`)),
+	}
+
+	data, err := batch.AsNDJSON()
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":1,"tags":["a","b"]}
+{"id":2,"name":"foo"}
+{"id":3}
+"héllo"
+`, string(data))
+
+	lines := bytes.Split(bytes.TrimSuffix(data, []byte("\n")), []byte("\n"))
+	require.Len(t, lines, len(batch))
+	for i, line := range lines {
+		original, err := batch[i].AsBytes()
+		require.NoError(t, err)
+
+		exp, err := NewMessage(original).AsStructured()
+		require.NoError(t, err)
+
+		act, err := NewMessage(line).AsStructured()
+		require.NoError(t, err)
+		assert.Equal(t, exp, act, i)
+	}
+
+	data, err = MessageBatch{}.AsNDJSON()
+	require.NoError(t, err)
+	assert.Empty(t, data)
+
+	_, err = MessageBatch{NewMessage([]byte("foo")), NewMessage([]byte{0xff, 0xfe})}.AsNDJSON()
+	require.EqualError(t, err, "message 1: contents are not valid UTF-8")
+
+	_, err = MessageBatch{NewMessage([]byte("foo\nbar"))}.AsNDJSON()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "message 0: contents span multiple lines")
+}
+
+func TestMessageBatchGroupBy(t *testing.T) {
+	batch := MessageBatch{
+		NewMessage([]byte("a1")),
+		NewMessage([]byte("b1")),
+		NewMessage([]byte("a2")),
+		NewMessage([]byte("c1")),
+		NewMessage([]byte("b2")),
+		NewMessage([]byte("a3")),
+	}
+
+	groups, err := batch.GroupBy(func(m *Message) (string, error) {
+		b, err := m.AsBytes()
+		if err != nil {
+			return "", err
+		}
+		return string(b[:1]), nil
+	})
+	require.NoError(t, err)
+
+	contents := map[string][]string{}
+	for k, g := range groups {
+		for _, m := range g {
+			b, err := m.AsBytes()
+			require.NoError(t, err)
+			contents[k] = append(contents[k], string(b))
+		}
+	}
+	assert.Equal(t, map[string][]string{
+		"a": {"a1", "a2", "a3"},
+		"b": {"b1", "b2"},
+		"c": {"c1"},
+	}, contents)
+	assert.Same(t, batch[1], groups["b"][0])
+
+	groups, err = MessageBatch{}.GroupBy(func(m *Message) (string, error) {
+		return "", nil
+	})
+	require.NoError(t, err)
+	assert.Empty(t, groups)
+
+	_, err = batch.GroupBy(func(m *Message) (string, error) {
+		b, err := m.AsBytes()
+		if err != nil {
+			return "", err
+		}
+		if string(b) == "c1" {
+			return "", errors.New("nope")
+		}
+		return "", nil
+	})
+	require.EqualError(t, err, "message 3: nope")
+}